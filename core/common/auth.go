@@ -0,0 +1,65 @@
+package common
+
+const (
+	// EventLoginLocked 表示账号因多次登录失败被临时锁定事件。
+	EventLoginLocked = "login_locked"
+	// EventLoginUnlocked 表示账号通过邮件验证码解除锁定事件。
+	EventLoginUnlocked = "login_unlocked"
+	// EventEmailChanged 表示用户更换绑定邮箱事件。
+	EventEmailChanged = "email_changed"
+	// EventAdminUserDisabled 表示管理员禁用账号事件。
+	EventAdminUserDisabled = "admin_user_disabled"
+	// EventAdminUserEnabled 表示管理员启用账号事件。
+	EventAdminUserEnabled = "admin_user_enabled"
+	// EventAdminPasswordReset 表示管理员重置用户密码事件。
+	EventAdminPasswordReset = "admin_password_reset"
+	// EventAdminRoleChanged 表示管理员变更用户角色事件。
+	EventAdminRoleChanged = "admin_role_changed"
+	// EventAdminRolePermissions 表示管理员调整角色权限事件。
+	EventAdminRolePermissions = "admin_role_permissions"
+	// EventAdminPolicyChanged 表示管理员调整角色安全策略事件。
+	EventAdminPolicyChanged = "admin_policy_changed"
+	// EventAdminQuotaChanged 表示管理员调整用户存储配额事件。
+	EventAdminQuotaChanged = "admin_quota_changed"
+)
+
+const (
+	// RoleAdmin 管理员角色。
+	RoleAdmin = "admin"
+)
+
+const (
+	// PermUsersRead 管理权限：查看与搜索用户。
+	PermUsersRead = "users:read"
+	// PermUsersWrite 管理权限：禁用、启用用户，重置密码与变更角色。
+	PermUsersWrite = "users:write"
+	// PermRolesManage 管理权限：查看角色并调整角色权限。
+	PermRolesManage = "roles:manage"
+	// PermPoliciesManage 管理权限：调整角色安全策略。
+	PermPoliciesManage = "policies:manage"
+	// PermAuditRead 管理权限：查询与导出审计日志。
+	PermAuditRead = "audit:read"
+	// PermWebhooksManage 管理权限：管理接收全站文件事件的全局 webhook。
+	PermWebhooksManage = "webhooks:manage"
+)
+
+// Permissions 系统支持的全部管理权限，admin 角色隐式拥有全部权限。
+var Permissions = []string{PermUsersRead, PermUsersWrite, PermRolesManage, PermPoliciesManage, PermAuditRead, PermWebhooksManage}
+
+const (
+	// ScopeFilesRead 个人访问令牌权限：读取文件。
+	ScopeFilesRead = "files:read"
+	// ScopeFilesWrite 个人访问令牌权限：上传、修改与删除文件（包含读取）。
+	ScopeFilesWrite = "files:write"
+	// ScopeShareManage 个人访问令牌权限：管理分享与上传链接。
+	ScopeShareManage = "share:manage"
+)
+
+const (
+	// CodePurposeRegister 验证码用途：注册。
+	CodePurposeRegister = "register"
+	// CodePurposeReset 验证码用途：重置密码。
+	CodePurposeReset = "reset"
+	// CodePurposeChangeEmail 验证码用途：更换邮箱。
+	CodePurposeChangeEmail = "change_email"
+)
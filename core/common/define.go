@@ -33,3 +33,21 @@ var ExchangeName = "upload.event.exchange"
 var QueueName = "upload.process.queue"
 
 var RoutingKey = "upload.new"
+
+// WebhookRoutingKey webhook 投递任务的路由键，投递队列绑定到 ExchangeName。
+var WebhookRoutingKey = "webhook.deliver"
+
+// WebhookQueueName webhook 投递队列。
+var WebhookQueueName = "webhook.delivery.queue"
+
+// WebhookRetryQueuePrefix webhook 重试延迟队列名前缀，第 n 次重试的队列为前缀加 n，消息过期后死信回投递队列。
+var WebhookRetryQueuePrefix = "webhook.retry.queue."
+
+// MailRoutingKey 邮件发送任务的路由键，邮件队列绑定到 ExchangeName。
+var MailRoutingKey = "mail.send"
+
+// MailQueueName 邮件发送队列。
+var MailQueueName = "mail.queue"
+
+// MailRetryQueuePrefix 邮件重试延迟队列名前缀，第 n 次重试的队列为前缀加 n，消息过期后死信回邮件队列。
+var MailRetryQueuePrefix = "mail.retry.queue."
@@ -0,0 +1,10 @@
+package common
+
+const (
+	// GroupRoleOwner 群组所有者，可管理成员与群组。
+	GroupRoleOwner = "owner"
+	// GroupRoleEditor 群组编辑者，可上传、移动、重命名与删除文件。
+	GroupRoleEditor = "editor"
+	// GroupRoleViewer 群组查看者，仅可浏览与下载文件。
+	GroupRoleViewer = "viewer"
+)
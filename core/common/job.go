@@ -0,0 +1,12 @@
+package common
+
+const (
+	// JobStatusPending 表示任务已创建等待执行。
+	JobStatusPending = "pending"
+	// JobStatusRunning 表示任务执行中。
+	JobStatusRunning = "running"
+	// JobStatusDone 表示任务执行完成。
+	JobStatusDone = "done"
+	// JobStatusFailed 表示任务执行失败。
+	JobStatusFailed = "failed"
+)
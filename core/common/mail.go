@@ -0,0 +1,40 @@
+package common
+
+import "time"
+
+const (
+	// MailVerification 注册与更换邮箱验证码邮件。
+	MailVerification = "verification"
+	// MailPasswordReset 重置密码验证码邮件。
+	MailPasswordReset = "password_reset"
+	// MailAccountUnlock 账号锁定解锁验证码邮件。
+	MailAccountUnlock = "account_unlock"
+	// MailShareInvite 定向分享邀请邮件。
+	MailShareInvite = "share_invite"
+	// MailQuotaWarning 存储用量告警邮件。
+	MailQuotaWarning = "quota_warning"
+	// MailUploadReceived 上传链接收到文件的通知邮件。
+	MailUploadReceived = "upload_received"
+	// MailEmailChanged 绑定邮箱已更换的通知邮件（发送至原邮箱）。
+	MailEmailChanged = "email_changed"
+)
+
+const (
+	// LangZh 简体中文。
+	LangZh = "zh"
+	// LangEn 英文。
+	LangEn = "en"
+)
+
+// MailRetryDelays 邮件发送失败后的重试间隔，依次递增；重试耗尽后放弃并记录日志。
+var MailRetryDelays = []time.Duration{
+	30 * time.Second, 2 * time.Minute, 10 * time.Minute, time.Hour,
+}
+
+// MailExpiry 带时效内容的邮件有效期（与验证码、解锁码的有效期一致），过期后不再发送；
+// 重试时下一次发送会晚于有效期的同样放弃。未列出的模板不过期，按 MailRetryDelays 重试。
+var MailExpiry = map[string]time.Duration{
+	MailVerification:  5 * time.Minute,
+	MailPasswordReset: 5 * time.Minute,
+	MailAccountUnlock: 30 * time.Minute,
+}
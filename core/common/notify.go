@@ -0,0 +1,32 @@
+package common
+
+const (
+	// NotifyUploadDone 异步上传处理完成。
+	NotifyUploadDone = "upload_done"
+	// NotifyUploadFailed 异步上传处理失败。
+	NotifyUploadFailed = "upload_failed"
+	// NotifyShareAccessed 分享被他人访问或下载。
+	NotifyShareAccessed = "share_accessed"
+	// NotifyQuotaWarning 存储用量超过配额告警阈值。
+	NotifyQuotaWarning = "quota_warning"
+	// NotifyJobProgress 后台任务进度更新。
+	NotifyJobProgress = "job_progress"
+	// NotifyInbox 收到新的站内通知。
+	NotifyInbox = "notification"
+)
+
+const (
+	// ShareActionView 查看分享。
+	ShareActionView = "view"
+	// ShareActionDownload 下载分享的文件。
+	ShareActionDownload = "download"
+)
+
+const (
+	// InboxShareReceived 他人向你定向分享了文件或文件夹。
+	InboxShareReceived = "share_received"
+	// InboxUploadFailed 异步上传处理失败。
+	InboxUploadFailed = "upload_failed"
+	// InboxTrashPurge 回收站中的项目即将被永久删除。
+	InboxTrashPurge = "trash_purge"
+)
@@ -0,0 +1,17 @@
+package common
+
+const (
+	// ShareScopePublic 表示持有链接即可访问的公开分享。
+	ShareScopePublic = "public"
+	// ShareScopeRestricted 表示仅被授权用户或邮箱可访问的定向分享。
+	ShareScopeRestricted = "restricted"
+)
+
+const (
+	// SharePermissionView 仅可查看。
+	SharePermissionView = "view"
+	// SharePermissionDownload 可查看与下载。
+	SharePermissionDownload = "download"
+	// SharePermissionEdit 可查看、下载，并可在分享的文件夹内上传、新建文件夹与重命名。
+	SharePermissionEdit = "edit"
+)
@@ -18,4 +18,24 @@ const (
 	EventRestore = "restore"
 	// EventPurge 表示清理事件。
 	EventPurge = "purge"
+	// EventShareExpired 表示分享过期被清理事件。
+	EventShareExpired = "share_expired"
+	// EventShareOrphaned 表示分享源文件已删除被清理事件。
+	EventShareOrphaned = "share_orphaned"
+	// EventDropUpload 表示通过匿名上传链接上传文件事件。
+	EventDropUpload = "drop_upload"
+	// EventUpload 表示上传文件事件。
+	EventUpload = "upload"
+	// EventFolderCreate 表示创建文件夹事件。
+	EventFolderCreate = "folder_create"
+	// EventRename 表示重命名事件。
+	EventRename = "rename"
+	// EventMove 表示移动事件。
+	EventMove = "move"
+	// EventShare 表示创建分享事件。
+	EventShare = "share"
+	// EventSave 表示转存分享资源事件。
+	EventSave = "save"
+	// EventDownload 表示获取下载链接事件。
+	EventDownload = "download"
 )
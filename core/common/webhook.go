@@ -0,0 +1,23 @@
+package common
+
+import "time"
+
+const (
+	// WebhookStatusPending 投递排队中或等待重试。
+	WebhookStatusPending = "pending"
+	// WebhookStatusSuccess 投递成功（接收方返回 2xx）。
+	WebhookStatusSuccess = "success"
+	// WebhookStatusFailed 重试耗尽或无法投递。
+	WebhookStatusFailed = "failed"
+)
+
+// WebhookEvents 可订阅的文件与分享事件。
+var WebhookEvents = []string{
+	EventUpload, EventDropUpload, EventFolderCreate, EventRename, EventMove,
+	EventDelete, EventRestore, EventShare, EventSave, EventDownload,
+}
+
+// WebhookRetryDelays 投递失败后的重试间隔，依次递增；重试耗尽后投递记录标记为失败。
+var WebhookRetryDelays = []time.Duration{
+	10 * time.Second, time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour,
+}
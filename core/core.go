@@ -24,6 +24,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/prometheus"
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
@@ -119,7 +120,7 @@ func main() {
 		c.RestConf,
 		rest.WithUnauthorizedCallback(JwtUnauthorizedResult),
 		rest.WithCustomCors(func(header http.Header) {
-			header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Token")
+			header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Token, X-Upload-Password")
 			header.Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,HEAD,OPTIONS")
 		}, nil, origins...),
 	)
@@ -130,10 +131,15 @@ func main() {
 	if ctx.RabbitMQChannel != nil {
 		consumer := mq.NewConsumer(context.Background(), ctx, ctx.RabbitMQChannel)
 		consumer.Start()
+		mq.NewWebhookConsumer(context.Background(), ctx).Start()
+		mq.NewMailConsumer(context.Background(), ctx).Start()
 	} else {
 		logx.Info("RabbitMQ disabled: channel not initialized")
 	}
 	logic.StartRecycleJob(context.Background(), ctx)
+	logic.StartShareCleanupJob(context.Background(), ctx)
+	// 开启指标采集：业务指标与 go-zero 内置的路由耗时指标均通过 /metrics 暴露
+	prometheus.Enable()
 	handler.RegisterHandlers(server, ctx)
 
 	checkCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -3,6 +3,7 @@ package global
 import (
 	"cloud_disk/core/common"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
@@ -85,35 +86,66 @@ func declareRabbitMQResources() error {
 	}
 
 	// 2. 声明队列（按业务阶段拆分，均为持久化）
-	queues := []string{
-		common.QueueName,
+	queues := []struct {
+		name       string
+		routingKey string
+	}{
+		{common.QueueName, common.RoutingKey},
+		{common.WebhookQueueName, common.WebhookRoutingKey},
+		{common.MailQueueName, common.MailRoutingKey},
 	}
+	names := make([]string, 0, len(queues)+len(common.WebhookRetryDelays)+len(common.MailRetryDelays))
 	for _, queue := range queues {
 		_, err := RmqCh.QueueDeclare(
-			queue, // 队列名
-			true,  // 持久化
-			false, // 非自动删除
-			false, // 非排他
-			false, // 无等待
-			nil,   // 额外参数（可配置死信队列）
+			queue.name, // 队列名
+			true,       // 持久化
+			false,      // 非自动删除
+			false,      // 非排他
+			false,      // 无等待
+			nil,        // 额外参数（可配置死信队列）
 		)
 		if err != nil {
-			return fmt.Errorf("声明队列 %s 失败: %w", queue, err)
+			return fmt.Errorf("声明队列 %s 失败: %w", queue.name, err)
 		}
 
-		// 3. 绑定队列到交换机（路由键与队列名一致）
+		// 3. 绑定队列到交换机
 		err = RmqCh.QueueBind(
-			queue,               // 队列名
-			common.RoutingKey,   // 路由键
+			queue.name,          // 队列名
+			queue.routingKey,    // 路由键
 			common.ExchangeName, // 交换机名
 			false,               // 无等待
 			nil,                 // 额外参数
 		)
 		if err != nil {
-			return fmt.Errorf("绑定队列 %s 失败: %w", queue, err)
+			return fmt.Errorf("绑定队列 %s 失败: %w", queue.name, err)
 		}
+		names = append(names, queue.name)
 	}
-	logx.Infof("RabbitMQ 资源声明成功: 交换机 %s, 队列 %v", common.ExchangeName, queues)
+
+	// 4. 声明 webhook 与邮件的重试延迟队列：不设消费者，消息过期后经死信交换机回到原队列
+	retries := []struct {
+		prefix     string
+		routingKey string
+		delays     []time.Duration
+	}{
+		{common.WebhookRetryQueuePrefix, common.WebhookRoutingKey, common.WebhookRetryDelays},
+		{common.MailRetryQueuePrefix, common.MailRoutingKey, common.MailRetryDelays},
+	}
+	for _, retry := range retries {
+		for i, delay := range retry.delays {
+			name := retry.prefix + strconv.Itoa(i+1)
+			_, err := RmqCh.QueueDeclare(name, true, false, false, false, amqp091.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    common.ExchangeName,
+				"x-dead-letter-routing-key": retry.routingKey,
+			})
+			if err != nil {
+				return fmt.Errorf("声明队列 %s 失败: %w", name, err)
+			}
+			names = append(names, name)
+		}
+	}
+	logx.Infof("RabbitMQ 资源声明成功: 交换机 %s, 队列 %v", common.ExchangeName, names)
 
 	return nil
 }
@@ -11,7 +11,7 @@ type Config struct {
 	Auth struct {
 		// AccessSecret JWT 密钥。
 		AccessSecret string
-		// AccessExpire JWT 过期时间（小时）。
+		// AccessExpire 访问令牌有效期（秒），为 0 时默认 15 分钟；登录状态由刷新令牌延续。
 		AccessExpire int64
 	}
 	MySQL struct {
@@ -33,4 +33,58 @@ type Config struct {
 		Password string
 		Vhost    string
 	}
+	OIDC struct {
+		// Issuer 身份提供方地址，为空时不启用单点登录。
+		Issuer string `json:",optional"`
+		// ClientId 在身份提供方注册的客户端 ID。
+		ClientId string `json:",optional"`
+		// ClientSecret 客户端密钥，公共客户端可留空（仅依赖 PKCE）。
+		ClientSecret string `json:",optional"`
+		// RedirectURL 授权回调地址，需与身份提供方登记的一致。
+		RedirectURL string `json:",optional"`
+		// Scopes 申请的权限范围，默认 openid profile email。
+		Scopes []string `json:",optional"`
+		// AllowedDomains 允许自动开通账号的邮箱域名，为空时不限制。
+		AllowedDomains []string `json:",optional"`
+		// LinkVerifiedEmail 是否允许按已验证邮箱关联已有本地账号，默认关闭；管理员及拥有管理权限的角色始终不关联。
+		LinkVerifiedEmail bool `json:",optional"`
+	} `json:",optional"`
+	Metrics struct {
+		// Token 访问 /metrics 所需的 Bearer 令牌，为空时不开放 /metrics（返回 404）。
+		Token string `json:",optional"`
+	} `json:",optional"`
+	LDAP struct {
+		// URL 目录服务地址，如 ldaps://ad.example.com:636，为空时不启用 LDAP 登录。
+		URL string `json:",optional"`
+		// StartTLS 使用 ldap:// 地址时是否升级为 TLS。
+		StartTLS bool `json:",optional"`
+		// InsecureSkipVerify 是否跳过证书校验（仅用于测试环境）。
+		InsecureSkipVerify bool `json:",optional"`
+		// BindDN 用于查找用户的服务账号，留空时匿名查找。
+		BindDN string `json:",optional"`
+		// BindPassword 服务账号密码。
+		BindPassword string `json:",optional"`
+		// BaseDN 用户查找起点。
+		BaseDN string `json:",optional"`
+		// UserFilter 用户查找过滤器，%s 替换为转义后的登录名，默认 (sAMAccountName=%s)。
+		UserFilter string `json:",optional"`
+		// EmailAttribute 邮箱属性，默认 mail。
+		EmailAttribute string `json:",optional"`
+		// GroupAttribute 组成员属性，默认 memberOf。
+		GroupAttribute string `json:",optional"`
+		// GroupRoles 组到本地角色的映射，按顺序匹配第一个命中的组；配置后目录账号的角色在每次登录时同步且不能在本地修改，未配置时保留本地角色。
+		GroupRoles []GroupRole `json:",optional"`
+	} `json:",optional"`
+	// TrustedProxies 可信反向代理（CIDR 或 IP），仅来自这些地址的请求才采用 X-Forwarded-For 作为客户端 IP；为空时使用直连地址。
+	TrustedProxies []string `json:",optional"`
+	// WebhookAllowPrivateNetworks 是否允许 webhook 回调内网地址（回环、内网、链路本地），默认拒绝以防 SSRF；仅在接收方部署于内网时开启。
+	WebhookAllowPrivateNetworks bool `json:",optional"`
+}
+
+// GroupRole 目录组与本地角色的映射。
+type GroupRole struct {
+	// Group 组 DN，大小写不敏感。
+	Group string
+	// Role 映射到的 user_basic.Role。
+	Role string
 }
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// AccessTokenDeleteHandler 吊销个人访问令牌处理入口。
+func AccessTokenDeleteHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AccessTokenDeleteRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewAccessTokenDeleteLogic(r.Context(), svcCtx)
+		resp, err := l.AccessTokenDelete(&req)
+		common.Response(r, w, resp, err)
+	}
+}
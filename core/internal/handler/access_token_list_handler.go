@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// AccessTokenListHandler 个人访问令牌列表处理入口。
+func AccessTokenListHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AccessTokenListRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewAccessTokenListLogic(r.Context(), svcCtx)
+		resp, err := l.AccessTokenList(&req)
+		common.Response(r, w, resp, err)
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// AdminAuditVerifyHandler 审计日志哈希链校验处理入口。
+func AdminAuditVerifyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminAuditVerifyRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewAdminAuditVerifyLogic(r.Context(), svcCtx)
+		resp, err := l.AdminAuditVerify(&req)
+		common.Response(r, w, resp, err)
+	}
+}
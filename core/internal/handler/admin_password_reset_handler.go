@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// AdminPasswordResetHandler 管理员重置密码处理入口。
+func AdminPasswordResetHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminPasswordResetRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewAdminPasswordResetLogic(r.Context(), svcCtx)
+		resp, err := l.AdminPasswordReset(&req)
+		common.Response(r, w, resp, err)
+	}
+}
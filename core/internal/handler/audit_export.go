@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud_disk/core/internal/types"
+)
+
+// auditCSVHeader 审计日志 CSV 导出的表头。
+var auditCSVHeader = []string{"identity", "created_at", "event_type", "user_identity", "actor_identity", "repository_identity", "ip", "detail"}
+
+// writeAuditExport 以附件形式输出审计日志，format 为 csv 或 json。
+func writeAuditExport(w http.ResponseWriter, name, format string, items []*types.AuditLogItem) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102150405"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(items)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	// 写入 UTF-8 BOM，便于表格软件正确识别中文
+	_, _ = w.Write([]byte("\xef\xbb\xbf"))
+	cw := csv.NewWriter(w)
+	_ = cw.Write(auditCSVHeader)
+	for _, item := range items {
+		_ = cw.Write([]string{
+			csvCell(item.Identity), csvCell(item.CreatedAt), csvCell(item.EventType), csvCell(item.UserIdentity),
+			csvCell(item.ActorIdentity), csvCell(item.RepositoryIdentity), csvCell(item.Ip), csvCell(item.Detail),
+		})
+	}
+	cw.Flush()
+}
+
+// csvCell 防止 CSV 公式注入：以 = + - @ 或制表符、回车开头的单元格加单引号前缀，
+// 避免文件名等用户可控内容在表格软件中被当作公式执行。
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
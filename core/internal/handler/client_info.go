@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"cloud_disk/core/utils"
+)
+
+// withClientInfo 将客户端 IP、User-Agent 与 Accept-Language 写入 context，供登录限流、会话记录、审计日志与邮件语言使用。
+func withClientInfo(r *http.Request) context.Context {
+	ctx := context.WithValue(r.Context(), "client_ip", utils.ClientIP(r))
+	ctx = context.WithValue(ctx, "accept_language", r.Header.Get("Accept-Language"))
+	return context.WithValue(ctx, "user_agent", r.UserAgent())
+}
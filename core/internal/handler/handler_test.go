@@ -1,11 +1,27 @@
 package handler
 
 import (
+	"bufio"
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/notify"
 	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/prometheus"
+	_ "modernc.org/sqlite"
+	"xorm.io/xorm"
 )
 
 // TestHandlersParseError 验证请求解析失败的处理。
@@ -87,3 +103,215 @@ func TestHealthHandler(t *testing.T) {
 		t.Fatalf("status mismatch: %d", rec.Code)
 	}
 }
+
+// TestWriteAuditExport 验证审计日志 CSV 导出的表头、附件名、字段转义与公式注入防护。
+func TestWriteAuditExport(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeAuditExport(rec, "audit", "csv", []*types.AuditLogItem{
+		{Identity: "e-1", CreatedAt: "2026-01-02 03:04:05", EventType: "rename", UserIdentity: "u-1", ActorIdentity: "u-1", Ip: "10.0.0.1", Detail: "name=a,b->c"},
+	})
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, ".csv") {
+		t.Fatalf("unexpected disposition: %s", cd)
+	}
+	body := strings.TrimPrefix(rec.Body.String(), "\xef\xbb\xbf")
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "identity,created_at,event_type") {
+		t.Fatalf("unexpected csv: %q", body)
+	}
+	if !strings.Contains(lines[1], `"name=a,b->c"`) {
+		t.Fatalf("detail should be quoted: %q", lines[1])
+	}
+
+	// 以公式字符开头的单元格加单引号前缀
+	rec = httptest.NewRecorder()
+	writeAuditExport(rec, "audit", "csv", []*types.AuditLogItem{
+		{Identity: "e-2", EventType: "upload", Ip: "@10.0.0.1", Detail: `=HYPERLINK("http://evil")`},
+		{Identity: "e-3", EventType: "upload", Ip: "+1", Detail: "-2+3"},
+	})
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(rec.Body.String(), "\xef\xbb\xbf"))).ReadAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("parse csv failed: %v %q", err, rec.Body.String())
+	}
+	if records[1][6] != "'@10.0.0.1" || records[1][7] != `'=HYPERLINK("http://evil")` || records[2][6] != "'+1" || records[2][7] != "'-2+3" {
+		t.Fatalf("formula cells not neutralized: %q", records)
+	}
+	if records[1][0] != "e-2" {
+		t.Fatalf("plain cells should be unchanged: %q", records[1])
+	}
+}
+
+// TestNotificationStreamHandler 验证推送连接只接收当前用户的事件并按 SSE 格式输出。
+func TestNotificationStreamHandler(t *testing.T) {
+	hub := notify.NewHub(nil)
+	svcCtx := &svc.ServiceContext{Notifier: hub}
+	stream := NotificationStreamHandler(svcCtx)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream(w, r.WithContext(context.WithValue(r.Context(), "user_identity", "u-1")))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != "retry: 5000\n" {
+		t.Fatalf("unexpected preamble: %q", line)
+	}
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers("u-1") == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = hub.Publish(context.Background(), "u-2", "upload_done", map[string]string{"name": "other.txt"})
+	_ = hub.Publish(context.Background(), "u-1", "upload_done", map[string]string{"name": "a.txt"})
+
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream failed: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "id: ") || lines[1] != "event: upload_done" || lines[2] != `data: {"name":"a.txt"}` {
+		t.Fatalf("unexpected event: %q", lines)
+	}
+}
+
+// TestMetricsHandler 验证 /metrics 未配置令牌时不开放、令牌校验，以及存储调用与去重查询的指标输出。
+func TestMetricsHandler(t *testing.T) {
+	prometheus.Enable()
+	oldLoad, oldUpload := utils.OSSLoadEnv(), utils.OSSUpload()
+	utils.SetOSSLoadEnv(func() error { return nil })
+	utils.SetOSSUpload(func(region, bucket, key string, body io.Reader) (string, error) {
+		return "", errors.New("oss down")
+	})
+	t.Cleanup(func() {
+		utils.SetOSSLoadEnv(oldLoad)
+		utils.SetOSSUpload(oldUpload)
+	})
+	if _, err := utils.UploadToOSS(strings.NewReader("x"), "a.txt"); err == nil {
+		t.Fatal("upload should fail")
+	}
+	utils.ObserveDedup(utils.DedupStageBloom, true)
+
+	svcCtx := &svc.ServiceContext{}
+	rec := httptest.NewRecorder()
+	MetricsHandler(svcCtx).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("metrics without token should be disabled: %d", rec.Code)
+	}
+
+	svcCtx.Config.Metrics.Token = "scrape"
+	rec = httptest.NewRecorder()
+	MetricsHandler(svcCtx).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token should be rejected: %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape")
+	rec = httptest.NewRecorder()
+	MetricsHandler(svcCtx).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status mismatch: %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`cloud_disk_storage_errors_total{op="put"} 1`,
+		`cloud_disk_storage_duration_ms_count{op="put"} 1`,
+		`cloud_disk_dedup_lookups_total{result="hit",stage="bloom"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %s", want)
+		}
+	}
+}
+
+// failReader 被读取即失败的请求体，用于确认处理器未读取请求体。
+type failReader struct {
+	t *testing.T
+}
+
+// Read 标记测试失败。
+func (r failReader) Read([]byte) (int, error) {
+	r.t.Error("request body should not be read")
+	return 0, io.EOF
+}
+
+// TestUploadLinkUploadHandlerChecksLinkFirst 验证匿名上传在读取请求体前校验链接与大小。
+func TestUploadLinkUploadHandlerChecksLinkFirst(t *testing.T) {
+	eng, err := xorm.NewEngine("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("engine init failed: %v", err)
+	}
+	defer eng.Close()
+	if err := utils.EnsureSchema(eng); err != nil {
+		t.Fatalf("ensure schema failed: %v", err)
+	}
+	hash, _ := utils.HashPassword("secret")
+	link := &models.UploadLink{
+		Identity:     "link-1",
+		UserIdentity: "u-1",
+		ExpireAt:     time.Now().Add(time.Hour).Format(common.DataTimeFormat),
+		MaxSize:      1024,
+		Password:     hash,
+	}
+	if _, err := eng.InsertOne(link); err != nil {
+		t.Fatalf("insert upload link failed: %v", err)
+	}
+	svcCtx := &svc.ServiceContext{DBEngine: eng}
+
+	cases := []struct {
+		name     string
+		target   string
+		password string
+		length   int64
+		status   int
+	}{
+		{name: "missing", target: "/api/drop/upload", length: 10, status: http.StatusBadRequest},
+		{name: "wrongPassword", target: "/api/drop/upload?identity=link-1", password: "wrong", length: 10, status: http.StatusBadRequest},
+		{name: "tooLarge", target: "/api/drop/upload?identity=link-1", password: "secret", length: 1024 + uploadFormOverhead + 1, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, c.target, failReader{t: t})
+			req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+			req.Header.Set(uploadLinkPasswordHeader, base64.StdEncoding.EncodeToString([]byte(c.password)))
+			req.ContentLength = c.length
+			rec := httptest.NewRecorder()
+			UploadLinkUploadHandler(svcCtx).ServeHTTP(rec, req)
+			if rec.Code != c.status {
+				t.Fatalf("status mismatch: %d", rec.Code)
+			}
+		})
+	}
+}
+
+// TestOIDCStateCookie 验证单点登录 state Cookie 为 HttpOnly，且回调时写入 context 供比对。
+func TestOIDCStateCookie(t *testing.T) {
+	rec := httptest.NewRecorder()
+	setOIDCStateCookie(rec, httptest.NewRequest(http.MethodGet, "/api/users/oidc/authorize", nil), "st-1")
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcStateCookie || cookies[0].Value != "st-1" || !cookies[0].HttpOnly || cookies[0].Path != oidcStateCookiePath || cookies[0].MaxAge <= 0 {
+		t.Fatalf("unexpected state cookie: %+v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/oidc/callback", nil)
+	if v := withOIDCState(context.Background(), req).Value("oidc_state"); v != nil {
+		t.Fatalf("missing cookie should leave context empty: %v", v)
+	}
+	req.AddCookie(cookies[0])
+	if v, _ := withOIDCState(context.Background(), req).Value("oidc_state").(string); v != "st-1" {
+		t.Fatalf("state cookie not passed to context: %q", v)
+	}
+}
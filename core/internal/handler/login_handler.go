@@ -24,7 +24,7 @@ func LoginHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			return
 		}
 
-		l := logic.NewLoginLogic(r.Context(), svcCtx)
+		l := logic.NewLoginLogic(withClientInfo(r), svcCtx)
 		resp, err := l.Login(&req)
 		//if err != nil {
 		//	httpx.ErrorCtx(r.Context(), w, err)
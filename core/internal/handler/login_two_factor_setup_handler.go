@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// LoginTwoFactorSetupHandler 登录时绑定两步验证处理入口。
+func LoginTwoFactorSetupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.LoginTwoFactorSetupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewLoginTwoFactorSetupLogic(r.Context(), svcCtx)
+		resp, err := l.LoginTwoFactorSetup(&req)
+		common.Response(r, w, resp, err)
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"cloud_disk/core/internal/svc"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler Prometheus 指标处理入口；要求 Authorization: Bearer <Metrics.Token>，未配置令牌时不开放。
+func MetricsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	metrics := promhttp.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := svcCtx.Config.Metrics.Token
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		metrics.ServeHTTP(w, r)
+	}
+}
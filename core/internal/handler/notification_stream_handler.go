@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/notify"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// notificationHeartbeat 推送连接的心跳间隔，避免代理因连接空闲而断开。
+const notificationHeartbeat = 25 * time.Second
+
+// notificationStreamMaxAge 单个推送连接的最长保持时间，到期后由客户端自动重连。
+const notificationStreamMaxAge = 30 * time.Minute
+
+// NotificationStreamHandler 实时通知推送处理入口，以 text/event-stream 持续推送当前用户的事件。
+func NotificationStreamHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.NotificationStreamRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewNotificationStreamLogic(r.Context(), svcCtx)
+		sub, err := l.NotificationStream(&req)
+		if err != nil {
+			common.Response(r, w, nil, err)
+			return
+		}
+		defer sub.Close()
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprint(w, "retry: 5000\n\n"); err != nil || rc.Flush() != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(notificationHeartbeat)
+		defer heartbeat.Stop()
+		maxAge := time.NewTimer(notificationStreamMaxAge)
+		defer maxAge.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-maxAge.C:
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case event := <-sub.C:
+				if err := writeNotificationEvent(w, event); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeNotificationEvent 按 SSE 格式写出一条事件，事件名为通知类型。
+func writeNotificationEvent(w http.ResponseWriter, event notify.Event) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Data)
+	return err
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// OIDCAuthorizeHandler 单点登录授权地址处理入口。
+func OIDCAuthorizeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.OIDCAuthorizeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewOIDCAuthorizeLogic(r.Context(), svcCtx)
+		resp, err := l.OIDCAuthorize(&req)
+		if err == nil {
+			setOIDCStateCookie(w, r, resp.State)
+		}
+		common.Response(r, w, resp, err)
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// OIDCCallbackHandler 单点登录回调处理入口。
+func OIDCCallbackHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.OIDCCallbackRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewOIDCCallbackLogic(withOIDCState(withClientInfo(r), r), svcCtx)
+		resp, err := l.OIDCCallback(&req)
+		clearOIDCStateCookie(w, r)
+		common.Response(r, w, resp, err)
+	}
+}
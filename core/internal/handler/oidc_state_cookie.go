@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	// oidcStateCookie 绑定单点登录 state 与发起登录的浏览器的 Cookie 名称。
+	oidcStateCookie = "oidc_state"
+	// oidcStateCookiePath Cookie 仅随单点登录接口发送。
+	oidcStateCookiePath = "/api/users/oidc"
+	// oidcStateCookieTTL 与服务端 state 的有效期一致。
+	oidcStateCookieTTL = 10 * time.Minute
+)
+
+// setOIDCStateCookie 写入短期 HttpOnly 的 state Cookie，回调时据此确认由同一浏览器发起。
+func setOIDCStateCookie(w http.ResponseWriter, r *http.Request, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcStateCookiePath,
+		MaxAge:   int(oidcStateCookieTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearOIDCStateCookie 删除 state Cookie，state 无论成功与否只能使用一次。
+func clearOIDCStateCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// withOIDCState 将浏览器持有的 state Cookie 写入 context，供回调逻辑与请求中的 state 比对。
+func withOIDCState(ctx context.Context, r *http.Request) context.Context {
+	if c, err := r.Cookie(oidcStateCookie); err == nil {
+		return context.WithValue(ctx, "oidc_state", c.Value)
+	}
+	return ctx
+}
@@ -24,7 +24,7 @@ func RegisterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			return
 		}
 
-		l := logic.NewRegisterLogic(r.Context(), svcCtx)
+		l := logic.NewRegisterLogic(withClientInfo(r), svcCtx)
 		resp, err := l.Register(&req)
 		//if err != nil {
 		//	httpx.ErrorCtx(r.Context(), w, err)
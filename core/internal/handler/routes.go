@@ -24,6 +24,26 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/login",
 				Handler: LoginHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/login/2fa",
+				Handler: LoginTwoFactorHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/login/2fa/setup",
+				Handler: LoginTwoFactorSetupHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/oidc/authorize",
+				Handler: OIDCAuthorizeHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/oidc/callback",
+				Handler: OIDCCallbackHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodPost,
 				Path:    "/password/reset",
@@ -34,6 +54,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/password/update",
 				Handler: ChangePasswordHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/refresh",
+				Handler: RefreshTokenHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodPost,
 				Path:    "/register",
@@ -44,6 +69,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/send-verification-code",
 				Handler: SendVerificationCodeHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/unlock",
+				Handler: UnlockAccountHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/users"),
 	)
@@ -52,6 +82,95 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithMiddlewares(
 			[]rest.Middleware{serverCtx.FileAuthMiddleware},
 			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/2fa/disable",
+					Handler: TwoFactorDisableHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/2fa/enable",
+					Handler: TwoFactorEnableHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/2fa/setup",
+					Handler: TwoFactorSetupHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/activity",
+					Handler: UserActivityHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/email/change",
+					Handler: ChangeEmailHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/logout",
+					Handler: LogoutHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/quota",
+					Handler: UserQuotaHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/sessions",
+					Handler: SessionListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/sessions/revoke",
+					Handler: SessionRevokeHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/sessions/revoke-others",
+					Handler: SessionRevokeOthersHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/tokens/create",
+					Handler: CreateAccessTokenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/tokens/delete",
+					Handler: AccessTokenDeleteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/tokens/list",
+					Handler: AccessTokenListHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/users"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.FileAuthMiddleware},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/drop/create",
+					Handler: CreateUploadLinkHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/drop/delete",
+					Handler: UploadLinkDeleteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/drop/list",
+					Handler: UploadLinkListHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/upload",
@@ -72,6 +191,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/user/file/name/update",
 					Handler: UserFileNameUpdateHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/user/file/restore",
+					Handler: UserFileRestoreHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/user/folder/create",
@@ -92,6 +216,22 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithPrefix("/api/file"),
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/info",
+				Handler: UploadLinkInfoHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/upload",
+				Handler: UploadLinkUploadHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/drop"),
+	)
+
 	server.AddRoutes(
 		rest.WithMiddlewares(
 			[]rest.Middleware{serverCtx.FileAuthMiddleware},
@@ -111,13 +251,235 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/get",
 					Handler: GetShareRecordHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/grant",
+					Handler: CreateShareGrantHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/qrcode",
+					Handler: ShareQRCodeHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/save",
 					Handler: SaveResourceHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/save/folder",
+					Handler: SaveShareFolderHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/save/job",
+					Handler: SaveJobHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/shared-with-me",
+					Handler: SharedWithMeHandler(serverCtx),
+				},
 			}...,
 		),
 		rest.WithPrefix("/api/share"),
 	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.FileAuthMiddleware, serverCtx.RBACMiddleware},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/2fa/policy",
+					Handler: TwoFactorPolicyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/audit",
+					Handler: AdminAuditHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/audit/verify",
+					Handler: AdminAuditVerifyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/roles",
+					Handler: AdminRoleListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/roles/permissions",
+					Handler: AdminRolePermissionsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/users",
+					Handler: AdminUserListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/users/disable",
+					Handler: AdminUserDisableHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/users/enable",
+					Handler: AdminUserEnableHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/users/password/reset",
+					Handler: AdminPasswordResetHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/users/quota",
+					Handler: AdminUserQuotaHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/users/role",
+					Handler: AdminUserRoleHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/admin"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.FileAuthMiddleware},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/create",
+					Handler: GroupCreateHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/delete",
+					Handler: GroupDeleteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/list",
+					Handler: GroupListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/members",
+					Handler: GroupMemberListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/members/add",
+					Handler: GroupMemberAddHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/members/remove",
+					Handler: GroupMemberRemoveHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/members/role",
+					Handler: GroupMemberRoleHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/groups"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.FileAuthMiddleware},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/create",
+					Handler: CreateWebhookHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/delete",
+					Handler: WebhookDeleteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/deliveries",
+					Handler: WebhookDeliveryListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/deliveries/redeliver",
+					Handler: WebhookRedeliverHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/list",
+					Handler: WebhookListHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/webhooks"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.FileAuthMiddleware},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/list",
+					Handler: NotificationListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/read",
+					Handler: NotificationReadHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/read-all",
+					Handler: NotificationReadAllHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/unread",
+					Handler: NotificationUnreadHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/notifications"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.FileAuthMiddleware},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/stream",
+					Handler: NotificationStreamHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/notifications"),
+		rest.WithSSE(),
+		rest.WithTimeout(0),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/metrics",
+				Handler: MetricsHandler(serverCtx),
+			},
+		},
+	)
 }
@@ -24,7 +24,7 @@ func SendVerificationCodeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			return
 		}
 
-		l := logic.NewSendVerificationCodeLogic(r.Context(), svcCtx)
+		l := logic.NewSendVerificationCodeLogic(withClientInfo(r), svcCtx)
 		resp, err := l.SendVerificationCode(&req)
 		//if err != nil {
 		//	httpx.ErrorCtx(r.Context(), w, err)
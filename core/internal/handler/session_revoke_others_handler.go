@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// SessionRevokeOthersHandler 注销其他全部登录会话处理入口。
+func SessionRevokeOthersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.SessionRevokeOthersRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewSessionRevokeOthersLogic(r.Context(), svcCtx)
+		resp, err := l.SessionRevokeOthers(&req)
+		common.Response(r, w, resp, err)
+	}
+}
@@ -19,7 +19,7 @@ func ShareDownloadURLHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			httpx.ErrorCtx(r.Context(), w, err)
 			return
 		}
-		l := logic.NewShareDownloadURLLogic(r.Context(), svcCtx)
+		l := logic.NewShareDownloadURLLogic(withClientInfo(r), svcCtx)
 		resp, err := l.ShareDownloadURL(&req)
 		common.Response(r, w, resp, err)
 	}
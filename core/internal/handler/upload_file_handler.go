@@ -8,11 +8,8 @@ import (
 	"cloud_disk/core/internal/logic"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
-	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
-	"crypto/md5"
-	"encoding/hex"
-	"io"
+	"errors"
 	"net/http"
 	"os"
 
@@ -23,11 +20,42 @@ import (
 // UploadFileHandler 文件上传处理入口。
 func UploadFileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// 读取请求体之前校验剩余空间，并限制可读取的字节数；此时尚不知道目标目录，按可写入空间中剩余最多的配额预检
+		userIdentity, ok := r.Context().Value("user_identity").(string)
+		if !ok || userIdentity == "" {
+			httpx.ErrorCtx(r.Context(), w, errors.New("用户身份验证失败"))
+			return
+		}
+		l := logic.NewUploadFileLogic(r.Context(), svcCtx)
+		remaining, limited, err := l.UploadLimit()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		if limited {
+			if r.ContentLength > remaining+uploadFormOverhead {
+				writeTooLarge(w, utils.ErrQuotaExceeded.Error())
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, remaining+uploadFormOverhead)
+		}
+
 		var req types.UploadFileRequest
 		if err := httpx.Parse(r, &req); err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 			return
 		}
+		// 目标目录决定文件归属的空间（个人或群组），按该空间的剩余配额校验实际大小
+		owner, err := l.UploadOwner(req.ParentId)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		remaining, limited, err = utils.RemainingQuota(svcCtx.DBEngine, owner)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
 		file, fileHeader, err := r.FormFile("file")
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
@@ -35,11 +63,7 @@ func UploadFileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		}
 		defer file.Close()
 		if fileHeader.Size > 0 && fileHeader.Size > common.MaxUploadSize {
-			httpx.WriteJson(w, http.StatusRequestEntityTooLarge, common.Body{
-				Code: uint32(http.StatusRequestEntityTooLarge),
-				Msg:  "文件过大，超过10GB限制",
-				Data: nil,
-			})
+			writeTooLarge(w, "文件过大，超过10GB限制")
 			return
 		}
 
@@ -52,104 +76,35 @@ func UploadFileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		}
 		// 从文件名提取扩展名（如果 req 中没有提供）
 		if req.Ext == "" {
-			// 获取文件扩展名
-			for i := len(fileHeader.Filename) - 1; i >= 0; i-- {
-				if fileHeader.Filename[i] == '.' {
-					req.Ext = fileHeader.Filename[i:]
-					break
-				}
-			}
+			req.Ext = fileExt(fileHeader.Filename)
 		}
 
 		// 调试日志
 		logx.Infof("文件上传信息 - Name: %s, Ext: %s, Size: %d", req.Name, req.Ext, req.Size)
 
-		// 复制到临时文件夹
-		tempFile, err := os.OpenFile("/tmp/upload-"+utils.UUID()+req.Ext, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o644)
+		// 复制到临时文件夹，同时计算 hash
+		tempPath, hash, size, err := spoolUpload(file, req.Ext)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 			return
 		}
-
-		defer tempFile.Close()
-
-		if _, copyErr := io.Copy(tempFile, file); copyErr != nil {
-			httpx.ErrorCtx(r.Context(), w, copyErr)
+		if size > common.MaxUploadSize {
+			_ = os.Remove(tempPath)
+			writeTooLarge(w, "文件过大，超过10GB限制")
 			return
 		}
-		if fileHeader.Size <= 0 {
-			fileInfo, statErr := tempFile.Stat()
-			if statErr != nil {
-				httpx.ErrorCtx(r.Context(), w, statErr)
-				return
-			}
-			if fileInfo.Size() > common.MaxUploadSize {
-				httpx.WriteJson(w, http.StatusRequestEntityTooLarge, common.Body{
-					Code: uint32(http.StatusRequestEntityTooLarge),
-					Msg:  "文件过大，超过10GB限制",
-					Data: nil,
-				})
-				return
-			}
-		}
-
-		// 从临时文件计算 hash
-		// 将文件指针重置到开头
-		if _, seekErr := tempFile.Seek(0, 0); seekErr != nil {
-			httpx.ErrorCtx(r.Context(), w, seekErr)
+		if limited && size > remaining {
+			_ = os.Remove(tempPath)
+			writeTooLarge(w, utils.ErrQuotaExceeded.Error())
 			return
 		}
 
-		// 初始化 MD5 哈希对象，分块读取文件内容更新哈希
-		h := md5.New()
-		// 定义分块大小（比如 4KB，可根据需求调整，避免内存溢出）
-		buf := make([]byte, 4096)
-		for {
-			// 分块读取文件内容到 buf
-			n, readErr := tempFile.Read(buf)
-			if n > 0 {
-				// 将读取到的有效字节更新到 MD5 哈希对象
-				if _, writeErr := h.Write(buf[:n]); writeErr != nil {
-					httpx.ErrorCtx(r.Context(), w, writeErr)
-					return
-				}
-			}
-			if readErr == io.EOF {
-				// 读取完毕，退出循环
-				break
-			}
-			if readErr != nil {
-				httpx.ErrorCtx(r.Context(), w, readErr)
-				return
-			}
-		}
-
-		// 生成 32 位小写十六进制 MD5 字符串
-		md5Bytes := h.Sum(nil)
-		hash := hex.EncodeToString(md5Bytes)
-
-		// 判断文件是否已存在，现改成从布隆过滤器找
-		isExisted := svcCtx.MyBloomFilter.IsFileExisted(hash)
-
-		// 如果文件信息存在：
-		if isExisted {
-			rp := new(models.RepositoryPool)
-			// 避免假阳
-			has, err := svcCtx.DBEngine.Where("hash=?", hash).Get(rp)
-			if err != nil {
-				httpx.ErrorCtx(r.Context(), w, err)
-				return
-			}
-			l := logic.NewUploadFileLogic(r.Context(), svcCtx)
-			resp, err := l.UploadFile(&req, has, rp.Identity, tempFile.Name(), hash)
-			common.Response(r, w, resp, err)
+		isExisted, repositoryIdentity, err := lookupRepository(svcCtx, hash)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
 			return
 		}
-
-		// 不存在
-		identity := utils.UUID()
-		l := logic.NewUploadFileLogic(r.Context(), svcCtx)
-		resp, err := l.UploadFile(&req, false, identity, tempFile.Name(), hash)
+		resp, err := l.UploadFile(&req, isExisted, repositoryIdentity, tempPath, hash)
 		common.Response(r, w, resp, err)
 	}
 }
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// uploadFormOverhead 预估的 multipart 表单额外开销，按剩余空间限制请求体时预留。
+const uploadFormOverhead = 1 << 20
+
+// spoolUpload 将上传内容写入临时文件并同时计算 MD5，返回临时文件路径、哈希与实际大小。
+func spoolUpload(src io.Reader, ext string) (string, string, int64, error) {
+	tempFile, err := os.OpenFile("/tmp/upload-"+utils.UUID()+ext, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer tempFile.Close()
+
+	h := md5.New()
+	size, err := io.Copy(io.MultiWriter(tempFile, h), src)
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+		return "", "", 0, err
+	}
+	return tempFile.Name(), hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// lookupRepository 通过布隆过滤器与数据库判断文件是否已存在，返回是否存在与存储标识；各阶段命中情况计入去重指标。
+func lookupRepository(svcCtx *svc.ServiceContext, hash string) (bool, string, error) {
+	// 判断文件是否已存在，先从布隆过滤器找，命中后查库避免假阳
+	maybe := svcCtx.MyBloomFilter.IsFileExisted(hash)
+	utils.ObserveDedup(utils.DedupStageBloom, maybe)
+	if maybe {
+		rp := new(models.RepositoryPool)
+		has, err := svcCtx.DBEngine.Where("hash=?", hash).Get(rp)
+		if err != nil {
+			return false, "", err
+		}
+		utils.ObserveDedup(utils.DedupStageDB, has)
+		if has {
+			return true, rp.Identity, nil
+		}
+	}
+	return false, utils.UUID(), nil
+}
+
+// fileExt 从文件名提取扩展名（含点）。
+func fileExt(name string) string {
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] == '.' {
+			return name[i:]
+		}
+	}
+	return ""
+}
+
+// writeTooLarge 返回文件过大的统一响应。
+func writeTooLarge(w http.ResponseWriter, msg string) {
+	httpx.WriteJson(w, http.StatusRequestEntityTooLarge, common.Body{
+		Code: uint32(http.StatusRequestEntityTooLarge),
+		Msg:  msg,
+		Data: nil,
+	})
+}
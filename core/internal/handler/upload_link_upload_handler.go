@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// uploadLinkPasswordHeader 上传链接密码请求头，避免密码出现在访问日志的 URL 中。
+const uploadLinkPasswordHeader = "X-Upload-Password"
+
+// UploadLinkUploadHandler 匿名上传处理入口。
+func UploadLinkUploadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 匿名请求：链接与密码从查询参数/请求头读取，校验通过后再按链接上限读取请求体
+		query := r.URL.Query()
+		req := types.UploadLinkUploadRequest{
+			Identity: query.Get("identity"),
+			Password: r.Header.Get(uploadLinkPasswordHeader),
+		}
+		if req.Password == "" {
+			req.Password = query.Get("password")
+		}
+		l := logic.NewUploadLinkUploadLogic(withClientInfo(r), svcCtx)
+		link, err := l.AuthorizeUploadLink(&req)
+		if err != nil {
+			common.Response(r, w, nil, err)
+			return
+		}
+		if r.ContentLength > link.MaxSize+uploadFormOverhead {
+			writeTooLarge(w, "文件过大，超过上传链接限制")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, link.MaxSize+uploadFormOverhead)
+
+		file, fileHeader, err := r.FormFile("file")
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		defer file.Close()
+
+		name := filepath.Base(filepath.Clean("/" + fileHeader.Filename))
+		ext := fileExt(name)
+		if err := l.CheckUploadFile(link, ext, fileHeader.Size); err != nil {
+			common.Response(r, w, nil, err)
+			return
+		}
+
+		tempPath, hash, size, err := spoolUpload(file, ext)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		if size > link.MaxSize {
+			_ = os.Remove(tempPath)
+			writeTooLarge(w, "文件过大，超过上传链接限制")
+			return
+		}
+
+		isExisted, repositoryIdentity, err := lookupRepository(svcCtx, hash)
+		if err != nil {
+			_ = os.Remove(tempPath)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		resp, err := l.UploadLinkUpload(link, &types.UploadFileRequest{Name: name, Ext: ext, Size: size, Hash: hash}, isExisted, repositoryIdentity, tempPath, hash)
+		common.Response(r, w, resp, err)
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/logic"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// UserActivityHandler 用户操作记录处理入口，format 为 csv 或 json 时以附件导出。
+func UserActivityHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UserActivityRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		l := logic.NewUserActivityLogic(r.Context(), svcCtx)
+		resp, err := l.UserActivity(&req)
+		if err != nil || req.Format == "" {
+			common.Response(r, w, resp, err)
+			return
+		}
+		writeAuditExport(w, "activity", req.Format, resp.List)
+	}
+}
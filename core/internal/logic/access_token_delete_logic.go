@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AccessTokenDeleteLogic 吊销个人访问令牌逻辑。
+type AccessTokenDeleteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAccessTokenDeleteLogic 创建吊销个人访问令牌逻辑。
+func NewAccessTokenDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessTokenDeleteLogic {
+	return &AccessTokenDeleteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AccessTokenDelete 吊销（软删除）个人访问令牌，吊销后立即失效。
+func (l *AccessTokenDeleteLogic) AccessTokenDelete(req *types.AccessTokenDeleteRequest) (resp *types.AccessTokenDeleteResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	affected, err := l.svcCtx.DBEngine.Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).Delete(new(models.PersonalAccessToken))
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, errors.New("访问令牌不存在")
+	}
+	return &types.AccessTokenDeleteResponse{}, nil
+}
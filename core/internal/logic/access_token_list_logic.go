@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AccessTokenListLogic 个人访问令牌列表逻辑。
+type AccessTokenListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAccessTokenListLogic 创建个人访问令牌列表逻辑。
+func NewAccessTokenListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessTokenListLogic {
+	return &AccessTokenListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AccessTokenList 获取当前用户的个人访问令牌，不返回令牌明文。
+func (l *AccessTokenListLogic) AccessTokenList(req *types.AccessTokenListRequest) (resp *types.AccessTokenListResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	var tokens []models.PersonalAccessToken
+	if err := l.svcCtx.DBEngine.Where("user_identity = ?", userIdentity).Desc("created_at").Find(&tokens); err != nil {
+		return nil, err
+	}
+	list := make([]*types.AccessTokenItem, 0, len(tokens))
+	for _, t := range tokens {
+		list = append(list, &types.AccessTokenItem{
+			Identity:   t.Identity,
+			Name:       t.Name,
+			Prefix:     t.Prefix,
+			Scopes:     strings.Split(t.Scopes, ","),
+			ExpireAt:   t.ExpireAt,
+			LastUsedAt: t.LastUsedAt,
+			CreatedAt:  t.CreatedAt,
+		})
+	}
+	return &types.AccessTokenListResponse{List: list}, nil
+}
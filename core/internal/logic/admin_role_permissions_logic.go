@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AdminRolePermissionsLogic 设置角色权限逻辑。
+type AdminRolePermissionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAdminRolePermissionsLogic 创建设置角色权限逻辑。
+func NewAdminRolePermissionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminRolePermissionsLogic {
+	return &AdminRolePermissionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminRolePermissions 以覆盖方式设置角色的管理权限。非管理员只能授予自己已拥有的权限。
+func (l *AdminRolePermissionsLogic) AdminRolePermissions(req *types.AdminRolePermissionsRequest) (resp *types.AdminRolePermissionsResponse, err error) {
+	actor, err := requirePermission(l.ctx, l.svcCtx, common.PermRolesManage)
+	if err != nil {
+		return nil, err
+	}
+	role := strings.TrimSpace(req.Role)
+	if !validRoleName(role) {
+		return nil, errors.New("角色名只能包含小写字母、数字、下划线或连字符，且不超过 32 个字符")
+	}
+	if role == common.RoleAdmin {
+		return nil, errors.New("管理员角色默认拥有全部权限，无需设置")
+	}
+	perms := make([]string, 0, len(req.Permissions))
+	seen := map[string]bool{}
+	for _, p := range req.Permissions {
+		p = strings.TrimSpace(p)
+		if !utils.ValidPermission(p) {
+			return nil, fmt.Errorf("不支持的权限: %s", p)
+		}
+		if seen[p] {
+			continue
+		}
+		ok, err := utils.RoleHasPermission(l.svcCtx.DBEngine, actor.Role, p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("不能授予自己没有的权限: %s", p)
+		}
+		seen[p] = true
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+
+	session := l.svcCtx.DBEngine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := session.Where("role = ?", role).Delete(new(models.RolePermission)); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	for _, p := range perms {
+		if _, err := session.Insert(&models.RolePermission{Role: role, Permission: p}); err != nil {
+			_ = session.Rollback()
+			return nil, err
+		}
+	}
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+	recordAdminEvent(l.ctx, l.svcCtx, actor, common.EventAdminRolePermissions, "", fmt.Sprintf("role=%s permissions=%s", role, strings.Join(perms, ",")))
+	l.Infof("role permissions updated role=%s permissions=%v by=%s", role, perms, actor.Identity)
+	return &types.AdminRolePermissionsResponse{Message: "角色权限已更新"}, nil
+}
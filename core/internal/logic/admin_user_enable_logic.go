@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AdminUserEnableLogic 启用用户逻辑。
+type AdminUserEnableLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAdminUserEnableLogic 创建启用用户逻辑。
+func NewAdminUserEnableLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserEnableLogic {
+	return &AdminUserEnableLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserEnable 重新启用被禁用的用户。
+func (l *AdminUserEnableLogic) AdminUserEnable(req *types.AdminUserStatusRequest) (resp *types.AdminUserStatusResponse, err error) {
+	actor, err := requirePermission(l.ctx, l.svcCtx, common.PermUsersWrite)
+	if err != nil {
+		return nil, err
+	}
+	user, err := managedUser(l.svcCtx, actor, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		if _, err := l.svcCtx.DBEngine.Where("identity = ?", user.Identity).Cols("disabled").Update(&models.UserBasic{Disabled: false}); err != nil {
+			return nil, err
+		}
+	}
+	recordAdminEvent(l.ctx, l.svcCtx, actor, common.EventAdminUserEnabled, user.Identity, "name="+user.Name)
+	l.Infof("user enabled identity=%s by=%s", user.Identity, actor.Identity)
+	return &types.AdminUserStatusResponse{Message: "账号已启用"}, nil
+}
@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"context"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"xorm.io/xorm"
+)
+
+// AdminUserListLogic 管理员用户列表逻辑。
+type AdminUserListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAdminUserListLogic 创建管理员用户列表逻辑。
+func NewAdminUserListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserListLogic {
+	return &AdminUserListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// maxAdminPageSize 用户列表单页最大条数。
+const maxAdminPageSize = 100
+
+// AdminUserList 按关键字（用户名或邮箱）与角色分页查询用户。
+func (l *AdminUserListLogic) AdminUserList(req *types.AdminUserListRequest) (resp *types.AdminUserListResponse, err error) {
+	if _, err := requirePermission(l.ctx, l.svcCtx, common.PermUsersRead); err != nil {
+		return nil, err
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.PageSize
+	}
+	if size > maxAdminPageSize {
+		size = maxAdminPageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	query := func() *xorm.Session {
+		session := l.svcCtx.DBEngine.NewSession()
+		if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+			like := "%" + keyword + "%"
+			session.Where("name LIKE ? OR email LIKE ?", like, like)
+		}
+		if role := strings.TrimSpace(req.Role); role != "" {
+			session.And("role = ?", role)
+		}
+		return session
+	}
+	users := make([]*models.UserBasic, 0)
+	listSession := query()
+	defer listSession.Close()
+	if err := listSession.Desc("id").Limit(size, (page-1)*size).Find(&users); err != nil {
+		return nil, err
+	}
+	countSession := query()
+	defer countSession.Close()
+	cnt, err := countSession.Count(new(models.UserBasic))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*types.AdminUserItem, 0, len(users))
+	for _, u := range users {
+		list = append(list, &types.AdminUserItem{
+			Identity:  u.Identity,
+			Name:      u.Name,
+			Email:     u.Email,
+			Role:      u.Role,
+			Disabled:  u.Disabled,
+			CreatedAt: u.CreatedAt,
+		})
+	}
+	return &types.AdminUserListResponse{List: list, Count: cnt}, nil
+}
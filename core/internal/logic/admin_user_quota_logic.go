@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AdminUserQuotaLogic 设置用户存储配额逻辑。
+type AdminUserQuotaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAdminUserQuotaLogic 创建设置用户存储配额逻辑。
+func NewAdminUserQuotaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserQuotaLogic {
+	return &AdminUserQuotaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserQuota 设置用户存储配额，0 表示不限制；低于当前用量时仅阻止后续上传。
+func (l *AdminUserQuotaLogic) AdminUserQuota(req *types.AdminUserQuotaRequest) (resp *types.AdminUserQuotaResponse, err error) {
+	actor, err := requirePermission(l.ctx, l.svcCtx, common.PermUsersWrite)
+	if err != nil {
+		return nil, err
+	}
+	if req.QuotaBytes < 0 {
+		return nil, errors.New("配额不能为负数")
+	}
+	user, err := managedUser(l.svcCtx, actor, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := utils.LoadQuota(l.svcCtx.DBEngine, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.svcCtx.DBEngine.Where("user_identity = ?", user.Identity).Cols("quota_bytes").
+		Update(&models.UserQuota{QuotaBytes: req.QuotaBytes}); err != nil {
+		return nil, err
+	}
+	recordAdminEvent(l.ctx, l.svcCtx, actor, common.EventAdminQuotaChanged, user.Identity,
+		fmt.Sprintf("name=%s quota_bytes=%d->%d", user.Name, quota.QuotaBytes, req.QuotaBytes))
+	l.Infof("user quota changed identity=%s quota=%d by=%s", user.Identity, req.QuotaBytes, actor.Identity)
+	return &types.AdminUserQuotaResponse{Message: "配额已更新"}, nil
+}
@@ -0,0 +1,74 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AdminUserRoleLogic 变更用户角色逻辑。
+type AdminUserRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAdminUserRoleLogic 创建变更用户角色逻辑。
+func NewAdminUserRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserRoleLogic {
+	return &AdminUserRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserRole 变更用户角色，角色为空表示普通用户；非管理员只能授予或收回权限不超过自己的角色，admin 角色只有管理员可以授予。
+func (l *AdminUserRoleLogic) AdminUserRole(req *types.AdminUserRoleRequest) (resp *types.AdminUserRoleResponse, err error) {
+	actor, err := requirePermission(l.ctx, l.svcCtx, common.PermUsersWrite)
+	if err != nil {
+		return nil, err
+	}
+	role := strings.TrimSpace(req.Role)
+	if role != "" && !validRoleName(role) {
+		return nil, errors.New("角色名只能包含小写字母、数字、下划线或连字符，且不超过 32 个字符")
+	}
+	user, err := managedUser(l.svcCtx, actor, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	// 只能授予权限不超过自己的角色（当前角色已由 managedUser 校验），避免借助用户管理权限提升他人（或同伙）的权限
+	ok, err := roleWithinActor(l.svcCtx, actor, role)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("权限不足")
+	}
+	if user.Identity == actor.Identity {
+		return nil, errors.New("不能变更自己的角色")
+	}
+	managed, err := ldapManagedRole(l.svcCtx, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if managed {
+		return nil, errors.New("该用户的角色由目录组映射管理，请在目录中调整")
+	}
+	if user.Role == role {
+		return &types.AdminUserRoleResponse{Message: "角色未变化"}, nil
+	}
+	if _, err := l.svcCtx.DBEngine.Where("identity = ?", user.Identity).Cols("role").Update(&models.UserBasic{Role: role}); err != nil {
+		return nil, err
+	}
+	recordAdminEvent(l.ctx, l.svcCtx, actor, common.EventAdminRoleChanged, user.Identity, fmt.Sprintf("name=%s role=%s->%s", user.Name, user.Role, role))
+	l.Infof("user role changed identity=%s role=%s by=%s", user.Identity, role, actor.Identity)
+	return &types.AdminUserRoleResponse{Message: "角色已更新"}, nil
+}
@@ -0,0 +1,192 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// auditPageMaxSize 审计日志分页查询的最大页大小。
+const auditPageMaxSize = 100
+
+// auditExportLimit 单次导出审计日志的最大条数。
+const auditExportLimit = 10000
+
+// auditDateFormat 审计日志按日期筛选时的日期格式。
+const auditDateFormat = "2006-01-02"
+
+// fileEvent 构造当前请求的文件事件日志，操作人与客户端 IP 取自请求上下文。
+func fileEvent(ctx context.Context, eventType, owner, repositoryIdentity, detail string) *models.FileEventLog {
+	actor, _ := ctx.Value("user_identity").(string)
+	ip, _ := ctx.Value("client_ip").(string)
+	return &models.FileEventLog{
+		RepositoryIdentity: repositoryIdentity,
+		UserIdentity:       owner,
+		EventType:          eventType,
+		ActorIdentity:      actor,
+		Detail:             detail,
+		Ip:                 ip,
+	}
+}
+
+// recordFileEvent 记录当前请求的审计日志并触发 webhook，folderId 为事件所在文件夹；写入失败只记录日志，不影响业务流程。
+func recordFileEvent(ctx context.Context, svcCtx *svc.ServiceContext, eventType, owner string, folderId int64, repositoryIdentity, detail string) {
+	event := fileEvent(ctx, eventType, owner, repositoryIdentity, detail)
+	event.FolderId = folderId
+	recordFileEvents(ctx, svcCtx, event)
+}
+
+// recordFileEvents 批量记录审计日志并触发 webhook，写入失败只记录日志。
+func recordFileEvents(ctx context.Context, svcCtx *svc.ServiceContext, events ...*models.FileEventLog) {
+	if len(events) == 0 {
+		return
+	}
+	if err := utils.RecordFileEvent(svcCtx.DBEngine, events...); err != nil {
+		logx.WithContext(ctx).Errorf("record file event failed event=%s count=%d err=%v", events[0].EventType, len(events), err)
+		return
+	}
+	mq.PublishWebhooks(ctx, svcCtx, events...)
+}
+
+// auditFilter 审计日志查询条件，空字段表示不限。
+type auditFilter struct {
+	// Participant 限定为该用户空间内的事件或该用户执行的操作。
+	Participant        string
+	UserIdentity       string
+	ActorIdentity      string
+	EventTypes         string
+	RepositoryIdentity string
+	Ip                 string
+	Start              string
+	End                string
+}
+
+// where 将查询条件转换为 SQL 条件与参数；时间支持 "2006-01-02" 或 "2006-01-02 15:04:05"，按日期筛选时结束日期包含当天。
+func (f *auditFilter) where() (string, []interface{}, error) {
+	conds := []string{"1 = 1"}
+	args := []interface{}{}
+	if f.Participant != "" {
+		conds = append(conds, "(user_identity = ? OR actor_identity = ?)")
+		args = append(args, f.Participant, f.Participant)
+	}
+	for _, c := range []struct{ column, value string }{
+		{"user_identity", f.UserIdentity},
+		{"actor_identity", f.ActorIdentity},
+		{"repository_identity", f.RepositoryIdentity},
+		{"ip", f.Ip},
+	} {
+		if c.value != "" {
+			conds = append(conds, c.column+" = ?")
+			args = append(args, c.value)
+		}
+	}
+	if f.EventTypes != "" {
+		events := strings.Split(f.EventTypes, ",")
+		conds = append(conds, "event_type IN ("+utils.InPlaceholders(len(events))+")")
+		for _, t := range events {
+			args = append(args, strings.TrimSpace(t))
+		}
+	}
+	if f.Start != "" {
+		start, _, err := parseAuditTime(f.Start)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, "created_at >= ?")
+		args = append(args, start.Format(common.DataTimeFormat))
+	}
+	if f.End != "" {
+		end, dateOnly, err := parseAuditTime(f.End)
+		if err != nil {
+			return "", nil, err
+		}
+		if dateOnly {
+			conds = append(conds, "created_at < ?")
+			args = append(args, end.AddDate(0, 0, 1).Format(common.DataTimeFormat))
+		} else {
+			conds = append(conds, "created_at <= ?")
+			args = append(args, end.Format(common.DataTimeFormat))
+		}
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// parseAuditTime 解析筛选时间，返回是否仅包含日期。
+func parseAuditTime(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(common.DataTimeFormat, value, time.Local); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation(auditDateFormat, value, time.Local); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, errors.New("时间格式应为 2006-01-02 或 2006-01-02 15:04:05")
+}
+
+// validAuditFormat 校验导出格式，空字符串表示分页查询。
+func validAuditFormat(format string) error {
+	switch format {
+	case "", "csv", "json":
+		return nil
+	}
+	return errors.New("导出格式仅支持 csv 或 json")
+}
+
+// queryAuditLogs 按条件分页查询审计日志（按时间倒序），count 为 false 时不统计总数（用于导出）。
+func queryAuditLogs(svcCtx *svc.ServiceContext, filter *auditFilter, page, size int, count bool) (*types.AuditLogListResponse, error) {
+	cond, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.AuditLogListResponse{List: make([]*types.AuditLogItem, 0)}
+	if count {
+		resp.Count, err = svcCtx.DBEngine.Where(cond, args...).Count(new(models.FileEventLog))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var logs []models.FileEventLog
+	err = svcCtx.DBEngine.Where(cond, args...).Desc("id").Limit(size, (page-1)*size).Find(&logs)
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		resp.List = append(resp.List, &types.AuditLogItem{
+			Identity:           log.Identity,
+			EventType:          log.EventType,
+			UserIdentity:       log.UserIdentity,
+			ActorIdentity:      log.ActorIdentity,
+			RepositoryIdentity: log.RepositoryIdentity,
+			Ip:                 log.Ip,
+			Detail:             log.Detail,
+			CreatedAt:          log.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+// auditPage 规范化分页参数；导出时固定返回第一页与导出上限。
+func auditPage(page, size int, format string) (int, int) {
+	if format != "" {
+		return 1, auditExportLimit
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = common.PageSize
+	}
+	if size > auditPageMaxSize {
+		size = auditPageMaxSize
+	}
+	return page, size
+}
@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// errEmailTaken 邮箱已被其他账号绑定时返回的错误。
+var errEmailTaken = errors.New("该邮箱已被使用")
+
+// ChangeEmailLogic 更换绑定邮箱逻辑。
+type ChangeEmailLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewChangeEmailLogic 创建更换绑定邮箱逻辑。
+func NewChangeEmailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChangeEmailLogic {
+	return &ChangeEmailLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ChangeEmail 校验旧邮箱与新邮箱的 change_email 验证码后更换绑定邮箱，并通知旧邮箱。
+// 当前未绑定邮箱时只需验证新邮箱。
+func (l *ChangeEmailLogic) ChangeEmail(req *types.ChangeEmailRequest) (resp *types.ChangeEmailResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	newEmail := normalizeEmail(req.NewEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return nil, errors.New("邮箱格式不正确")
+	}
+	user := new(models.UserBasic)
+	has, err := l.svcCtx.DBEngine.Where("identity = ?", userIdentity).Get(user)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("用户不存在")
+	}
+	oldEmail := normalizeEmail(user.Email)
+	if newEmail == oldEmail {
+		return nil, errors.New("新邮箱不能与当前邮箱相同")
+	}
+	if err := l.ensureEmailAvailable(newEmail); err != nil {
+		return nil, err
+	}
+
+	// 两个验证码都校验通过后才销毁，避免一个正确一个错误时白白消耗正确的验证码
+	if oldEmail != "" {
+		if err := checkVerificationCode(l.ctx, l.svcCtx, common.CodePurposeChangeEmail, oldEmail, req.OldCode); err != nil {
+			return nil, fmt.Errorf("原邮箱%s", err.Error())
+		}
+	}
+	if err := checkVerificationCode(l.ctx, l.svcCtx, common.CodePurposeChangeEmail, newEmail, req.NewCode); err != nil {
+		return nil, fmt.Errorf("新邮箱%s", err.Error())
+	}
+	if oldEmail != "" {
+		if err := burnVerificationCode(l.ctx, l.svcCtx, common.CodePurposeChangeEmail, oldEmail); err != nil {
+			return nil, fmt.Errorf("原邮箱%s", err.Error())
+		}
+	}
+	if err := burnVerificationCode(l.ctx, l.svcCtx, common.CodePurposeChangeEmail, newEmail); err != nil {
+		return nil, fmt.Errorf("新邮箱%s", err.Error())
+	}
+
+	if err := l.updateEmail(userIdentity, newEmail); err != nil {
+		return nil, err
+	}
+	if err := utils.RecordFileEvent(l.svcCtx.DBEngine, fileEvent(l.ctx, common.EventEmailChanged, userIdentity, "", "")); err != nil {
+		l.Errorf("email change audit failed identity=%s err=%v", userIdentity, err)
+	}
+	if oldEmail != "" {
+		l.notifyEmailChanged(user, oldEmail, newEmail)
+	}
+	l.Infof("email changed identity=%s", userIdentity)
+	return &types.ChangeEmailResponse{Email: newEmail}, nil
+}
+
+// ensureEmailAvailable 提前校验邮箱未被其他账号使用，避免白白消耗验证码；最终以唯一索引为准。
+func (l *ChangeEmailLogic) ensureEmailAvailable(email string) error {
+	has, err := l.svcCtx.DBEngine.Where("email = ?", email).Exist(new(models.UserBasic))
+	if err != nil {
+		return err
+	}
+	if has {
+		return errEmailTaken
+	}
+	return nil
+}
+
+// updateEmail 更新绑定邮箱；email 列有唯一索引，并发更换到同一邮箱时只有一个请求成功。
+func (l *ChangeEmailLogic) updateEmail(userIdentity, email string) error {
+	_, err := l.svcCtx.DBEngine.Where("identity = ?", userIdentity).Cols("email").Update(&models.UserBasic{Email: email})
+	if utils.IsDuplicateKeyError(err) {
+		return errEmailTaken
+	}
+	return err
+}
+
+// notifyEmailChanged 向旧邮箱发送更换提醒，发送失败只记录日志。
+func (l *ChangeEmailLogic) notifyEmailChanged(user *models.UserBasic, oldEmail, newEmail string) {
+	err := mq.SendMail(l.ctx, l.svcCtx, oldEmail, common.MailEmailChanged, utils.MailLang(user.Language), map[string]any{
+		"Name":     user.Name,
+		"Time":     time.Now().Format(common.DataTimeFormat),
+		"NewEmail": maskEmail(newEmail),
+	})
+	if err != nil {
+		l.Errorf("发送邮箱更换通知失败 email=%s err=%v", oldEmail, err)
+	}
+}
+
+// maskEmail 对邮箱用户名部分打码，如 a***@example.com。
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 1 {
+		return email
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -30,6 +30,9 @@ func NewChangePasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ch
 
 // ChangePassword 修改密码。
 func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordRequest) (resp *types.ChangePasswordResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
 	identity, err := resolveChangePasswordIdentity(l.ctx, req)
 	if err != nil {
 		return nil, err
@@ -47,18 +50,22 @@ func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordRequest) (
 		logx.Errorf("password update user not found identity=%s", identity)
 		return nil, errors.New("用户不存在")
 	}
-    if user.Password != utils.Md5(utils.DecodeMaybeBase64(req.OldPassword)) {
+	if ok, _ := utils.VerifyPassword(user.Password, utils.DecodeMaybeBase64(req.OldPassword)); !ok {
 		logx.Errorf("password update old password mismatch identity=%s", identity)
 		return nil, errors.New("旧密码错误")
 	}
-    if utils.DecodeMaybeBase64(req.OldPassword) == utils.DecodeMaybeBase64(req.NewPassword) {
+	if utils.DecodeMaybeBase64(req.OldPassword) == utils.DecodeMaybeBase64(req.NewPassword) {
 		return nil, errors.New("新密码不能与旧密码相同")
 	}
-    if !isPasswordStrong(utils.DecodeMaybeBase64(req.NewPassword)) {
+	if !isPasswordStrong(utils.DecodeMaybeBase64(req.NewPassword)) {
 		return nil, errors.New("密码强度不足")
 	}
 
-    update := &models.UserBasic{Password: utils.Md5(utils.DecodeMaybeBase64(req.NewPassword))}
+	hashed, err := utils.HashPassword(utils.DecodeMaybeBase64(req.NewPassword))
+	if err != nil {
+		return nil, err
+	}
+	update := &models.UserBasic{Password: hashed}
 	affected, err := l.svcCtx.DBEngine.Where("identity = ?", identity).Cols("password").Update(update)
 	if err != nil {
 		logx.Severef("password update failed identity=%s err=%v", identity, err)
@@ -69,8 +76,14 @@ func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordRequest) (
 		return nil, errors.New("更新失败")
 	}
 
-	logx.Infof("password update success identity=%s", identity)
-	return &types.ChangePasswordResponse{Message: "密码更新成功"}, nil
+	// 密码变更后注销全部会话，其他设备上的访问令牌与刷新令牌随即失效
+	revoked, err := revokeUserSessions(l.ctx, l.svcCtx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	logx.Infof("password update success identity=%s sessions_revoked=%d", identity, revoked)
+	return &types.ChangePasswordResponse{Message: "密码更新成功，请重新登录"}, nil
 }
 
 // resolveChangePasswordIdentity 解析并校验修改密码请求中的身份信息。
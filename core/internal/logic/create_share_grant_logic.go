@@ -0,0 +1,226 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// CreateShareGrantLogic 定向分享逻辑。
+type CreateShareGrantLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCreateShareGrantLogic 创建定向分享逻辑。
+func NewCreateShareGrantLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateShareGrantLogic {
+	return &CreateShareGrantLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateShareGrant 将文件或文件夹定向分享给指定用户或邮箱。
+func (l *CreateShareGrantLogic) CreateShareGrant(req *types.CreateShareGrantRequest) (resp *types.CreateShareGrantResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	permission := strings.TrimSpace(req.Permission)
+	if permission == "" {
+		permission = common.SharePermissionView
+	}
+	if _, ok := sharePermissionRank[permission]; !ok {
+		return nil, errors.New("分享权限无效")
+	}
+	if strings.TrimSpace(req.Identity) == "" {
+		return nil, errors.New("分享对象不能为空")
+	}
+
+	item := new(models.UserRepository)
+	has, err := l.svcCtx.DBEngine.
+		Where("identity = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.Identity, userIdentity, common.StatusDeleted).
+		Get(item)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("文件不存在")
+	}
+
+	grants, recipients, err := l.resolveGrantees(userIdentity, req.Users, req.Emails)
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		return nil, errors.New("分享对象用户不能为空")
+	}
+
+	share := &models.ShareBasic{
+		Identity:               utils.UUID(),
+		UserIdentity:           userIdentity,
+		RepositoryIdentity:     item.RepositoryIdentity,
+		UserRepositoryIdentity: item.Identity,
+		Scope:                  common.ShareScopeRestricted,
+		ExpiredTime:            req.ExpiredTime,
+	}
+	for i := range grants {
+		grants[i].Identity = utils.UUID()
+		grants[i].ShareIdentity = share.Identity
+		grants[i].Permission = permission
+	}
+
+	if err = insertShareWithCode(share, func() error { return l.insertShareGrants(share, grants) }); err != nil {
+		return nil, err
+	}
+
+	recordFileEvent(l.ctx, l.svcCtx, common.EventShare, userIdentity, item.ParentId, share.RepositoryIdentity,
+		fmt.Sprintf("share=%s scope=%s permission=%s grantees=%d", share.Identity, share.Scope, permission, len(grants)))
+	l.notifyGrantees(userIdentity, share, item, permission, grants, recipients)
+	return &types.CreateShareGrantResponse{Identity: share.Identity, Code: share.Code}, nil
+}
+
+// insertShareGrants 在同一事务中写入分享及其授权记录。
+func (l *CreateShareGrantLogic) insertShareGrants(share *models.ShareBasic, grants []models.ShareGrant) error {
+	session := l.svcCtx.DBEngine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if _, err := session.Insert(share); err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	if _, err := session.Insert(&grants); err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+// resolveGrantees 将用户名与邮箱解析为授权记录，并返回需要通知的邮箱列表。
+func (l *CreateShareGrantLogic) resolveGrantees(ownerIdentity string, names, emails []string) ([]models.ShareGrant, []string, error) {
+	grants := make([]models.ShareGrant, 0, len(names)+len(emails))
+	recipients := make([]string, 0, len(names)+len(emails))
+	seen := map[string]struct{}{}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		user := new(models.UserBasic)
+		has, err := l.svcCtx.DBEngine.Where("name = ?", name).Get(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !has {
+			return nil, nil, fmt.Errorf("用户不存在: %s", name)
+		}
+		if user.Identity == ownerIdentity {
+			continue
+		}
+		if _, ok := seen[user.Identity]; ok {
+			continue
+		}
+		seen[user.Identity] = struct{}{}
+		grants = append(grants, models.ShareGrant{GranteeIdentity: user.Identity, GranteeEmail: strings.ToLower(user.Email)})
+		if user.Email != "" {
+			recipients = append(recipients, user.Email)
+		}
+	}
+
+	for _, addr := range emails {
+		addr = strings.ToLower(strings.TrimSpace(addr))
+		if addr == "" {
+			continue
+		}
+		if !strings.Contains(addr, "@") {
+			return nil, nil, fmt.Errorf("邮箱格式错误: %s", addr)
+		}
+		user := new(models.UserBasic)
+		has, err := l.svcCtx.DBEngine.Where("email = ?", addr).Get(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := addr
+		if has {
+			if user.Identity == ownerIdentity {
+				continue
+			}
+			key = user.Identity
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		grants = append(grants, models.ShareGrant{GranteeIdentity: user.Identity, GranteeEmail: addr})
+		recipients = append(recipients, addr)
+	}
+	return grants, recipients, nil
+}
+
+// notifyGrantees 向已注册的被分享者发送站内通知，并向被分享者发送邮件通知。
+func (l *CreateShareGrantLogic) notifyGrantees(ownerIdentity string, share *models.ShareBasic, item *models.UserRepository, permission string, grants []models.ShareGrant, recipients []string) {
+	owner := new(models.UserBasic)
+	if _, err := l.svcCtx.DBEngine.Where("identity = ?", ownerIdentity).Get(owner); err != nil {
+		logx.Errorf("share grant query owner failed: %v", err)
+	}
+	subject := fmt.Sprintf("%s 与你共享了「%s」", owner.Name, item.Name)
+	notice := &types.ShareReceivedNotice{
+		ShareIdentity: share.Identity,
+		Code:          share.Code,
+		Name:          item.Name,
+		Folder:        item.RepositoryIdentity == "",
+		Permission:    permission,
+		OwnerIdentity: ownerIdentity,
+	}
+	for _, grant := range grants {
+		if grant.GranteeIdentity != "" {
+			mq.AddNotification(l.ctx, l.svcCtx, grant.GranteeIdentity, common.InboxShareReceived, subject,
+				fmt.Sprintf("%s 与你共享了「%s」，权限：%s。", owner.Name, item.Name, permission), notice)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	langs := l.recipientLanguages(recipients)
+	for _, addr := range recipients {
+		err := mq.SendMail(l.ctx, l.svcCtx, addr, common.MailShareInvite, utils.MailLang(langs[strings.ToLower(addr)]), map[string]any{
+			"Owner":      owner.Name,
+			"Name":       item.Name,
+			"Folder":     item.RepositoryIdentity == "",
+			"Permission": permission,
+			"URL":        shareURL(share),
+		})
+		if err != nil {
+			l.Errorf("发送分享通知邮件失败 email=%s err=%v", addr, err)
+		}
+	}
+}
+
+// recipientLanguages 查询已注册收件人的邮件语言，未注册的邮箱不在结果中。
+func (l *CreateShareGrantLogic) recipientLanguages(recipients []string) map[string]string {
+	langs := make(map[string]string, len(recipients))
+	var users []models.UserBasic
+	if err := l.svcCtx.DBEngine.In("email", recipients).Cols("email", "language").Find(&users); err != nil {
+		l.Errorf("share grant query recipient language failed: %v", err)
+		return langs
+	}
+	for _, u := range users {
+		langs[strings.ToLower(u.Email)] = u.Language
+	}
+	return langs
+}
@@ -4,12 +4,14 @@
 package logic
 
 import (
+	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
 	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -39,13 +41,40 @@ func (l *CreateShareRecordLogic) CreateShareRecord(req *types.CreateShareRecordR
 	data := new(models.ShareBasic)
 	data.UserIdentity = userIdentity
 	data.RepositoryIdentity = req.Identity
+	data.Scope = common.ShareScopePublic
 	data.ExpiredTime = req.ExpiredTime
+	var folderId int64
+	if req.UserRepositoryIdentity != "" {
+		item := new(models.UserRepository)
+		has, err := l.svcCtx.DBEngine.
+			Where("identity = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.UserRepositoryIdentity, userIdentity, common.StatusDeleted).
+			Get(item)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, errors.New("文件不存在")
+		}
+		data.RepositoryIdentity = item.RepositoryIdentity
+		data.UserRepositoryIdentity = item.Identity
+		folderId = item.ParentId
+	}
+	if data.RepositoryIdentity == "" && data.UserRepositoryIdentity == "" {
+		return nil, errors.New("分享对象不能为空")
+	}
 	data.Identity = utils.UUID()
-	_, err = l.svcCtx.DBEngine.Insert(data)
+	err = insertShareWithCode(data, func() error {
+		_, err := l.svcCtx.DBEngine.Insert(data)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	recordFileEvent(l.ctx, l.svcCtx, common.EventShare, userIdentity, folderId, data.RepositoryIdentity,
+		fmt.Sprintf("share=%s scope=%s expired_time=%d", data.Identity, data.Scope, data.ExpiredTime))
 	return &types.CreateShareRecordResponse{
 		Identity: data.Identity,
+		Code:     data.Code,
+		URL:      shareURL(data),
 	}, nil
 }
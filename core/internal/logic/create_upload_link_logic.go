@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// defaultUploadLinkTTL 上传链接默认有效期。
+const defaultUploadLinkTTL = 7 * 24 * time.Hour
+
+// maxUploadLinkTTL 上传链接最长有效期。
+const maxUploadLinkTTL = 90 * 24 * time.Hour
+
+// CreateUploadLinkLogic 创建匿名上传链接逻辑。
+type CreateUploadLinkLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCreateUploadLinkLogic 创建匿名上传链接逻辑。
+func NewCreateUploadLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateUploadLinkLogic {
+	return &CreateUploadLinkLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateUploadLink 创建指向目标文件夹的匿名上传链接。
+func (l *CreateUploadLinkLogic) CreateUploadLink(req *types.CreateUploadLinkRequest) (resp *types.CreateUploadLinkResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	if req.ParentId != 0 {
+		folder := new(models.UserRepository)
+		has, err := l.svcCtx.DBEngine.
+			Where("id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.ParentId, userIdentity, common.StatusDeleted).
+			Get(folder)
+		if err != nil {
+			return nil, err
+		}
+		if !has || folder.RepositoryIdentity != "" {
+			return nil, errors.New("目标文件夹不存在")
+		}
+	}
+	ttl := time.Duration(req.ExpiredTime) * time.Second
+	if ttl <= 0 {
+		ttl = defaultUploadLinkTTL
+	}
+	if ttl > maxUploadLinkTTL {
+		ttl = maxUploadLinkTTL
+	}
+	maxSize := req.MaxSize
+	if maxSize <= 0 || maxSize > common.MaxUploadSize {
+		maxSize = common.MaxUploadSize
+	}
+	password := ""
+	if p := utils.DecodeMaybeBase64(req.Password); p != "" {
+		if password, err = utils.HashPassword(p); err != nil {
+			return nil, err
+		}
+	}
+
+	link := &models.UploadLink{
+		Identity:     utils.UUID(),
+		UserIdentity: userIdentity,
+		ParentId:     req.ParentId,
+		ExpireAt:     time.Now().Add(ttl).Format(common.DataTimeFormat),
+		MaxSize:      maxSize,
+		AllowedExts:  normalizeAllowedExts(req.AllowedExts),
+		Password:     password,
+	}
+	if _, err := l.svcCtx.DBEngine.Insert(link); err != nil {
+		return nil, err
+	}
+	return &types.CreateUploadLinkResponse{Identity: link.Identity, ExpireAt: link.ExpireAt}, nil
+}
+
+// normalizeAllowedExts 规范化扩展名列表为小写、带点、逗号分隔的字符串。
+func normalizeAllowedExts(exts []string) string {
+	out := make([]string, 0, len(exts))
+	seen := map[string]struct{}{}
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if _, ok := seen[ext]; ok {
+			continue
+		}
+		seen[ext] = struct{}{}
+		out = append(out, ext)
+	}
+	return strings.Join(out, ",")
+}
+
+// splitAllowedExts 将存储的扩展名字符串拆分为列表。
+func splitAllowedExts(exts string) []string {
+	if exts == "" {
+		return []string{}
+	}
+	return strings.Split(exts, ",")
+}
@@ -0,0 +1,89 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// CreateWebhookLogic 创建 webhook逻辑。
+type CreateWebhookLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCreateWebhookLogic 创建创建 webhook逻辑。
+func NewCreateWebhookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWebhookLogic {
+	return &CreateWebhookLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateWebhook 创建 webhook 订阅并返回签名密钥。个人订阅限定在当前用户有所有者权限的空间（parent_id 所在空间，0 为个人根目录），
+// 事件发生在该文件夹或其子孙目录中时触发；global 为 true 时创建全站订阅，需要 webhooks:manage 权限。
+func (l *CreateWebhookLogic) CreateWebhook(req *types.CreateWebhookRequest) (resp *types.CreateWebhookResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	hookURL, err := validWebhookURL(l.ctx, req.Url)
+	if err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret := strings.TrimSpace(req.Secret)
+	if secret != "" {
+		if err := validWebhookSecret(secret); err != nil {
+			return nil, err
+		}
+	} else if secret, err = utils.GenerateWebhookSecret(); err != nil {
+		return nil, err
+	}
+
+	space := ""
+	if req.Global {
+		if req.ParentId != 0 {
+			return nil, errors.New("全站 webhook 不支持限定文件夹")
+		}
+		if _, err := requirePermission(l.ctx, l.svcCtx, common.PermWebhooksManage); err != nil {
+			return nil, err
+		}
+	} else if space, err = spaceOfParent(l.svcCtx, userIdentity, req.ParentId, common.GroupRoleOwner); err != nil {
+		return nil, err
+	}
+	cnt, err := l.svcCtx.DBEngine.Where("user_identity = ?", userIdentity).Count(new(models.Webhook))
+	if err != nil {
+		return nil, err
+	}
+	if cnt >= maxWebhooksPerUser {
+		return nil, errors.New("webhook 数量已达上限")
+	}
+
+	hook := &models.Webhook{
+		Identity:      utils.UUID(),
+		UserIdentity:  userIdentity,
+		SpaceIdentity: space,
+		ParentId:      req.ParentId,
+		Url:           hookURL,
+		EventTypes:    events,
+		Secret:        secret,
+	}
+	if _, err := l.svcCtx.DBEngine.Insert(hook); err != nil {
+		return nil, err
+	}
+	return &types.CreateWebhookResponse{Identity: hook.Identity, Secret: secret}, nil
+}
@@ -50,10 +50,23 @@ func (l *DownloadURLLogic) DownloadURL(req *types.DownloadURLRequest) (resp *typ
 	if err != nil {
 		return nil, err
 	}
+	// 非本人文件时，依次校验群组空间与定向分享的下载权限，并取得文件所在空间与目录用于审计
 	if !has {
+		if ref, err = groupRepository(l.svcCtx, userIdentity, req.RepositoryIdentity); err != nil {
+			return nil, err
+		}
+	}
+	if ref == nil {
+		if ref, err = grantedRepository(l.svcCtx, userIdentity, req.RepositoryIdentity, common.SharePermissionDownload); err != nil {
+			return nil, err
+		}
+	}
+	if ref == nil {
 		return nil, errors.New("文件不存在")
 	}
 
+	recordFileEvent(l.ctx, l.svcCtx, common.EventDownload, ref.UserIdentity, ref.ParentId, req.RepositoryIdentity, fmt.Sprintf("expires=%d", expires))
+
 	repo := new(models.RepositoryPool)
 	has, err = l.svcCtx.DBEngine.Where("identity = ?", req.RepositoryIdentity).Get(repo)
 	if err != nil {
@@ -5,7 +5,9 @@ package logic
 
 import (
 	"context"
+	"errors"
 
+	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
 
@@ -30,16 +32,38 @@ func NewGetShareRecordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ge
 
 // GetShareRecord 获取分享记录。
 func (l *GetShareRecordLogic) GetShareRecord(req *types.GetShareRecordRequest) (resp *types.GetShareRecordResponse, err error) {
+	share, has, err := loadShare(l.svcCtx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	shareIdentity := req.Identity
+	if has {
+		shareIdentity = share.Identity
+	}
+	if has && share.Scope == common.ShareScopeRestricted {
+		userIdentity, _ := l.ctx.Value("user_identity").(string)
+		perm, permErr := shareGrantPermission(l.svcCtx, share, userIdentity)
+		if permErr != nil {
+			return nil, permErr
+		}
+		if !permissionAllows(perm, common.SharePermissionView) {
+			return nil, errors.New("无权查看该分享")
+		}
+	}
+
 	resp = &types.GetShareRecordResponse{}
 	_, err = l.svcCtx.DBEngine.Table("share_basic").
 		Select("share_basic.identity, repository_pool.identity as repository_identity, user_repository.name, repository_pool.ext, repository_pool.size, repository_pool.path").
-		Where("share_basic.identity = ?", req.Identity).
+		Where("share_basic.identity = ?", shareIdentity).
 		Join("LEFT", "repository_pool", "share_basic.repository_identity = repository_pool.identity").
 		Join("LEFT", "user_repository", "repository_pool.identity = user_repository.repository_identity").
 		Get(resp)
 	if err != nil {
 		return nil, err
 	}
+	if has {
+		notifyShareAccess(l.ctx, l.svcCtx, share, common.ShareActionView, resp.Name)
+	}
 
 	return resp, nil
 }
@@ -0,0 +1,210 @@
+package logic
+
+import (
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+)
+
+// groupRoleRank 群组角色等级，数值越大权限越高。
+var groupRoleRank = map[string]int{
+	common.GroupRoleViewer: 1,
+	common.GroupRoleEditor: 2,
+	common.GroupRoleOwner:  3,
+}
+
+// validGroupRole 校验群组角色是否合法。
+func validGroupRole(role string) bool {
+	_, ok := groupRoleRank[role]
+	return ok
+}
+
+// groupRoleAllows 判断已有群组角色是否满足所需角色。
+func groupRoleAllows(have, need string) bool {
+	return have != "" && groupRoleRank[have] >= groupRoleRank[need]
+}
+
+// groupRole 查询用户在群组中的角色，非成员返回空字符串。
+func groupRole(svcCtx *svc.ServiceContext, groupIdentity, userIdentity string) (string, error) {
+	member := new(models.GroupMember)
+	has, err := svcCtx.DBEngine.
+		Where("group_identity = ? AND user_identity = ?", groupIdentity, userIdentity).
+		Get(member)
+	if err != nil || !has {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// memberGroups 查询用户角色不低于 need 的全部群组标识。
+func memberGroups(svcCtx *svc.ServiceContext, userIdentity, need string) ([]string, error) {
+	var members []models.GroupMember
+	if err := svcCtx.DBEngine.Where("user_identity = ?", userIdentity).Find(&members); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		if groupRoleAllows(m.Role, need) {
+			ids = append(ids, m.GroupIdentity)
+		}
+	}
+	return ids, nil
+}
+
+// spaceRecipients 返回存储空间的通知对象：个人空间为本人；群组空间为 editor 及以上的成员（可恢复回收站的人），同时返回群组名称。
+func spaceRecipients(svcCtx *svc.ServiceContext, owner string) ([]string, string, error) {
+	group := new(models.GroupBasic)
+	has, err := svcCtx.DBEngine.Where("identity = ?", owner).Get(group)
+	if err != nil {
+		return nil, "", err
+	}
+	if !has {
+		return []string{owner}, "", nil
+	}
+	var members []models.GroupMember
+	if err := svcCtx.DBEngine.Where("group_identity = ?", owner).Asc("id").Find(&members); err != nil {
+		return nil, "", err
+	}
+	users := make([]string, 0, len(members))
+	for _, m := range members {
+		if groupRoleAllows(m.Role, common.GroupRoleEditor) {
+			users = append(users, m.UserIdentity)
+		}
+	}
+	return users, group.Name, nil
+}
+
+// groupRepository 查询位于用户所属任一群组空间中的存储文件记录，群组成员均可下载；不在任何所属群组中时返回 nil。
+func groupRepository(svcCtx *svc.ServiceContext, userIdentity, repositoryIdentity string) (*models.UserRepository, error) {
+	groups, err := memberGroups(svcCtx, userIdentity, common.GroupRoleViewer)
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	ref := new(models.UserRepository)
+	has, err := svcCtx.DBEngine.In("user_identity", groups).
+		Where("repository_identity = ? AND (status != ? OR status IS NULL)", repositoryIdentity, common.StatusDeleted).
+		Get(ref)
+	if err != nil || !has {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// errGroupRoleDenied 群组成员角色不足时返回的错误。
+var errGroupRoleDenied = errors.New("群组权限不足")
+
+// spaceRole 查询用户在某个存储空间中的角色：个人空间的本人视为所有者，群组空间按成员角色，无权访问返回空字符串。
+func spaceRole(svcCtx *svc.ServiceContext, userIdentity, owner string) (string, error) {
+	if owner == userIdentity {
+		return common.GroupRoleOwner, nil
+	}
+	return groupRole(svcCtx, owner, userIdentity)
+}
+
+// authorizeSpace 校验用户对存储空间的角色不低于 need；无权访问时返回 notFound，避免泄露他人文件是否存在。
+func authorizeSpace(svcCtx *svc.ServiceContext, userIdentity, owner, need string, notFound error) error {
+	role, err := spaceRole(svcCtx, userIdentity, owner)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return notFound
+	}
+	if !groupRoleAllows(role, need) {
+		return errGroupRoleDenied
+	}
+	return nil
+}
+
+// errParentNotFound 目标目录不存在或无权访问时返回的错误。
+var errParentNotFound = errors.New("目标文件夹不存在")
+
+// errNodeNotFound 文件或文件夹不存在或无权访问时返回的错误。
+var errNodeNotFound = errors.New("文件或文件夹不存在")
+
+// spaceOfParent 解析目标目录所属空间并校验权限，返回空间所有者（用户或群组标识）；parentId 为 0 表示个人根目录。
+// 需要 editor 权限时，以 edit 权限定向分享给用户的文件夹（及其子文件夹）同样可写入，写入内容归分享者所有。
+func spaceOfParent(svcCtx *svc.ServiceContext, userIdentity string, parentId int64, need string) (string, error) {
+	if parentId == 0 {
+		return userIdentity, nil
+	}
+	parent := new(models.UserRepository)
+	has, err := svcCtx.DBEngine.
+		Where("id = ? AND (status != ? OR status IS NULL)", parentId, common.StatusDeleted).
+		Get(parent)
+	if err != nil {
+		return "", err
+	}
+	if !has || parent.RepositoryIdentity != "" {
+		return "", errParentNotFound
+	}
+	err = authorizeSpace(svcCtx, userIdentity, parent.UserIdentity, need, errParentNotFound)
+	if err == errParentNotFound && need == common.GroupRoleEditor {
+		ok, shareErr := shareEditable(svcCtx, userIdentity, parent)
+		if shareErr != nil {
+			return "", shareErr
+		}
+		if ok {
+			err = nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return parent.UserIdentity, nil
+}
+
+// spaceOfNode 查询未删除的文件或文件夹并校验其所属空间的权限。
+func spaceOfNode(svcCtx *svc.ServiceContext, userIdentity, identity, need string) (*models.UserRepository, error) {
+	node := new(models.UserRepository)
+	has, err := svcCtx.DBEngine.
+		Where("identity = ? AND (status != ? OR status IS NULL)", identity, common.StatusDeleted).
+		Get(node)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errNodeNotFound
+	}
+	if err := authorizeSpace(svcCtx, userIdentity, node.UserIdentity, need, errNodeNotFound); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// isGroupRoot 判断节点是否为群组根目录，群组根目录随群组创建与删除，不允许单独修改。
+func isGroupRoot(node *models.UserRepository, userIdentity string) bool {
+	return node.ParentId == 0 && node.UserIdentity != userIdentity
+}
+
+// groupNameMaxLen 群组名称最大长度（字符数）。
+const groupNameMaxLen = 64
+
+// requireGroupRole 查询群组并校验当前用户角色不低于 need，返回群组与用户角色；非成员视为群组不存在。
+func requireGroupRole(svcCtx *svc.ServiceContext, userIdentity, groupIdentity, need string) (*models.GroupBasic, string, error) {
+	notFound := errors.New("群组不存在")
+	if groupIdentity == "" {
+		return nil, "", errors.New("群组标识不能为空")
+	}
+	group := new(models.GroupBasic)
+	has, err := svcCtx.DBEngine.Where("identity = ?", groupIdentity).Get(group)
+	if err != nil {
+		return nil, "", err
+	}
+	if !has {
+		return nil, "", notFound
+	}
+	role, err := groupRole(svcCtx, groupIdentity, userIdentity)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", notFound
+	}
+	if !groupRoleAllows(role, need) {
+		return nil, "", errGroupRoleDenied
+	}
+	return group, role, nil
+}
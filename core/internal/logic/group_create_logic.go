@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupCreateLogic 创建群组逻辑。
+type GroupCreateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupCreateLogic 创建创建群组逻辑。
+func NewGroupCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupCreateLogic {
+	return &GroupCreateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupCreate 创建群组及其根目录，创建者成为群组所有者。群组文件以群组标识作为所属者，配额按群组单独计算。
+func (l *GroupCreateLogic) GroupCreate(req *types.GroupCreateRequest) (resp *types.GroupCreateResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("群组名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > groupNameMaxLen {
+		return nil, errors.New("群组名称过长")
+	}
+
+	groupIdentity := utils.UUID()
+	root := &models.UserRepository{
+		Identity:     utils.UUID(),
+		UserIdentity: groupIdentity,
+		ParentId:     0,
+		Name:         name,
+		Status:       common.StatusActive,
+	}
+	session := l.svcCtx.DBEngine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := session.Insert(root); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	if root.Id == 0 {
+		if _, err := session.Where("identity = ?", root.Identity).Get(root); err != nil {
+			_ = session.Rollback()
+			return nil, err
+		}
+	}
+	group := &models.GroupBasic{
+		Identity:      groupIdentity,
+		Name:          name,
+		OwnerIdentity: userIdentity,
+		RootId:        int64(root.Id),
+		RootIdentity:  root.Identity,
+	}
+	if _, err := session.Insert(group); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	member := &models.GroupMember{GroupIdentity: groupIdentity, UserIdentity: userIdentity, Role: common.GroupRoleOwner}
+	if _, err := session.Insert(member); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+	return &types.GroupCreateResponse{Identity: groupIdentity, RootId: group.RootId, RootIdentity: root.Identity}, nil
+}
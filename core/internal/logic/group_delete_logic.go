@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupDeleteLogic 删除群组逻辑。
+type GroupDeleteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupDeleteLogic 创建删除群组逻辑。
+func NewGroupDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupDeleteLogic {
+	return &GroupDeleteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupDelete 删除群组，仅所有者可操作，且群组空间中不能有未删除的文件。回收站中的文件按原有规则过期清理。
+func (l *GroupDeleteLogic) GroupDelete(req *types.GroupDeleteRequest) (resp *types.GroupDeleteResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	group, _, err := requireGroupRole(l.svcCtx, userIdentity, req.Identity, common.GroupRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	cnt, err := l.svcCtx.DBEngine.
+		Where("user_identity = ? AND id != ? AND (status != ? OR status IS NULL)", group.Identity, group.RootId, common.StatusDeleted).
+		Count(new(models.UserRepository))
+	if err != nil {
+		return nil, err
+	}
+	if cnt > 0 {
+		return nil, errors.New("群组空间不为空，请先删除其中的文件")
+	}
+
+	session := l.svcCtx.DBEngine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := session.Where("group_identity = ?", group.Identity).Delete(new(models.GroupMember)); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	if _, err := session.Where("id = ? AND user_identity = ?", group.RootId, group.Identity).Delete(new(models.UserRepository)); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	if _, err := session.Where("identity = ?", group.Identity).Delete(new(models.GroupBasic)); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+	return &types.GroupDeleteResponse{Message: "群组已删除"}, nil
+}
@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupListLogic 群组列表逻辑。
+type GroupListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupListLogic 创建群组列表逻辑。
+func NewGroupListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupListLogic {
+	return &GroupListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupList 列出当前用户加入的群组及其角色，根目录编号可直接用于文件列表、上传等接口。
+func (l *GroupListLogic) GroupList(req *types.GroupListRequest) (resp *types.GroupListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	var members []models.GroupMember
+	if err := l.svcCtx.DBEngine.Where("user_identity = ?", userIdentity).Find(&members); err != nil {
+		return nil, err
+	}
+	resp = &types.GroupListResponse{List: make([]*types.GroupItem, 0, len(members))}
+	if len(members) == 0 {
+		return resp, nil
+	}
+	roles := make(map[string]string, len(members))
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		roles[m.GroupIdentity] = m.Role
+		ids = append(ids, m.GroupIdentity)
+	}
+	var groups []models.GroupBasic
+	if err := l.svcCtx.DBEngine.In("identity", ids).Asc("id").Find(&groups); err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		resp.List = append(resp.List, &types.GroupItem{
+			Identity:      g.Identity,
+			Name:          g.Name,
+			Role:          roles[g.Identity],
+			OwnerIdentity: g.OwnerIdentity,
+			RootId:        g.RootId,
+			RootIdentity:  g.RootIdentity,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,71 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupMemberAddLogic 添加群组成员逻辑。
+type GroupMemberAddLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupMemberAddLogic 创建添加群组成员逻辑。
+func NewGroupMemberAddLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupMemberAddLogic {
+	return &GroupMemberAddLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupMemberAdd 按用户名添加群组成员，仅所有者可操作；角色默认为查看者，所有权需通过角色变更转让。
+func (l *GroupMemberAddLogic) GroupMemberAdd(req *types.GroupMemberAddRequest) (resp *types.GroupMemberResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	group, _, err := requireGroupRole(l.svcCtx, userIdentity, req.Identity, common.GroupRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	role := req.Role
+	if role == "" {
+		role = common.GroupRoleViewer
+	}
+	if role != common.GroupRoleEditor && role != common.GroupRoleViewer {
+		return nil, errors.New("成员角色只能是 editor 或 viewer")
+	}
+	if req.UserName == "" {
+		return nil, errors.New("用户名不能为空")
+	}
+	user := new(models.UserBasic)
+	has, err := l.svcCtx.DBEngine.Where("name = ?", req.UserName).Get(user)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("用户不存在")
+	}
+	existing, err := groupRole(l.svcCtx, group.Identity, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if existing != "" {
+		return nil, errors.New("该用户已是群组成员")
+	}
+	member := &models.GroupMember{GroupIdentity: group.Identity, UserIdentity: user.Identity, Role: role}
+	if _, err := l.svcCtx.DBEngine.Insert(member); err != nil {
+		return nil, err
+	}
+	return &types.GroupMemberResponse{Message: "成员已添加"}, nil
+}
@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupMemberListLogic 群组成员列表逻辑。
+type GroupMemberListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupMemberListLogic 创建群组成员列表逻辑。
+func NewGroupMemberListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupMemberListLogic {
+	return &GroupMemberListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupMemberList 列出群组成员，任意成员均可查看。
+func (l *GroupMemberListLogic) GroupMemberList(req *types.GroupMemberListRequest) (resp *types.GroupMemberListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	group, _, err := requireGroupRole(l.svcCtx, userIdentity, req.Identity, common.GroupRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	var members []models.GroupMember
+	if err := l.svcCtx.DBEngine.Where("group_identity = ?", group.Identity).Asc("id").Find(&members); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserIdentity)
+	}
+	names := make(map[string]string, len(members))
+	if len(ids) > 0 {
+		var users []models.UserBasic
+		if err := l.svcCtx.DBEngine.In("identity", ids).Cols("identity", "name").Find(&users); err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.Identity] = u.Name
+		}
+	}
+	resp = &types.GroupMemberListResponse{List: make([]*types.GroupMemberItem, 0, len(members))}
+	for _, m := range members {
+		resp.List = append(resp.List, &types.GroupMemberItem{
+			UserIdentity: m.UserIdentity,
+			Name:         names[m.UserIdentity],
+			Role:         m.Role,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupMemberRemoveLogic 移除群组成员逻辑。
+type GroupMemberRemoveLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupMemberRemoveLogic 创建移除群组成员逻辑。
+func NewGroupMemberRemoveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupMemberRemoveLogic {
+	return &GroupMemberRemoveLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupMemberRemove 移除群组成员。所有者可移除其他成员，普通成员只能移除自己（退出群组），所有者需先转让群组。
+func (l *GroupMemberRemoveLogic) GroupMemberRemove(req *types.GroupMemberRemoveRequest) (resp *types.GroupMemberResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	group, role, err := requireGroupRole(l.svcCtx, userIdentity, req.Identity, common.GroupRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	target := req.UserIdentity
+	if target == "" {
+		target = userIdentity
+	}
+	if target == userIdentity && role == common.GroupRoleOwner {
+		return nil, errors.New("所有者不能退出群组，请先转让群组")
+	}
+	if target != userIdentity && role != common.GroupRoleOwner {
+		return nil, errGroupRoleDenied
+	}
+	affected, err := l.svcCtx.DBEngine.
+		Where("group_identity = ? AND user_identity = ?", group.Identity, target).
+		Delete(new(models.GroupMember))
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, errors.New("该用户不是群组成员")
+	}
+	return &types.GroupMemberResponse{Message: "成员已移除"}, nil
+}
@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GroupMemberRoleLogic 群组成员角色变更逻辑。
+type GroupMemberRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGroupMemberRoleLogic 创建群组成员角色变更逻辑。
+func NewGroupMemberRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupMemberRoleLogic {
+	return &GroupMemberRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupMemberRole 变更成员角色，仅所有者可操作；将其他成员设为 owner 即转让群组，原所有者降为编辑者。
+func (l *GroupMemberRoleLogic) GroupMemberRole(req *types.GroupMemberRoleRequest) (resp *types.GroupMemberResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	group, _, err := requireGroupRole(l.svcCtx, userIdentity, req.Identity, common.GroupRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if !validGroupRole(req.Role) {
+		return nil, errors.New("无效的群组角色")
+	}
+	if req.UserIdentity == "" || req.UserIdentity == userIdentity {
+		return nil, errors.New("不能变更自己的角色")
+	}
+	existing, err := groupRole(l.svcCtx, group.Identity, req.UserIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if existing == "" {
+		return nil, errors.New("该用户不是群组成员")
+	}
+
+	session := l.svcCtx.DBEngine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := session.Table(new(models.GroupMember)).
+		Where("group_identity = ? AND user_identity = ?", group.Identity, req.UserIdentity).
+		Update(map[string]any{"role": req.Role}); err != nil {
+		_ = session.Rollback()
+		return nil, err
+	}
+	if req.Role == common.GroupRoleOwner {
+		if _, err := session.Table(new(models.GroupMember)).
+			Where("group_identity = ? AND user_identity = ?", group.Identity, userIdentity).
+			Update(map[string]any{"role": common.GroupRoleEditor}); err != nil {
+			_ = session.Rollback()
+			return nil, err
+		}
+		if _, err := session.Table(new(models.GroupBasic)).
+			Where("identity = ?", group.Identity).
+			Update(map[string]any{"owner_identity": req.UserIdentity}); err != nil {
+			_ = session.Rollback()
+			return nil, err
+		}
+	}
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+	return &types.GroupMemberResponse{Message: "角色已更新"}, nil
+}
@@ -0,0 +1,243 @@
+package logic
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud_disk/core/internal/config"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ldapIssuer LDAP 账号在 user_external_identity 中的签发方标识，subject 为用户 DN。
+const ldapIssuer = "ldap"
+
+// ldapTimeout 连接与单次请求的超时时间。
+const ldapTimeout = 10 * time.Second
+
+// errLDAPUnavailable 目录服务不可用时返回给调用方的错误。
+var errLDAPUnavailable = errors.New("目录服务暂不可用，请稍后重试")
+
+// ldapEnabled 判断是否启用 LDAP 登录。
+func ldapEnabled(svcCtx *svc.ServiceContext) bool {
+	c := svcCtx.Config.LDAP
+	return c.URL != "" && c.BaseDN != ""
+}
+
+// ldapManaged 判断账号是否交由 LDAP 认证：本地不存在的账号，或已与目录账号绑定的账号。
+// 未绑定的本地账号（如默认管理员）仍使用本地密码。
+func ldapManaged(svcCtx *svc.ServiceContext, user *models.UserBasic, has bool) (bool, error) {
+	if !ldapEnabled(svcCtx) {
+		return false, nil
+	}
+	if !has {
+		return true, nil
+	}
+	cnt, err := svcCtx.DBEngine.Where("issuer = ? AND user_identity = ?", ldapIssuer, user.Identity).Count(new(models.UserExternalIdentity))
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+// ldapAuthenticate 查找目录用户并以其 DN 绑定校验密码，成功后同步（必要时开通）本地用户。
+// 密码错误或用户不存在时返回 ok=false；目录服务异常时返回错误。
+func ldapAuthenticate(svcCtx *svc.ServiceContext, name, password string) (*models.UserBasic, bool, error) {
+	// 空密码会被多数目录视为匿名绑定而"成功"，必须拒绝
+	if name == "" || password == "" {
+		return nil, false, nil
+	}
+	c := svcCtx.Config.LDAP
+	conn, err := dialLDAP(c.URL, c.StartTLS, c.InsecureSkipVerify)
+	if err != nil {
+		logx.Errorf("ldap dial failed: %v", err)
+		return nil, false, errLDAPUnavailable
+	}
+	defer conn.Close()
+
+	if c.BindDN != "" {
+		if err := conn.Bind(c.BindDN, c.BindPassword); err != nil {
+			logx.Errorf("ldap service bind failed: %v", err)
+			return nil, false, errLDAPUnavailable
+		}
+	}
+	emailAttr := defaultString(c.EmailAttribute, "mail")
+	groupAttr := defaultString(c.GroupAttribute, "memberOf")
+	filter := fmt.Sprintf(defaultString(c.UserFilter, "(sAMAccountName=%s)"), ldap.EscapeFilter(name))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		c.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		filter, []string{emailAttr, groupAttr}, nil,
+	))
+	if err != nil {
+		logx.Errorf("ldap search failed: %v", err)
+		return nil, false, errLDAPUnavailable
+	}
+	if len(result.Entries) != 1 {
+		return nil, false, nil
+	}
+	entry := result.Entries[0]
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, false, nil
+		}
+		logx.Errorf("ldap user bind failed dn=%s err=%v", entry.DN, err)
+		return nil, false, errLDAPUnavailable
+	}
+
+	// 仅在配置了组映射时由目录管理角色，否则保留管理员在本地设置的角色
+	var role *string
+	if len(c.GroupRoles) > 0 {
+		mapped := ldapRole(c.GroupRoles, entry.GetAttributeValues(groupAttr))
+		role = &mapped
+	}
+	user, err := syncLDAPUser(svcCtx, name, entry.DN, strings.ToLower(entry.GetAttributeValue(emailAttr)), role)
+	if err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// dialLDAP 连接目录服务，按需升级 StartTLS。
+func dialLDAP(rawURL string, startTLS, insecure bool) (*ldap.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: insecure}
+	conn, err := ldap.DialURL(rawURL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(ldapTimeout)
+	if startTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// ldapRole 按配置顺序返回第一个命中组对应的角色，均未命中时为普通用户（空角色）。
+func ldapRole(mappings []config.GroupRole, groups []string) string {
+	for _, m := range mappings {
+		for _, g := range groups {
+			if strings.EqualFold(strings.TrimSpace(m.Group), g) {
+				return m.Role
+			}
+		}
+	}
+	return ""
+}
+
+// syncLDAPUser 按目录 DN 查找绑定的本地用户并同步邮箱与角色；首次登录时开通本地用户。
+// role 为 nil 表示未配置组映射，此时不改动已有角色，新用户为普通用户。
+func syncLDAPUser(svcCtx *svc.ServiceContext, name, dn, email string, role *string) (*models.UserBasic, error) {
+	link := new(models.UserExternalIdentity)
+	has, err := svcCtx.DBEngine.Where("issuer = ? AND subject = ?", ldapIssuer, dn).Get(link)
+	if err != nil {
+		return nil, err
+	}
+	user := new(models.UserBasic)
+	if has {
+		has, err = svcCtx.DBEngine.Where("identity = ?", link.UserIdentity).Get(user)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, errors.New("绑定的用户不存在")
+		}
+		if (role != nil && user.Role != *role) || (email != "" && user.Email != email) {
+			if email != "" {
+				user.Email = email
+			}
+			if role != nil {
+				user.Role = *role
+			}
+			if _, err := svcCtx.DBEngine.Where("identity = ?", user.Identity).Cols("email", "role").Nullable("email").Update(user); err != nil {
+				if utils.IsDuplicateKeyError(err) {
+					return nil, errEmailTaken
+				}
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+
+	cnt, err := svcCtx.DBEngine.Unscoped().Where("name = ?", name).Count(new(models.UserBasic))
+	if err != nil {
+		return nil, err
+	}
+	if cnt > 0 {
+		return nil, errors.New("同名本地账号已存在，请联系管理员绑定")
+	}
+	// 本地密码为随机值，目录账号只能通过 LDAP 登录
+	secret, err := utils.Base62Code(32)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := utils.HashPassword(secret)
+	if err != nil {
+		return nil, err
+	}
+	user = &models.UserBasic{
+		Identity: utils.UUID(),
+		Name:     name,
+		Password: hashed,
+		Email:    email,
+	}
+	if role != nil {
+		user.Role = *role
+	}
+	// 目录或身份提供方未返回邮箱时写入 NULL，不占用邮箱唯一索引
+	if _, err := svcCtx.DBEngine.Nullable("email").InsertOne(user); err != nil {
+		if utils.IsDuplicateKeyError(err) {
+			return nil, errEmailTaken
+		}
+		return nil, err
+	}
+	if user.Id == 0 {
+		if _, err := svcCtx.DBEngine.Where("identity = ?", user.Identity).Get(user); err != nil {
+			return nil, err
+		}
+	}
+	link = &models.UserExternalIdentity{
+		Identity:     utils.UUID(),
+		UserIdentity: user.Identity,
+		Issuer:       ldapIssuer,
+		Subject:      dn,
+		Email:        email,
+	}
+	if _, err := svcCtx.DBEngine.Insert(link); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ldapManagedRole 判断用户角色是否由目录组映射管理（配置了组映射且账号来自 LDAP）。
+func ldapManagedRole(svcCtx *svc.ServiceContext, userIdentity string) (bool, error) {
+	if len(svcCtx.Config.LDAP.GroupRoles) == 0 {
+		return false, nil
+	}
+	return svcCtx.DBEngine.Where("issuer = ? AND user_identity = ?", ldapIssuer, userIdentity).Exist(new(models.UserExternalIdentity))
+}
+
+// defaultString 值为空时返回默认值。
+func defaultString(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -2,18 +2,39 @@ package logic
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"cloud_disk/core/common"
 	"cloud_disk/core/internal/config"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/smtptest"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
 	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
 
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/jordan-wright/email"
 	"github.com/redis/go-redis/v9"
 	"xorm.io/xorm"
 
@@ -59,13 +80,14 @@ func newTestEnv(t *testing.T) *testEnv {
 
 // fakeRedisClient Redis 客户端测试替身。
 type fakeRedisClient struct {
-	mu   sync.Mutex
-	data map[string]string
+	mu    sync.Mutex
+	data  map[string]string
+	zsets map[string]map[string]float64
 }
 
 // newFakeRedisClient 创建 Redis 测试替身。
 func newFakeRedisClient() *fakeRedisClient {
-	return &fakeRedisClient{data: map[string]string{}}
+	return &fakeRedisClient{data: map[string]string{}, zsets: map[string]map[string]float64{}}
 }
 
 // Get 获取键值。
@@ -107,6 +129,10 @@ func (f *fakeRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd
 			delete(f.data, key)
 			count++
 		}
+		if _, ok := f.zsets[key]; ok {
+			delete(f.zsets, key)
+			count++
+		}
 	}
 	f.mu.Unlock()
 	return redis.NewIntResult(count, nil)
@@ -117,6 +143,74 @@ func (f *fakeRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
 	return redis.NewStatusResult("PONG", nil)
 }
 
+// Scan 按模式遍历键（一次返回全部匹配项）。
+func (f *fakeRedisClient) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0)
+	for key := range f.data {
+		if ok, _ := path.Match(match, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return redis.NewScanCmdResult(keys, 0, nil)
+}
+
+// Expire 设置过期时间（测试替身不处理过期）。
+func (f *fakeRedisClient) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	return redis.NewBoolResult(true, nil)
+}
+
+// ZAdd 向有序集合添加成员。
+func (f *fakeRedisClient) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	set, ok := f.zsets[key]
+	if !ok {
+		set = map[string]float64{}
+		f.zsets[key] = set
+	}
+	var added int64
+	for _, m := range members {
+		member := fmt.Sprint(m.Member)
+		if _, exists := set[member]; !exists {
+			added++
+		}
+		set[member] = m.Score
+	}
+	return redis.NewIntResult(added, nil)
+}
+
+// ZRemRangeByScore 按分数区间删除有序集合成员。
+func (f *fakeRedisClient) ZRemRangeByScore(ctx context.Context, key, min, max string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parse := func(bound string, def float64) (float64, bool) {
+		exclusive := strings.HasPrefix(bound, "(")
+		if v, err := strconv.ParseFloat(strings.TrimPrefix(bound, "("), 64); err == nil {
+			return v, exclusive
+		}
+		return def, exclusive
+	}
+	lo, loEx := parse(min, math.Inf(-1))
+	hi, hiEx := parse(max, math.Inf(1))
+	var removed int64
+	for member, score := range f.zsets[key] {
+		if (score > lo || (!loEx && score == lo)) && (score < hi || (!hiEx && score == hi)) {
+			delete(f.zsets[key], member)
+			removed++
+		}
+	}
+	return redis.NewIntResult(removed, nil)
+}
+
+// ZCard 返回有序集合成员数。
+func (f *fakeRedisClient) ZCard(ctx context.Context, key string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return redis.NewIntResult(int64(len(f.zsets[key])), nil)
+}
+
 // TestLogin 验证登录逻辑。
 func TestLogin(t *testing.T) {
 	env := newTestEnv(t)
@@ -168,7 +262,7 @@ func TestLogin(t *testing.T) {
 // TestRegister 验证注册逻辑。
 func TestRegister(t *testing.T) {
 	env := newTestEnv(t)
-	if err := env.rdb.Set(env.ctx, "verification_code:alice@example.com", "123456", time.Minute).Err(); err != nil {
+	if err := env.rdb.Set(env.ctx, "verification_code:register:alice@example.com", "123456", time.Minute).Err(); err != nil {
 		t.Fatalf("set code failed: %v", err)
 	}
 
@@ -204,6 +298,11 @@ func TestChangePassword(t *testing.T) {
 		t.Fatalf("insert user failed: %v", err)
 	}
 
+	sid, err := createSession(env.ctx, env.svc, "u-1")
+	if err != nil {
+		t.Fatalf("create session failed: %v", err)
+	}
+
 	logic := NewChangePasswordLogic(env.ctx, env.svc)
 	resp, err := logic.ChangePassword(&types.ChangePasswordRequest{
 		Identity:    "u-1",
@@ -222,9 +321,12 @@ func TestChangePassword(t *testing.T) {
 	if err != nil {
 		t.Fatalf("query user failed: %v", err)
 	}
-	if updated.Password != utils.Md5("newpass1") {
+	if ok, _ := utils.VerifyPassword(updated.Password, "newpass1"); !ok {
 		t.Fatalf("password not updated")
 	}
+	if _, ok := env.rdb.data[utils.SessionKey(sid)]; ok {
+		t.Fatal("sessions must be revoked after password change")
+	}
 }
 
 // TestResetPassword 验证重置密码逻辑。
@@ -234,10 +336,15 @@ func TestResetPassword(t *testing.T) {
 	if _, err := env.eng.InsertOne(user); err != nil {
 		t.Fatalf("insert user failed: %v", err)
 	}
-	if err := env.rdb.Set(env.ctx, "verification_code:alice@example.com", "654321", time.Minute).Err(); err != nil {
+	if err := env.rdb.Set(env.ctx, "verification_code:reset:alice@example.com", "654321", time.Minute).Err(); err != nil {
 		t.Fatalf("set code failed: %v", err)
 	}
 
+	sid, err := createSession(env.ctx, env.svc, "u-1")
+	if err != nil {
+		t.Fatalf("create session failed: %v", err)
+	}
+
 	logic := NewResetPasswordLogic(env.ctx, env.svc)
 	resp, err := logic.ResetPassword(&types.ResetPasswordRequest{
 		Email:       "alice@example.com",
@@ -256,28 +363,31 @@ func TestResetPassword(t *testing.T) {
 	if err != nil {
 		t.Fatalf("query user failed: %v", err)
 	}
-	if updated.Password != utils.Md5("newpass1") {
+	if ok, _ := utils.VerifyPassword(updated.Password, "newpass1"); !ok {
 		t.Fatalf("password not updated")
 	}
 
-	val, err := env.rdb.Get(env.ctx, "verification_code:alice@example.com").Result()
+	val, err := env.rdb.Get(env.ctx, "verification_code:reset:alice@example.com").Result()
 	if err == nil || val != "" {
 		t.Fatal("verification code not deleted")
 	}
+	if _, ok := env.rdb.data[utils.SessionKey(sid)]; ok {
+		t.Fatal("sessions must be revoked after password reset")
+	}
 }
 
 // TestSendVerificationCode 验证发送验证码逻辑。
 func TestSendVerificationCode(t *testing.T) {
 	env := newTestEnv(t)
 	logic := NewSendVerificationCodeLogic(env.ctx, env.svc)
-	resp, err := logic.SendVerificationCode(&types.SendVerificationCodeRequest{Email: "alice@example.com"})
+	resp, err := logic.SendVerificationCode(&types.SendVerificationCodeRequest{Email: "alice@example.com", Purpose: common.CodePurposeRegister})
 	if err != nil {
 		t.Fatalf("send code failed: %v", err)
 	}
 	if resp.Message == "" {
 		t.Fatal("empty response")
 	}
-	val, err := env.rdb.Get(env.ctx, "verification_code:alice@example.com").Result()
+	val, err := env.rdb.Get(env.ctx, "verification_code:register:alice@example.com").Result()
 	if err != nil {
 		t.Fatalf("code missing: %v", err)
 	}
@@ -307,7 +417,7 @@ func TestUserDetail(t *testing.T) {
 func TestUploadFile(t *testing.T) {
 	env := newTestEnv(t)
 	logic := NewUploadFileLogic(env.ctx, env.svc)
-	resp, err := logic.UploadFile(&types.UploadFileRequest{Name: "a.txt", Hash: "h", Ext: ".txt", Size: 10, ObjectKey: "k", ParentId: 0}, false, "", "/tmp/a.txt", "h")
+	resp, err := logic.UploadFile(&types.UploadFileRequest{Name: "a.txt", Hash: "h", Ext: ".txt", Size: 10, ParentId: 0}, false, "", "/tmp/a.txt", "h")
 	if env.svc.RabbitMQConn == nil {
 		if err == nil {
 			t.Fatal("expected error")
@@ -526,3 +636,2141 @@ func TestUserFolderDelete(t *testing.T) {
 		t.Fatal("child not deleted")
 	}
 }
+
+// TestShareGrant 验证定向分享、与我共享列表与目录访问授权。
+func TestShareGrant(t *testing.T) {
+	env := newTestEnv(t)
+	users := []*models.UserBasic{
+		{Identity: "u-1", Name: "owner", Email: "owner@example.com"},
+		{Identity: "u-2", Name: "bob", Email: "bob@example.com"},
+		{Identity: "u-3", Name: "eve", Email: "eve@example.com"},
+		{Identity: "u-4", Name: "carol", Email: "carol@example.com"},
+	}
+	for _, u := range users {
+		if _, err := env.eng.InsertOne(u); err != nil {
+			t.Fatalf("insert user failed: %v", err)
+		}
+	}
+	folder := &models.UserRepository{Identity: "dir", UserIdentity: "u-1", ParentId: 0, Name: "docs"}
+	if _, err := env.eng.InsertOne(folder); err != nil {
+		t.Fatalf("insert folder failed: %v", err)
+	}
+	file := &models.UserRepository{Identity: "f1", UserIdentity: "u-1", ParentId: folder.Id, Name: "a.txt", RepositoryIdentity: "r1", Ext: ".txt"}
+	if _, err := env.eng.InsertOne(file); err != nil {
+		t.Fatalf("insert file failed: %v", err)
+	}
+
+	if _, err := NewCreateShareGrantLogic(env.ctx, env.svc).CreateShareGrant(&types.CreateShareGrantRequest{Identity: "dir", Permission: "owner"}); err == nil {
+		t.Fatal("expected invalid permission error")
+	}
+	resp, err := NewCreateShareGrantLogic(env.ctx, env.svc).CreateShareGrant(&types.CreateShareGrantRequest{
+		Identity:   "dir",
+		Users:      []string{"bob"},
+		Emails:     []string{"Guest@Example.com"},
+		Permission: "view",
+	})
+	if err != nil {
+		t.Fatalf("create grant failed: %v", err)
+	}
+	count, err := env.eng.Where("share_identity = ?", resp.Identity).Count(new(models.ShareGrant))
+	if err != nil || count != 2 {
+		t.Fatalf("grant count mismatch: %d %v", count, err)
+	}
+
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	list, err := NewSharedWithMeLogic(bobCtx, env.svc).SharedWithMe(&types.SharedWithMeRequest{})
+	if err != nil {
+		t.Fatalf("shared with me failed: %v", err)
+	}
+	if list.Count != 1 || !list.List[0].IsDir || list.List[0].OwnerName != "owner" || list.List[0].Permission != "view" {
+		t.Fatalf("unexpected shared list: %+v", list.List)
+	}
+
+	files, err := NewUserFileListLogic(bobCtx, env.svc).UserFileList(&types.UserFileListRequest{Id: folder.Id})
+	if err != nil {
+		t.Fatalf("list shared folder failed: %v", err)
+	}
+	if files.Count != 1 || files.List[0].Name != "a.txt" {
+		t.Fatalf("unexpected shared folder list: %+v", files)
+	}
+	if _, err := NewDownloadURLLogic(bobCtx, env.svc).DownloadURL(&types.DownloadURLRequest{RepositoryIdentity: "r1"}); err == nil || err.Error() != "文件不存在" {
+		t.Fatalf("view grant should not allow download: %v", err)
+	}
+
+	if _, err := NewUserFolderCreateLogic(bobCtx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{ParentId: folder.Id, Name: "new"}); err == nil {
+		t.Fatal("view grant should not allow creating folders")
+	}
+
+	eveCtx := context.WithValue(context.Background(), "user_identity", "u-3")
+	if _, err := NewUserFileListLogic(eveCtx, env.svc).UserFileList(&types.UserFileListRequest{Id: folder.Id}); err == nil {
+		t.Fatal("expected access denied for non-grantee")
+	}
+
+	// edit 权限可在分享文件夹内新建与重命名，新内容归分享者所有，分享的文件夹本身不可改名
+	if _, err := NewCreateShareGrantLogic(env.ctx, env.svc).CreateShareGrant(&types.CreateShareGrantRequest{
+		Identity:   "dir",
+		Users:      []string{"carol"},
+		Permission: "edit",
+	}); err != nil {
+		t.Fatalf("create edit grant failed: %v", err)
+	}
+	carolCtx := context.WithValue(context.Background(), "user_identity", "u-4")
+	created, err := NewUserFolderCreateLogic(carolCtx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{ParentId: folder.Id, Name: "drafts"})
+	if err != nil {
+		t.Fatalf("edit grant should allow creating folders: %v", err)
+	}
+	sub := new(models.UserRepository)
+	if has, err := env.eng.Where("identity = ?", created.Identity).Get(sub); err != nil || !has || sub.UserIdentity != "u-1" || sub.ParentId != folder.Id {
+		t.Fatalf("created folder mismatch: %+v %v", sub, err)
+	}
+	if _, err := NewUserFileNameUpdateLogic(carolCtx, env.svc).UserFileNameUpdate(&types.UserFileNameUpdateRequest{Identity: "f1", Name: "b.txt"}); err != nil {
+		t.Fatalf("edit grant should allow renaming inside the folder: %v", err)
+	}
+	if _, err := NewUserFileNameUpdateLogic(carolCtx, env.svc).UserFileNameUpdate(&types.UserFileNameUpdateRequest{Identity: "dir", Name: "mine"}); err == nil {
+		t.Fatal("edit grant should not allow renaming the shared folder itself")
+	}
+	if _, err := NewUserFileNameUpdateLogic(bobCtx, env.svc).UserFileNameUpdate(&types.UserFileNameUpdateRequest{Identity: "f1", Name: "c.txt"}); err == nil {
+		t.Fatal("view grant should not allow renaming")
+	}
+	if _, err := NewUserFolderDeleteLogic(carolCtx, env.svc).UserFolderDelete(&types.UserFolderDeleteRequest{Identity: "f1"}); err == nil {
+		t.Fatal("edit grant should not allow deleting")
+	}
+}
+
+// TestCleanupShares 验证过期与源文件已删除的分享被清理。
+func TestCleanupShares(t *testing.T) {
+	env := newTestEnv(t)
+	file := &models.UserRepository{Identity: "f1", UserIdentity: "u-1", Name: "a.txt", RepositoryIdentity: "r1", Status: "active"}
+	if _, err := env.eng.InsertOne(file); err != nil {
+		t.Fatalf("insert file failed: %v", err)
+	}
+	shares := []*models.ShareBasic{
+		{Identity: "live", Code: "c-live", UserIdentity: "u-1", RepositoryIdentity: "r1"},
+		{Identity: "expired", Code: "c-expired", UserIdentity: "u-1", RepositoryIdentity: "r1", ExpiredTime: 1},
+		{Identity: "orphan", Code: "c-orphan", UserIdentity: "u-1", RepositoryIdentity: "r-gone"},
+		{Identity: "pinned", Code: "c-pinned", UserIdentity: "u-1", RepositoryIdentity: "r1", UserRepositoryIdentity: "f1"},
+		{Identity: "trashed", Code: "c-trashed", UserIdentity: "u-1", RepositoryIdentity: "r2", UserRepositoryIdentity: "f2"},
+	}
+	trashed := &models.UserRepository{Identity: "f2", UserIdentity: "u-1", Name: "b.txt", RepositoryIdentity: "r2", Status: common.StatusDeleted}
+	if _, err := env.eng.InsertOne(trashed); err != nil {
+		t.Fatalf("insert trashed file failed: %v", err)
+	}
+	if _, err := env.eng.Where("identity = ?", "f2").Delete(new(models.UserRepository)); err != nil {
+		t.Fatalf("trash file failed: %v", err)
+	}
+	for _, s := range shares {
+		if _, err := env.eng.InsertOne(s); err != nil {
+			t.Fatalf("insert share failed: %v", err)
+		}
+	}
+	old := time.Now().Add(-time.Hour).Format("2006-01-02 15:04:05")
+	if _, err := env.eng.Exec("UPDATE share_basic SET created_at = ? WHERE identity = ?", old, "expired"); err != nil {
+		t.Fatalf("backdate share failed: %v", err)
+	}
+	_ = env.rdb.Set(env.ctx, "share_download_url:expired:3600", "http://u", time.Hour).Err()
+	_ = env.rdb.Set(env.ctx, "share_download_url:live:3600", "http://u", time.Hour).Err()
+
+	cleanupShares(env.ctx, env.svc)
+
+	var left []models.ShareBasic
+	if err := env.eng.Find(&left); err != nil {
+		t.Fatalf("query shares failed: %v", err)
+	}
+	if len(left) != 2 || left[0].Identity != "live" || left[1].Identity != "pinned" {
+		t.Fatalf("unexpected remaining shares: %+v", left)
+	}
+	if _, ok := env.rdb.data["share_download_url:expired:3600"]; ok {
+		t.Fatal("expired share cache not cleared")
+	}
+	if _, ok := env.rdb.data["share_download_url:live:3600"]; !ok {
+		t.Fatal("live share cache should be kept")
+	}
+	events, err := env.eng.In("event_type", "share_expired", "share_orphaned").Count(new(models.FileEventLog))
+	if err != nil || events != 3 {
+		t.Fatalf("event count mismatch: %d %v", events, err)
+	}
+}
+
+// TestSaveShareFolder 验证转存分享文件夹（同步与后台任务）。
+func TestSaveShareFolder(t *testing.T) {
+	env := newTestEnv(t)
+	folder := &models.UserRepository{Identity: "dir", UserIdentity: "u-2", ParentId: 0, Name: "docs"}
+	if _, err := env.eng.InsertOne(folder); err != nil {
+		t.Fatalf("insert folder failed: %v", err)
+	}
+	sub := &models.UserRepository{Identity: "sub", UserIdentity: "u-2", ParentId: folder.Id, Name: "sub"}
+	if _, err := env.eng.InsertOne(sub); err != nil {
+		t.Fatalf("insert sub failed: %v", err)
+	}
+	file := &models.UserRepository{Identity: "f1", UserIdentity: "u-2", ParentId: sub.Id, Name: "a.txt", RepositoryIdentity: "r1", Ext: ".txt"}
+	if _, err := env.eng.InsertOne(file); err != nil {
+		t.Fatalf("insert file failed: %v", err)
+	}
+	mine := &models.UserRepository{Identity: "mine", UserIdentity: "u-1", ParentId: 0, Name: "docs"}
+	if _, err := env.eng.InsertOne(mine); err != nil {
+		t.Fatalf("insert existing folder failed: %v", err)
+	}
+	share := &models.ShareBasic{Identity: "s1", UserIdentity: "u-2", UserRepositoryIdentity: "dir", Scope: "public"}
+	if _, err := env.eng.InsertOne(share); err != nil {
+		t.Fatalf("insert share failed: %v", err)
+	}
+
+	resp, err := NewSaveShareFolderLogic(env.ctx, env.svc).SaveShareFolder(&types.SaveShareFolderRequest{ShareIdentity: "s1"})
+	if err != nil {
+		t.Fatalf("save folder failed: %v", err)
+	}
+	root := new(models.UserRepository)
+	if has, err := env.eng.Where("identity = ?", resp.Identity).Get(root); err != nil || !has {
+		t.Fatalf("saved root missing: %v", err)
+	}
+	if root.Name != "docs (1)" || root.UserIdentity != "u-1" {
+		t.Fatalf("unexpected saved root: %+v", root)
+	}
+	copied := new(models.UserRepository)
+	has, err := env.eng.Where("user_identity = ? AND repository_identity = ?", "u-1", "r1").Get(copied)
+	if err != nil || !has {
+		t.Fatalf("copied file missing: %v", err)
+	}
+	copiedSub := new(models.UserRepository)
+	if _, err := env.eng.Where("id = ?", copied.ParentId).Get(copiedSub); err != nil || copiedSub.Name != "sub" || copiedSub.ParentId != root.Id {
+		t.Fatalf("copied tree structure mismatch: %+v %v", copiedSub, err)
+	}
+
+	t.Setenv("SAVE_FOLDER_SYNC_LIMIT", "0")
+	resp, err = NewSaveShareFolderLogic(env.ctx, env.svc).SaveShareFolder(&types.SaveShareFolderRequest{ShareIdentity: "s1"})
+	if err != nil {
+		t.Fatalf("save folder job failed: %v", err)
+	}
+	if resp.JobId == "" {
+		t.Fatal("expected background job")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := NewSaveJobLogic(env.ctx, env.svc).SaveJob(&types.SaveJobRequest{JobId: resp.JobId})
+		if err != nil {
+			t.Fatalf("query job failed: %v", err)
+		}
+		if job.Status == "done" {
+			if job.Total != 3 || job.Identity == "" {
+				t.Fatalf("unexpected job result: %+v", job)
+			}
+			break
+		}
+		if job.Status == "failed" || time.Now().After(deadline) {
+			t.Fatalf("job not finished: %+v", job)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	otherCtx := context.WithValue(context.Background(), "user_identity", "u-9")
+	if _, err := NewSaveJobLogic(otherCtx, env.svc).SaveJob(&types.SaveJobRequest{JobId: resp.JobId}); err == nil {
+		t.Fatal("job of another user should not be visible")
+	}
+
+	// 超过最大层级的文件夹拒绝转存，而不是只转存部分子树
+	parentId := int64(0)
+	var deep *models.UserRepository
+	for i := 0; i <= maxShareAncestorDepth; i++ {
+		node := &models.UserRepository{Identity: fmt.Sprintf("deep-%d", i), UserIdentity: "u-2", ParentId: parentId, Name: "d"}
+		if _, err := env.eng.InsertOne(node); err != nil {
+			t.Fatalf("insert deep folder failed: %v", err)
+		}
+		if deep == nil {
+			deep = node
+		}
+		parentId = node.Id
+	}
+	if _, err := collectSubtree(env.eng, deep); err != errSubtreeTooDeep {
+		t.Fatalf("deep subtree should be rejected: %v", err)
+	}
+}
+
+// TestUploadLink 验证匿名上传链接的创建、信息查询与上传前校验。
+func TestUploadLink(t *testing.T) {
+	env := newTestEnv(t)
+	folder := &models.UserRepository{Identity: "inbox", UserIdentity: "u-1", ParentId: 0, Name: "inbox"}
+	if _, err := env.eng.InsertOne(folder); err != nil {
+		t.Fatalf("insert folder failed: %v", err)
+	}
+	if _, err := env.eng.InsertOne(&models.UserBasic{Identity: "u-1", Name: "owner"}); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+
+	created, err := NewCreateUploadLinkLogic(env.ctx, env.svc).CreateUploadLink(&types.CreateUploadLinkRequest{
+		ParentId:    folder.Id,
+		MaxSize:     1024,
+		AllowedExts: []string{"PDF", ".txt"},
+		Password:    "secret",
+	})
+	if err != nil {
+		t.Fatalf("create upload link failed: %v", err)
+	}
+
+	info, err := NewUploadLinkInfoLogic(context.Background(), env.svc).UploadLinkInfo(&types.UploadLinkInfoRequest{Identity: created.Identity})
+	if err != nil {
+		t.Fatalf("upload link info failed: %v", err)
+	}
+	if info.FolderName != "inbox" || info.OwnerName != "owner" || !info.NeedPassword || len(info.AllowedExts) != 2 {
+		t.Fatalf("unexpected link info: %+v", info)
+	}
+
+	l := NewUploadLinkUploadLogic(context.Background(), env.svc)
+	if _, err := l.AuthorizeUploadLink(&types.UploadLinkUploadRequest{Identity: created.Identity, Password: "wrong"}); err == nil {
+		t.Fatal("expected wrong password error")
+	}
+	link, err := l.AuthorizeUploadLink(&types.UploadLinkUploadRequest{Identity: created.Identity, Password: "secret"})
+	if err != nil {
+		t.Fatalf("authorize upload link failed: %v", err)
+	}
+	if err := l.CheckUploadFile(link, ".exe", 10); err == nil {
+		t.Fatal("expected disallowed ext error")
+	}
+	if err := l.CheckUploadFile(link, ".pdf", 2048); err == nil {
+		t.Fatal("expected size limit error")
+	}
+	if err := l.CheckUploadFile(link, ".PDF", 10); err != nil {
+		t.Fatalf("expected upload allowed: %v", err)
+	}
+
+	if _, err := NewUploadLinkDeleteLogic(env.ctx, env.svc).UploadLinkDelete(&types.UploadLinkDeleteRequest{Identity: created.Identity}); err != nil {
+		t.Fatalf("delete upload link failed: %v", err)
+	}
+	if _, err := l.AuthorizeUploadLink(&types.UploadLinkUploadRequest{Identity: created.Identity, Password: "secret"}); err == nil {
+		t.Fatal("expected deleted link rejected")
+	}
+}
+
+// TestShareCode 验证分享短码生成、按短码访问与二维码输出。
+func TestShareCode(t *testing.T) {
+	env := newTestEnv(t)
+	repo := &models.RepositoryPool{Identity: "r1", Name: "file", Ext: ".txt", Size: 12}
+	if _, err := env.eng.InsertOne(repo); err != nil {
+		t.Fatalf("insert repo failed: %v", err)
+	}
+	file := &models.UserRepository{Identity: "f1", UserIdentity: "u-1", ParentId: 0, Name: "file", RepositoryIdentity: "r1", Ext: ".txt"}
+	if _, err := env.eng.InsertOne(file); err != nil {
+		t.Fatalf("insert file failed: %v", err)
+	}
+
+	created, err := NewCreateShareRecordLogic(env.ctx, env.svc).CreateShareRecord(&types.CreateShareRecordRequest{Identity: "r1"})
+	if err != nil {
+		t.Fatalf("create share failed: %v", err)
+	}
+	if len(created.Code) != shareCodeLength || !strings.HasSuffix(created.URL, "/"+created.Code) {
+		t.Fatalf("unexpected share code: %+v", created)
+	}
+
+	got, err := NewGetShareRecordLogic(env.ctx, env.svc).GetShareRecord(&types.GetShareRecordRequest{Identity: created.Code})
+	if err != nil {
+		t.Fatalf("get share by code failed: %v", err)
+	}
+	if got.Name != "file" || got.Size != 12 {
+		t.Fatalf("unexpected share record: %+v", got)
+	}
+
+	png, err := NewShareQRCodeLogic(env.ctx, env.svc).ShareQRCode(&types.ShareQRCodeRequest{Identity: created.Code, Size: 128})
+	if err != nil {
+		t.Fatalf("qr code failed: %v", err)
+	}
+	if len(png) < 8 || string(png[1:4]) != "PNG" {
+		t.Fatal("expected png output")
+	}
+	if _, err := NewShareQRCodeLogic(env.ctx, env.svc).ShareQRCode(&types.ShareQRCodeRequest{Identity: "missing"}); err == nil {
+		t.Fatal("expected missing share error")
+	}
+}
+
+// TestInsertShareWithCodeRetry 验证短码唯一索引冲突时换码重试。
+func TestInsertShareWithCodeRetry(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.InsertOne(&models.ShareBasic{Identity: "s-old", Code: "taken"}); err != nil {
+		t.Fatalf("insert share failed: %v", err)
+	}
+	share := &models.ShareBasic{Identity: "s-new", UserIdentity: "u-1"}
+	attempts := 0
+	err := insertShareWithCode(share, func() error {
+		attempts++
+		if attempts == 1 {
+			share.Code = "taken"
+		}
+		_, err := env.eng.InsertOne(share)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("insert share failed: %v", err)
+	}
+	if attempts != 2 || share.Code == "taken" || len(share.Code) != shareCodeLength {
+		t.Fatalf("expected retry with new code: attempts=%d code=%s", attempts, share.Code)
+	}
+}
+
+// TestRefreshAndLogout 验证刷新令牌轮换与退出登录吊销。
+func TestRefreshAndLogout(t *testing.T) {
+	env := newTestEnv(t)
+	pair, err := issueTokenPair(env.ctx, env.svc, utils.JwtPayLoad{Id: 1, Identity: "u-1", Name: "alice"})
+	if err != nil {
+		t.Fatalf("issue token failed: %v", err)
+	}
+
+	refreshed, err := NewRefreshTokenLogic(env.ctx, env.svc).RefreshToken(&types.RefreshTokenRequest{RefreshToken: pair.RefreshToken})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if refreshed.Token == "" || refreshed.RefreshToken == pair.RefreshToken {
+		t.Fatalf("expected rotated tokens: %+v", refreshed)
+	}
+	if _, err := NewRefreshTokenLogic(env.ctx, env.svc).RefreshToken(&types.RefreshTokenRequest{RefreshToken: pair.RefreshToken}); err == nil {
+		t.Fatal("old refresh token should be rejected")
+	}
+
+	claims, err := utils.ParseToken(refreshed.Token, env.svc.Config.Auth.AccessSecret, 0)
+	if err != nil {
+		t.Fatalf("parse token failed: %v", err)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != time.Duration(env.svc.Config.Auth.AccessExpire)*time.Second || refreshed.ExpiresIn != env.svc.Config.Auth.AccessExpire {
+		t.Fatalf("access token ttl should follow Auth.AccessExpire: %v", ttl)
+	}
+	ctx := context.WithValue(env.ctx, "token_id", claims.ID)
+	ctx = context.WithValue(ctx, "token_expire", claims.ExpiresAt.Time)
+	if _, err := NewLogoutLogic(ctx, env.svc).Logout(&types.LogoutRequest{RefreshToken: refreshed.RefreshToken}); err != nil {
+		t.Fatalf("logout failed: %v", err)
+	}
+	if v, _ := env.rdb.Get(env.ctx, utils.RevokedTokenKey(claims.ID)).Result(); v != "u-1" {
+		t.Fatalf("access token not revoked: %q", v)
+	}
+	if _, err := NewRefreshTokenLogic(env.ctx, env.svc).RefreshToken(&types.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}); err == nil {
+		t.Fatal("refresh token should be revoked after logout")
+	}
+
+	// 禁用账号的刷新令牌不能再换取新令牌
+	if _, err := env.eng.Nullable("email").InsertOne(&models.UserBasic{Identity: "u-2", Name: "bob"}); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	disabled, err := issueTokenPair(env.ctx, env.svc, utils.JwtPayLoad{Id: 2, Identity: "u-2", Name: "bob"})
+	if err != nil {
+		t.Fatalf("issue token failed: %v", err)
+	}
+	if _, err := env.eng.Table("user_basic").Where("identity = ?", "u-2").Update(map[string]any{"disabled": true}); err != nil {
+		t.Fatalf("disable user failed: %v", err)
+	}
+	if _, err := NewRefreshTokenLogic(env.ctx, env.svc).RefreshToken(&types.RefreshTokenRequest{RefreshToken: disabled.RefreshToken}); err != errDisabledAccount {
+		t.Fatalf("disabled account refresh should be rejected: %v", err)
+	}
+}
+
+// TestLoginUpgradesLegacyHash 验证旧 MD5 密码登录后自动升级为 argon2id。
+func TestLoginUpgradesLegacyHash(t *testing.T) {
+	env := newTestEnv(t)
+	user := &models.UserBasic{Identity: "uid-1", Name: "alice", Password: utils.Md5("pass1234")}
+	if _, err := env.eng.InsertOne(user); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "pass1234"}); err != nil {
+		t.Fatalf("legacy login failed: %v", err)
+	}
+	updated := new(models.UserBasic)
+	if _, err := env.eng.Where("identity = ?", "uid-1").Get(updated); err != nil {
+		t.Fatalf("query user failed: %v", err)
+	}
+	if !strings.HasPrefix(updated.Password, "$argon2id$") {
+		t.Fatalf("password not rehashed: %s", updated.Password)
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "pass1234"}); err != nil {
+		t.Fatalf("login after rehash failed: %v", err)
+	}
+}
+
+// TestLoginLockout 验证登录失败递增延迟、账号锁定与验证码解锁。
+func TestLoginLockout(t *testing.T) {
+	env := newTestEnv(t)
+	hashed, err := utils.HashPassword("pass1234")
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	user := &models.UserBasic{Identity: "uid-1", Name: "alice", Password: hashed}
+	if _, err := env.eng.InsertOne(user); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	ctx := context.WithValue(env.ctx, "client_ip", "10.0.0.1")
+	login := func(pass string) error {
+		_, err := NewLoginLogic(ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: pass})
+		return err
+	}
+
+	for i := 0; i < loginDelayAfter; i++ {
+		if err := login("wrong"); err == nil {
+			t.Fatal("expected login failure")
+		}
+	}
+	if err := login("pass1234"); err == nil || !strings.Contains(err.Error(), "秒后再试") {
+		t.Fatalf("expected progressive delay, got %v", err)
+	}
+
+	for i := loginDelayAfter; i < loginLockAfter; i++ {
+		env.rdb.Del(env.ctx, "login_delay:user:uid-1")
+		_ = login("wrong")
+	}
+	if err := login("pass1234"); err == nil || !strings.Contains(err.Error(), "锁定") {
+		t.Fatalf("expected lockout, got %v", err)
+	}
+
+	code, err := env.rdb.Get(env.ctx, "login_unlock:uid-1").Result()
+	if err != nil {
+		t.Fatalf("unlock code missing: %v", err)
+	}
+	if _, err := NewUnlockAccountLogic(env.ctx, env.svc).UnlockAccount(&types.UnlockAccountRequest{Name: "alice", Code: "000000x"}); err == nil {
+		t.Fatal("expected wrong unlock code error")
+	}
+	if _, err := NewUnlockAccountLogic(env.ctx, env.svc).UnlockAccount(&types.UnlockAccountRequest{Name: "alice", Code: code}); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+	if err := login("pass1234"); err != nil {
+		t.Fatalf("login after unlock failed: %v", err)
+	}
+	cnt, err := env.eng.In("event_type", common.EventLoginLocked, common.EventLoginUnlocked).Count(new(models.FileEventLog))
+	if err != nil || cnt != 2 {
+		t.Fatalf("expected lock and unlock audit events, got %d %v", cnt, err)
+	}
+
+	// 不存在的用户名按规范化名称计数，大小写与首尾空白不同的写法共用失败额度
+	for _, name := range []string{"ghost", "Ghost ", " GHOST"} {
+		if _, err := NewLoginLogic(ctx, env.svc).Login(&types.LoginRequest{Name: name, Password: "x"}); err == nil {
+			t.Fatal("expected login failure")
+		}
+	}
+	if _, err := NewLoginLogic(ctx, env.svc).Login(&types.LoginRequest{Name: "gHost", Password: "x"}); err == nil || !strings.Contains(err.Error(), "秒后再试") {
+		t.Fatalf("name variants should share the failure budget, got %v", err)
+	}
+}
+
+// TestUnlockAccountAttemptsCapped 验证解锁验证码错误次数达到上限后作废。
+func TestUnlockAccountAttemptsCapped(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.InsertOne(&models.UserBasic{Identity: "uid-1", Name: "alice"}); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	if err := env.rdb.Set(env.ctx, unlockCodeKey("uid-1"), "123456", time.Minute).Err(); err != nil {
+		t.Fatalf("set unlock code failed: %v", err)
+	}
+	l := NewUnlockAccountLogic(env.ctx, env.svc)
+	for i := 0; i < verificationMaxAttempts; i++ {
+		if _, err := l.UnlockAccount(&types.UnlockAccountRequest{Name: "alice", Code: "000000"}); err == nil {
+			t.Fatal("expected wrong unlock code error")
+		}
+	}
+	if _, err := l.UnlockAccount(&types.UnlockAccountRequest{Name: "alice", Code: "123456"}); err == nil {
+		t.Fatal("unlock code must be burned after too many attempts")
+	}
+	if _, ok := env.rdb.data[unlockCodeKey("uid-1")]; ok {
+		t.Fatal("burned unlock code should be deleted")
+	}
+}
+
+// TestTwoFactorLogin 验证 TOTP 绑定、两步登录、恢复码与角色强制策略。
+func TestTwoFactorLogin(t *testing.T) {
+	env := newTestEnv(t)
+	hashed, err := utils.HashPassword("pass1234")
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	users := []*models.UserBasic{
+		{Identity: "u-1", Name: "alice", Password: hashed, Role: common.RoleAdmin},
+		{Identity: "u-2", Name: "bob", Password: hashed, Role: "user"},
+	}
+	for _, u := range users {
+		if _, err := env.eng.Nullable("email").InsertOne(u); err != nil {
+			t.Fatalf("insert user failed: %v", err)
+		}
+	}
+	currentCode := func(secret string) string {
+		code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+		if err != nil {
+			t.Fatalf("totp failed: %v", err)
+		}
+		return code
+	}
+
+	setup, err := NewTwoFactorSetupLogic(env.ctx, env.svc).TwoFactorSetup(&types.TwoFactorSetupRequest{})
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if !strings.HasPrefix(setup.OtpauthURL, "otpauth://totp/") || !strings.HasPrefix(setup.QrCode, "data:image/png;base64,") {
+		t.Fatalf("unexpected setup response: %+v", setup)
+	}
+	enabled, err := NewTwoFactorEnableLogic(env.ctx, env.svc).TwoFactorEnable(&types.TwoFactorEnableRequest{Code: currentCode(setup.Secret)})
+	if err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if len(enabled.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("unexpected recovery codes: %v", enabled.RecoveryCodes)
+	}
+
+	login, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "pass1234"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if login.Token != "" || !login.TwoFactorRequired || login.ChallengeToken == "" {
+		t.Fatalf("expected two factor challenge: %+v", login)
+	}
+	if _, err := NewLoginTwoFactorLogic(env.ctx, env.svc).LoginTwoFactor(&types.LoginTwoFactorRequest{ChallengeToken: login.ChallengeToken, Code: "000000"}); err == nil {
+		t.Fatal("expected wrong code error")
+	}
+	done, err := NewLoginTwoFactorLogic(env.ctx, env.svc).LoginTwoFactor(&types.LoginTwoFactorRequest{ChallengeToken: login.ChallengeToken, Code: enabled.RecoveryCodes[0]})
+	if err != nil || done.Token == "" {
+		t.Fatalf("recovery code login failed: %+v %v", done, err)
+	}
+	login, _ = NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "pass1234"})
+	if _, err := NewLoginTwoFactorLogic(env.ctx, env.svc).LoginTwoFactor(&types.LoginTwoFactorRequest{ChallengeToken: login.ChallengeToken, Code: enabled.RecoveryCodes[0]}); err == nil {
+		t.Fatal("recovery code should be single use")
+	}
+
+	if _, err := NewTwoFactorPolicyLogic(env.ctx, env.svc).TwoFactorPolicy(&types.TwoFactorPolicyRequest{Role: "user", Required: true}); err != nil {
+		t.Fatalf("set policy failed: %v", err)
+	}
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	if _, err := NewTwoFactorPolicyLogic(bobCtx, env.svc).TwoFactorPolicy(&types.TwoFactorPolicyRequest{Role: "user"}); err == nil {
+		t.Fatal("non-admin should not change policy")
+	}
+	login, err = NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "bob", Password: "pass1234"})
+	if err != nil || !login.TwoFactorSetupRequired {
+		t.Fatalf("expected enforced setup: %+v %v", login, err)
+	}
+	bobSetup, err := NewLoginTwoFactorSetupLogic(env.ctx, env.svc).LoginTwoFactorSetup(&types.LoginTwoFactorSetupRequest{ChallengeToken: login.ChallengeToken})
+	if err != nil {
+		t.Fatalf("login setup failed: %v", err)
+	}
+	done, err = NewLoginTwoFactorLogic(env.ctx, env.svc).LoginTwoFactor(&types.LoginTwoFactorRequest{ChallengeToken: login.ChallengeToken, Code: currentCode(bobSetup.Secret)})
+	if err != nil || done.Token == "" || len(done.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("enforced setup login failed: %+v %v", done, err)
+	}
+	if _, err := NewTwoFactorDisableLogic(bobCtx, env.svc).TwoFactorDisable(&types.TwoFactorDisableRequest{Code: done.RecoveryCodes[0]}); err == nil {
+		t.Fatal("disable should be refused when role requires 2fa")
+	}
+}
+
+// TestTwoFactorFailuresLockAccount 验证第二因素失败跨挑战累计，达到上限后锁定账号。
+func TestTwoFactorFailuresLockAccount(t *testing.T) {
+	env := newTestEnv(t)
+	hashed, err := utils.HashPassword("pass1234")
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	if _, err := env.eng.InsertOne(&models.UserBasic{Identity: "u-1", Name: "alice", Password: hashed}); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	if _, err := env.eng.InsertOne(&models.UserTotp{UserIdentity: "u-1", Secret: "JBSWY3DPEHPK3PXP", Enabled: true}); err != nil {
+		t.Fatalf("insert totp failed: %v", err)
+	}
+
+	for i := 0; i < loginLockAfter; i++ {
+		env.rdb.Del(env.ctx, "login_delay:user:u-1")
+		login, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "pass1234"})
+		if err != nil || login.ChallengeToken == "" {
+			t.Fatalf("expected challenge on attempt %d: %+v %v", i, login, err)
+		}
+		if _, err := NewLoginTwoFactorLogic(env.ctx, env.svc).LoginTwoFactor(&types.LoginTwoFactorRequest{ChallengeToken: login.ChallengeToken, Code: "000000"}); err == nil {
+			t.Fatal("expected wrong code error")
+		}
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "pass1234"}); err == nil || !strings.Contains(err.Error(), "锁定") {
+		t.Fatalf("expected lockout after repeated second factor failures, got %v", err)
+	}
+}
+
+// TestPersonalAccessToken 验证个人访问令牌的创建、列表与吊销。
+func TestPersonalAccessToken(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := NewCreateAccessTokenLogic(env.ctx, env.svc).CreateAccessToken(&types.CreateAccessTokenRequest{Name: "ci", Scopes: []string{"admin"}}); err == nil {
+		t.Fatal("unknown scope should be rejected")
+	}
+	created, err := NewCreateAccessTokenLogic(env.ctx, env.svc).CreateAccessToken(&types.CreateAccessTokenRequest{
+		Name:          "ci",
+		Scopes:        []string{common.ScopeFilesRead, common.ScopeFilesRead},
+		ExpiresInDays: 30,
+	})
+	if err != nil {
+		t.Fatalf("create token failed: %v", err)
+	}
+	if !utils.IsPersonalAccessToken(created.Token) || len(created.Scopes) != 1 || created.ExpireAt == "" {
+		t.Fatalf("unexpected token: %+v", created)
+	}
+	stored := new(models.PersonalAccessToken)
+	if has, err := env.eng.Where("identity = ?", created.Identity).Get(stored); err != nil || !has {
+		t.Fatalf("token not stored: %v", err)
+	}
+	if stored.TokenHash != utils.HashToken(created.Token) || strings.Contains(stored.TokenHash, created.Token) {
+		t.Fatalf("token hash mismatch: %+v", stored)
+	}
+
+	list, err := NewAccessTokenListLogic(env.ctx, env.svc).AccessTokenList(&types.AccessTokenListRequest{})
+	if err != nil || len(list.List) != 1 || list.List[0].Prefix != stored.Prefix {
+		t.Fatalf("list tokens failed: %v %+v", err, list)
+	}
+
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	if _, err := NewAccessTokenDeleteLogic(bobCtx, env.svc).AccessTokenDelete(&types.AccessTokenDeleteRequest{Identity: created.Identity}); err == nil {
+		t.Fatal("other user should not revoke token")
+	}
+	if _, err := NewAccessTokenDeleteLogic(env.ctx, env.svc).AccessTokenDelete(&types.AccessTokenDeleteRequest{Identity: created.Identity}); err != nil {
+		t.Fatalf("revoke token failed: %v", err)
+	}
+	list, err = NewAccessTokenListLogic(env.ctx, env.svc).AccessTokenList(&types.AccessTokenListRequest{})
+	if err != nil || len(list.List) != 0 {
+		t.Fatalf("revoked token still listed: %v %+v", err, list)
+	}
+
+	// 个人访问令牌不能创建令牌、修改账号或调用管理接口
+	patCtx := context.WithValue(env.ctx, "token_scopes", []string{common.ScopeFilesWrite})
+	if _, err := NewCreateAccessTokenLogic(patCtx, env.svc).CreateAccessToken(&types.CreateAccessTokenRequest{Name: "ci", Scopes: []string{common.ScopeFilesRead}}); !errors.Is(err, errPersonalAccessToken) {
+		t.Fatalf("personal access token must not create tokens, got %v", err)
+	}
+	if _, err := NewChangePasswordLogic(patCtx, env.svc).ChangePassword(&types.ChangePasswordRequest{OldPassword: "a", NewPassword: "b"}); !errors.Is(err, errPersonalAccessToken) {
+		t.Fatalf("personal access token must not change password, got %v", err)
+	}
+	if _, err := NewAdminUserListLogic(patCtx, env.svc).AdminUserList(&types.AdminUserListRequest{}); !errors.Is(err, errPersonalAccessToken) {
+		t.Fatalf("personal access token must not call admin logic, got %v", err)
+	}
+}
+
+// TestUserSessions 验证登录会话记录、列表、刷新续期与注销。
+func TestUserSessions(t *testing.T) {
+	env := newTestEnv(t)
+	phoneCtx := context.WithValue(env.ctx, "client_ip", "10.0.0.1")
+	phoneCtx = context.WithValue(phoneCtx, "user_agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Safari/604.1")
+	phone, err := issueTokenPair(phoneCtx, env.svc, utils.JwtPayLoad{Id: 1, Identity: "u-1", Name: "alice"})
+	if err != nil {
+		t.Fatalf("issue token failed: %v", err)
+	}
+	laptop, err := issueTokenPair(env.ctx, env.svc, utils.JwtPayLoad{Id: 1, Identity: "u-1", Name: "alice"})
+	if err != nil {
+		t.Fatalf("issue token failed: %v", err)
+	}
+	claims, err := utils.ParseToken(laptop.AccessToken, env.svc.Config.Auth.AccessSecret, 0)
+	if err != nil || claims.SessionId == "" {
+		t.Fatalf("session claim missing: %v", err)
+	}
+	ctx := context.WithValue(env.ctx, "session_id", claims.SessionId)
+
+	list, err := NewSessionListLogic(ctx, env.svc).SessionList(&types.SessionListRequest{})
+	if err != nil || len(list.List) != 2 {
+		t.Fatalf("list sessions failed: %v %+v", err, list)
+	}
+	var phoneSid string
+	for _, s := range list.List {
+		if s.Ip == "10.0.0.1" {
+			phoneSid = s.Identity
+			if s.Device != "Safari on iOS" || s.Current {
+				t.Fatalf("unexpected phone session: %+v", s)
+			}
+		} else if !s.Current {
+			t.Fatalf("current session not marked: %+v", s)
+		}
+	}
+
+	// 刷新令牌沿用原会话
+	refreshed, err := NewRefreshTokenLogic(env.ctx, env.svc).RefreshToken(&types.RefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if c, err := utils.ParseToken(refreshed.Token, env.svc.Config.Auth.AccessSecret, 0); err != nil || c.SessionId != phoneSid {
+		t.Fatalf("refreshed token should keep session: %v", err)
+	}
+
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	if _, err := NewSessionRevokeLogic(bobCtx, env.svc).SessionRevoke(&types.SessionRevokeRequest{Identity: phoneSid}); err == nil {
+		t.Fatal("other user should not revoke session")
+	}
+	others, err := NewSessionRevokeOthersLogic(ctx, env.svc).SessionRevokeOthers(&types.SessionRevokeOthersRequest{})
+	if err != nil || others.Count != 1 {
+		t.Fatalf("revoke others failed: %v %+v", err, others)
+	}
+	if _, err := env.rdb.Get(env.ctx, utils.SessionKey(phoneSid)).Result(); err == nil {
+		t.Fatal("revoked session key should be removed")
+	}
+	if _, err := NewRefreshTokenLogic(env.ctx, env.svc).RefreshToken(&types.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}); err == nil {
+		t.Fatal("refresh on revoked session should fail")
+	}
+	if v, _ := env.rdb.Get(env.ctx, utils.SessionKey(claims.SessionId)).Result(); v != "u-1" {
+		t.Fatalf("current session should stay active: %q", v)
+	}
+}
+
+// mockOIDCProvider 本地模拟的 OIDC 身份提供方。
+type mockOIDCProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+}
+
+// newMockOIDCProvider 启动模拟身份提供方，提供发现文档、JWKS 与令牌端点。
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa key failed: %v", err)
+	}
+	p := &mockOIDCProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || utils.PKCEChallenge(r.FormValue("code_verifier")) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":                p.server.URL,
+			"aud":                "cloud-disk",
+			"sub":                p.subject,
+			"exp":                time.Now().Add(time.Minute).Unix(),
+			"nonce":              p.nonce,
+			"email":              p.email,
+			"email_verified":     true,
+			"preferred_username": "carol",
+		})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// login 走完授权与回调流程。
+func (p *mockOIDCProvider) login(t *testing.T, env *testEnv) (*types.OIDCCallbackResponse, error) {
+	auth, err := NewOIDCAuthorizeLogic(env.ctx, env.svc).OIDCAuthorize(&types.OIDCAuthorizeRequest{})
+	if err != nil {
+		t.Fatalf("authorize failed: %v", err)
+	}
+	u, err := url.Parse(auth.URL)
+	if err != nil {
+		t.Fatalf("parse authorize url failed: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("state") != auth.State {
+		t.Fatalf("unexpected authorize url: %s", auth.URL)
+	}
+	p.challenge, p.nonce = q.Get("code_challenge"), q.Get("nonce")
+	ctx := context.WithValue(env.ctx, "oidc_state", auth.State)
+	return NewOIDCCallbackLogic(ctx, env.svc).OIDCCallback(&types.OIDCCallbackRequest{Code: "good-code", State: auth.State})
+}
+
+// TestOIDCLogin 验证单点登录授权码 + PKCE 流程、首次登录开通账号、按邮箱关联已有账号的限制及两步验证。
+func TestOIDCLogin(t *testing.T) {
+	env := newTestEnv(t)
+	idp := newMockOIDCProvider(t)
+	env.svc.Config.OIDC.Issuer = idp.server.URL
+	env.svc.Config.OIDC.ClientId = "cloud-disk"
+	env.svc.Config.OIDC.RedirectURL = "http://localhost/callback"
+
+	idp.subject, idp.email = "sub-1", "carol@example.com"
+	first, err := idp.login(t, env)
+	if err != nil {
+		t.Fatalf("oidc login failed: %v", err)
+	}
+	if first.Token == "" || first.Name != "carol" {
+		t.Fatalf("unexpected login response: %+v", first)
+	}
+	again, err := idp.login(t, env)
+	if err != nil || again.Name != "carol" {
+		t.Fatalf("second login should map to same user: %v %+v", err, again)
+	}
+	if cnt, _ := env.eng.Where("email = ?", "carol@example.com").Count(new(models.UserBasic)); cnt != 1 {
+		t.Fatalf("user provisioned twice: %d", cnt)
+	}
+
+	// 已有本地账号默认不按邮箱关联，显式开启后才关联已验证邮箱
+	if _, err := env.eng.InsertOne(&models.UserBasic{Identity: "u-dave", Name: "dave", Email: "dave@example.com"}); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	idp.subject, idp.email = "sub-2", "dave@example.com"
+	if _, err := idp.login(t, env); err == nil {
+		t.Fatal("email linking should be disabled by default")
+	}
+	env.svc.Config.OIDC.LinkVerifiedEmail = true
+	linked, err := idp.login(t, env)
+	if err != nil || linked.Name != "dave" {
+		t.Fatalf("existing user should be linked: %v %+v", err, linked)
+	}
+
+	// 管理员及拥有管理权限的角色不自动关联
+	if _, err := env.eng.Insert(
+		&models.UserBasic{Identity: "u-root", Name: "root", Email: "root@example.com", Role: common.RoleAdmin},
+		&models.UserBasic{Identity: "u-ops", Name: "ops", Email: "ops@example.com", Role: "ops"},
+		&models.RolePermission{Role: "ops", Permission: common.PermAuditRead},
+	); err != nil {
+		t.Fatalf("insert privileged users failed: %v", err)
+	}
+	for i, email := range []string{"root@example.com", "ops@example.com"} {
+		idp.subject, idp.email = fmt.Sprintf("sub-priv-%d", i), email
+		if _, err := idp.login(t, env); err == nil {
+			t.Fatalf("privileged account %s should not be linked", email)
+		}
+	}
+	if cnt, _ := env.eng.Where("user_identity IN (?, ?)", "u-root", "u-ops").Count(new(models.UserExternalIdentity)); cnt != 0 {
+		t.Fatalf("privileged accounts linked: %d", cnt)
+	}
+
+	// 角色强制两步验证时，单点登录同样只返回登录挑战
+	if _, err := env.eng.Insert(&models.RolePolicy{Role: "staff", RequireTwoFactor: true}); err != nil {
+		t.Fatalf("insert policy failed: %v", err)
+	}
+	if _, err := env.eng.Where("identity = ?", "u-dave").Cols("role").Update(&models.UserBasic{Role: "staff"}); err != nil {
+		t.Fatalf("update role failed: %v", err)
+	}
+	idp.subject, idp.email = "sub-2", "dave@example.com"
+	challenged, err := idp.login(t, env)
+	if err != nil || challenged.Token != "" || !challenged.TwoFactorRequired || !challenged.TwoFactorSetupRequired || challenged.ChallengeToken == "" {
+		t.Fatalf("oidc login should require two-factor: %v %+v", err, challenged)
+	}
+
+	// state 须与浏览器 Cookie 一致、只能使用一次，且拒绝错误的授权码
+	auth, _ := NewOIDCAuthorizeLogic(env.ctx, env.svc).OIDCAuthorize(&types.OIDCAuthorizeRequest{})
+	for _, bound := range []string{"", "other-state"} {
+		ctx := context.WithValue(env.ctx, "oidc_state", bound)
+		if _, err := NewOIDCCallbackLogic(ctx, env.svc).OIDCCallback(&types.OIDCCallbackRequest{Code: "good-code", State: auth.State}); err == nil {
+			t.Fatalf("state not bound to this browser should be rejected: %q", bound)
+		}
+	}
+	stateCtx := context.WithValue(env.ctx, "oidc_state", auth.State)
+	if _, err := NewOIDCCallbackLogic(stateCtx, env.svc).OIDCCallback(&types.OIDCCallbackRequest{Code: "bad-code", State: auth.State}); err == nil {
+		t.Fatal("bad code should be rejected")
+	}
+	if _, err := NewOIDCCallbackLogic(stateCtx, env.svc).OIDCCallback(&types.OIDCCallbackRequest{Code: "good-code", State: auth.State}); err == nil {
+		t.Fatal("state should not be reusable")
+	}
+
+	env.svc.Config.OIDC.AllowedDomains = []string{"corp.example.com"}
+	idp.subject, idp.email = "sub-3", "eve@example.com"
+	if _, err := idp.login(t, env); err == nil {
+		t.Fatal("domain outside allow list should be rejected")
+	}
+}
+
+// ldapTestEntry 模拟目录中的用户条目。
+type ldapTestEntry struct {
+	dn       string
+	password string
+	mail     string
+	groups   []string
+}
+
+// startLDAPTestServer 启动进程内的最小 LDAP 服务，支持简单绑定与按等值过滤查找用户。
+func startLDAPTestServer(t *testing.T, serviceDN, servicePassword string, users map[string]*ldapTestEntry) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ldap listen failed: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveLDAPTestConn(conn, serviceDN, servicePassword, users)
+		}
+	}()
+	return "ldap://" + ln.Addr().String()
+}
+
+// serveLDAPTestConn 处理单个 LDAP 连接上的绑定与查找请求。
+func serveLDAPTestConn(conn net.Conn, serviceDN, servicePassword string, users map[string]*ldapTestEntry) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, pw := op.Children[1].Data.String(), op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if dn == serviceDN && pw == servicePassword {
+				code = ldap.LDAPResultSuccess
+			}
+			for _, u := range users {
+				if u.dn == dn && u.password == pw {
+					code = ldap.LDAPResultSuccess
+				}
+			}
+			writeLDAPTestMessage(conn, id, ldapTestResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			// 仅支持形如 (attr=value) 的等值过滤
+			if u, ok := users[op.Children[6].Children[1].Data.String()]; ok {
+				entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "entry")
+				entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, u.dn, "dn"))
+				attrs := ber.NewSequence("attributes")
+				attrs.AppendChild(ldapTestAttribute("mail", u.mail))
+				attrs.AppendChild(ldapTestAttribute("memberOf", u.groups...))
+				entry.AppendChild(attrs)
+				writeLDAPTestMessage(conn, id, entry)
+			}
+			writeLDAPTestMessage(conn, id, ldapTestResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		default:
+			return
+		}
+	}
+}
+
+// ldapTestResult 构造 LDAPResult 响应。
+func ldapTestResult(tag ber.Tag, code uint16) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "result")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "code"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "message"))
+	return p
+}
+
+// ldapTestAttribute 构造条目属性。
+func ldapTestAttribute(name string, values ...string) *ber.Packet {
+	attr := ber.NewSequence("attribute")
+	attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+	set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+	for _, v := range values {
+		set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+	}
+	attr.AppendChild(set)
+	return attr
+}
+
+// writeLDAPTestMessage 写出一条 LDAP 消息。
+func writeLDAPTestMessage(conn net.Conn, id int64, op *ber.Packet) {
+	msg := ber.NewSequence("message")
+	msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "id"))
+	msg.AppendChild(op)
+	_, _ = conn.Write(msg.Bytes())
+}
+
+// TestLDAPLogin 验证 LDAP 绑定认证、首次登录开通账号、组到角色的映射（未配置时保留本地角色）以及本地账号不受影响。
+func TestLDAPLogin(t *testing.T) {
+	env := newTestEnv(t)
+	users := map[string]*ldapTestEntry{
+		"alice": {
+			dn:       "cn=alice,ou=people,dc=example,dc=com",
+			password: "Alice#2024",
+			mail:     "Alice@Example.com",
+			groups:   []string{"cn=admins,ou=groups,dc=example,dc=com"},
+		},
+	}
+	c := &env.svc.Config.LDAP
+	c.URL = startLDAPTestServer(t, "cn=svc,dc=example,dc=com", "svc#pw", users)
+	c.BindDN, c.BindPassword = "cn=svc,dc=example,dc=com", "svc#pw"
+	c.BaseDN = "dc=example,dc=com"
+	c.GroupRoles = []config.GroupRole{{Group: "CN=Admins,OU=Groups,DC=example,DC=com", Role: common.RoleAdmin}}
+
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "wrong#pw"}); err == nil {
+		t.Fatal("wrong ldap password should be rejected")
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: ""}); err == nil {
+		t.Fatal("empty password must not bind anonymously")
+	}
+	resp, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "Alice#2024"})
+	if err != nil || resp.Token == "" {
+		t.Fatalf("ldap login failed: %v %+v", err, resp)
+	}
+	user := new(models.UserBasic)
+	if has, err := env.eng.Where("name = ?", "alice").Get(user); err != nil || !has {
+		t.Fatalf("ldap user not provisioned: %v", err)
+	}
+	if user.Role != common.RoleAdmin || user.Email != "alice@example.com" {
+		t.Fatalf("unexpected provisioned user: %+v", user)
+	}
+
+	// 组成员变化在下次登录时同步
+	users["alice"].groups = nil
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "Alice#2024"}); err != nil {
+		t.Fatalf("second ldap login failed: %v", err)
+	}
+	if has, _ := env.eng.Where("name = ? AND role = ?", "alice", common.RoleAdmin).Exist(new(models.UserBasic)); has {
+		t.Fatal("role should be revoked after group removal")
+	}
+	if cnt, _ := env.eng.Where("name = ?", "alice").Count(new(models.UserBasic)); cnt != 1 {
+		t.Fatalf("ldap user provisioned twice: %d", cnt)
+	}
+
+	// 配置组映射时角色由目录管理，管理员不能在本地修改
+	if _, err := env.eng.Nullable("email").InsertOne(&models.UserBasic{Identity: "u-1", Name: "root", Role: common.RoleAdmin}); err != nil {
+		t.Fatalf("insert admin failed: %v", err)
+	}
+	if _, err := NewAdminUserRoleLogic(env.ctx, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: user.Identity, Role: "staff"}); err == nil {
+		t.Fatal("directory-managed role should not be editable")
+	}
+
+	// 未配置组映射时保留本地设置的角色
+	c.GroupRoles = nil
+	if _, err := NewAdminUserRoleLogic(env.ctx, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: user.Identity, Role: "staff"}); err != nil {
+		t.Fatalf("set local role failed: %v", err)
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "alice", Password: "Alice#2024"}); err != nil {
+		t.Fatalf("third ldap login failed: %v", err)
+	}
+	if has, _ := env.eng.Where("name = ? AND role = ?", "alice", "staff").Exist(new(models.UserBasic)); !has {
+		t.Fatal("local role should survive login without group mapping")
+	}
+
+	// 未绑定目录的本地账号仍使用本地密码
+	hashed, _ := utils.HashPassword("Local#2024")
+	if _, err := env.eng.Nullable("email").InsertOne(&models.UserBasic{Identity: "u-local", Name: "local", Password: hashed}); err != nil {
+		t.Fatalf("insert local user failed: %v", err)
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "local", Password: "Local#2024"}); err != nil {
+		t.Fatalf("local login failed with ldap enabled: %v", err)
+	}
+}
+
+// TestVerificationCodeHardening 验证验证码发送冷却、用途隔离、校验次数上限、一次性使用与发送失败上报。
+func TestVerificationCodeHardening(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.WithValue(env.ctx, "client_ip", "10.0.0.9")
+	send := func(email, purpose string) error {
+		_, err := NewSendVerificationCodeLogic(ctx, env.svc).SendVerificationCode(&types.SendVerificationCodeRequest{Email: email, Purpose: purpose})
+		return err
+	}
+	if err := send("bob@example.com", "login"); err == nil {
+		t.Fatal("unknown purpose should be rejected")
+	}
+	if err := send("frank@example.com", ""); err != nil {
+		t.Fatalf("empty purpose should default to register: %v", err)
+	}
+	if _, err := env.rdb.Get(env.ctx, "verification_code:register:frank@example.com").Result(); err != nil {
+		t.Fatal("default purpose code not stored as register")
+	}
+	if err := send("Bob@Example.com", common.CodePurposeRegister); err != nil {
+		t.Fatalf("send code failed: %v", err)
+	}
+	if err := send("bob@example.com", common.CodePurposeRegister); err == nil || !strings.Contains(err.Error(), "频繁") {
+		t.Fatalf("cooldown not enforced: %v", err)
+	}
+	code, err := env.rdb.Get(env.ctx, "verification_code:register:bob@example.com").Result()
+	if err != nil {
+		t.Fatalf("code missing: %v", err)
+	}
+
+	// 注册验证码不能用于重置密码
+	if err := consumeVerificationCode(env.ctx, env.svc, common.CodePurposeReset, "bob@example.com", code); err == nil {
+		t.Fatal("code must be bound to its purpose")
+	}
+	if err := consumeVerificationCode(env.ctx, env.svc, common.CodePurposeRegister, "bob@example.com", code); err != nil {
+		t.Fatalf("consume code failed: %v", err)
+	}
+	if err := consumeVerificationCode(env.ctx, env.svc, common.CodePurposeRegister, "bob@example.com", code); err == nil {
+		t.Fatal("code should be burned after use")
+	}
+
+	// 错误次数达到上限后验证码作废
+	code, err = issueVerificationCode(env.ctx, env.svc, common.CodePurposeRegister, "carol@example.com")
+	if err != nil {
+		t.Fatalf("issue code failed: %v", err)
+	}
+	for i := 0; i < verificationMaxAttempts; i++ {
+		if err := consumeVerificationCode(env.ctx, env.svc, common.CodePurposeRegister, "carol@example.com", "000000x"); err == nil {
+			t.Fatal("wrong code accepted")
+		}
+	}
+	if err := consumeVerificationCode(env.ctx, env.svc, common.CodePurposeRegister, "carol@example.com", code); err == nil {
+		t.Fatal("code should be invalidated after too many attempts")
+	}
+
+	// 同一 IP 的发送次数受限
+	for i := 0; i < verificationIPLimit; i++ {
+		_ = windowAdd(env.ctx, env.rdb, "verification_send:ip:10.0.0.9", verificationSendWindow)
+	}
+	if err := send("dave@example.com", common.CodePurposeRegister); err == nil {
+		t.Fatal("ip limit not enforced")
+	}
+
+	// 发送失败需返回错误，并解除冷却以便重试
+	oldSender := utils.EmailSender()
+	utils.SetEmailConfig(true, "smtp.example.com", "465", "noreply@example.com", "pw")
+	utils.SetEmailSender(func(*email.Email, string, string, string, string) error { return errors.New("smtp down") })
+	t.Cleanup(func() { utils.SetEmailSender(oldSender) })
+	if _, err := NewSendVerificationCodeLogic(env.ctx, env.svc).SendVerificationCode(&types.SendVerificationCodeRequest{Email: "erin@example.com", Purpose: common.CodePurposeRegister}); err == nil {
+		t.Fatal("send failure should be surfaced")
+	}
+	if _, err := env.rdb.Get(env.ctx, "verification_cooldown:erin@example.com").Result(); err == nil {
+		t.Fatal("cooldown should be released after send failure")
+	}
+}
+
+// TestEnsureSchemaDuplicateEmails 验证已有重复邮箱时建立唯一索引前明确报错并列出冲突邮箱，空字符串邮箱迁移为 NULL。
+func TestEnsureSchemaDuplicateEmails(t *testing.T) {
+	eng, err := xorm.NewEngine("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("engine init failed: %v", err)
+	}
+	eng.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = eng.Close() })
+	for _, q := range []string{
+		"CREATE TABLE user_basic (id INTEGER PRIMARY KEY AUTOINCREMENT, identity VARCHAR(36), name VARCHAR(255), email VARCHAR(255))",
+		"INSERT INTO user_basic (identity, name, email) VALUES ('u-1', 'a', 'dup@example.com'), ('u-2', 'b', 'Dup@Example.com '), ('u-3', 'c', ''), ('u-4', 'd', '')",
+	} {
+		if _, err := eng.Exec(q); err != nil {
+			t.Fatalf("prepare legacy table failed: %v", err)
+		}
+	}
+	err = utils.EnsureSchema(eng)
+	if err == nil || !strings.Contains(err.Error(), "dup@example.com (2)") {
+		t.Fatalf("expected duplicate email error, got %v", err)
+	}
+	var cnt int64
+	if _, err := eng.SQL("SELECT COUNT(*) FROM user_basic WHERE email IS NULL").Get(&cnt); err != nil || cnt != 2 {
+		t.Fatalf("empty emails should become NULL: %d %v", cnt, err)
+	}
+
+	if _, err := eng.Exec("UPDATE user_basic SET email = 'other@example.com' WHERE identity = 'u-2'"); err != nil {
+		t.Fatalf("resolve duplicate failed: %v", err)
+	}
+	if err := utils.EnsureSchema(eng); err != nil {
+		t.Fatalf("ensure schema after resolving duplicates failed: %v", err)
+	}
+}
+
+// TestChangeEmail 验证更换邮箱需新旧邮箱双重验证、邮箱唯一性校验与旧邮箱通知。
+func TestChangeEmail(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.Insert(
+		&models.UserBasic{Identity: "u-1", Name: "alice", Email: "alice@example.com"},
+		&models.UserBasic{Identity: "u-2", Name: "bob", Email: "bob@example.com"},
+	); err != nil {
+		t.Fatalf("insert users failed: %v", err)
+	}
+	oldCode, _ := issueVerificationCode(env.ctx, env.svc, common.CodePurposeChangeEmail, "alice@example.com")
+	newCode, _ := issueVerificationCode(env.ctx, env.svc, common.CodePurposeChangeEmail, "alice@new.example.com")
+	registerCode, _ := issueVerificationCode(env.ctx, env.svc, common.CodePurposeRegister, "alice@new.example.com")
+
+	change := func(newEmail, oldCode, newCode string) (*types.ChangeEmailResponse, error) {
+		return NewChangeEmailLogic(env.ctx, env.svc).ChangeEmail(&types.ChangeEmailRequest{NewEmail: newEmail, OldCode: oldCode, NewCode: newCode})
+	}
+	if _, err := change("bob@example.com", oldCode, newCode); err == nil {
+		t.Fatal("email in use should be rejected")
+	}
+	if _, err := change("alice@new.example.com", "", newCode); err == nil {
+		t.Fatal("old address must be verified")
+	}
+	if _, err := change("alice@new.example.com", oldCode, registerCode); err == nil {
+		t.Fatal("register code must not be accepted for email change")
+	}
+
+	sent := make(chan string, 1)
+	oldSender := utils.EmailSender()
+	utils.SetEmailConfig(true, "smtp.example.com", "465", "noreply@example.com", "pw")
+	utils.SetEmailSender(func(e *email.Email, _, _, _, _ string) error {
+		sent <- e.To[0]
+		return nil
+	})
+	t.Cleanup(func() { utils.SetEmailSender(oldSender) })
+
+	// 新邮箱验证码错误时旧邮箱验证码不被消耗，可直接重试
+	resp, err := change("Alice@New.Example.com", oldCode, newCode)
+	if err != nil {
+		t.Fatalf("change email failed: %v", err)
+	}
+	if resp.Email != "alice@new.example.com" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if has, _ := env.eng.Where("identity = ? AND email = ?", "u-1", "alice@new.example.com").Exist(new(models.UserBasic)); !has {
+		t.Fatal("email not updated")
+	}
+	select {
+	case to := <-sent:
+		if to != "alice@example.com" {
+			t.Fatalf("notification sent to %s", to)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("old address not notified")
+	}
+	if has, _ := env.eng.Where("user_identity = ? AND event_type = ?", "u-1", common.EventEmailChanged).Exist(new(models.FileEventLog)); !has {
+		t.Fatal("email change not audited")
+	}
+
+	// 并发更换到同一邮箱时由唯一索引兜底
+	if err := NewChangeEmailLogic(env.ctx, env.svc).updateEmail("u-2", "alice@new.example.com"); !errors.Is(err, errEmailTaken) {
+		t.Fatalf("duplicate email should map to errEmailTaken: %v", err)
+	}
+}
+
+// TestAdminUserManagement 验证基于角色权限的用户管理：权限授予、禁用后无法登录、越权拦截与审计记录。
+func TestAdminUserManagement(t *testing.T) {
+	env := newTestEnv(t)
+	hashed, _ := utils.HashPassword("Passw0rd!")
+	if _, err := env.eng.Insert(
+		&models.UserBasic{Identity: "u-1", Name: "root", Email: "root@example.com", Role: common.RoleAdmin},
+		&models.UserBasic{Identity: "u-2", Name: "bob", Email: "bob@example.com", Password: hashed},
+		&models.UserBasic{Identity: "u-3", Name: "carol", Email: "carol@example.com"},
+	); err != nil {
+		t.Fatalf("insert users failed: %v", err)
+	}
+	asUser := func(identity string) context.Context {
+		return context.WithValue(context.Background(), "user_identity", identity)
+	}
+
+	if _, err := NewAdminUserListLogic(asUser("u-2"), env.svc).AdminUserList(&types.AdminUserListRequest{}); err == nil {
+		t.Fatal("plain user must not list users")
+	}
+	if _, err := NewAdminRolePermissionsLogic(env.ctx, env.svc).AdminRolePermissions(&types.AdminRolePermissionsRequest{
+		Role: "support", Permissions: []string{common.PermUsersRead, common.PermUsersWrite, common.PermUsersRead},
+	}); err != nil {
+		t.Fatalf("set role permissions failed: %v", err)
+	}
+	if _, err := NewAdminUserRoleLogic(env.ctx, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: "u-3", Role: "support"}); err != nil {
+		t.Fatalf("change role failed: %v", err)
+	}
+	support := asUser("u-3")
+	list, err := NewAdminUserListLogic(support, env.svc).AdminUserList(&types.AdminUserListRequest{Keyword: "bob@"})
+	if err != nil {
+		t.Fatalf("list users failed: %v", err)
+	}
+	if list.Count != 1 || len(list.List) != 1 || list.List[0].Identity != "u-2" {
+		t.Fatalf("unexpected search result: %+v", list)
+	}
+
+	// 支持角色不能管理管理员、授予 admin 角色或调整角色权限
+	if _, err := NewAdminUserDisableLogic(support, env.svc).AdminUserDisable(&types.AdminUserStatusRequest{Identity: "u-1"}); err == nil {
+		t.Fatal("support must not disable admin")
+	}
+	if _, err := NewAdminUserRoleLogic(support, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: "u-2", Role: common.RoleAdmin}); err == nil {
+		t.Fatal("support must not grant admin role")
+	}
+	if _, err := NewAdminRolePermissionsLogic(support, env.svc).AdminRolePermissions(&types.AdminRolePermissionsRequest{Role: "support", Permissions: common.Permissions}); err == nil {
+		t.Fatal("support must not manage role permissions")
+	}
+
+	sid, err := createSession(env.ctx, env.svc, "u-2")
+	if err != nil {
+		t.Fatalf("create session failed: %v", err)
+	}
+	if _, err := NewAdminUserDisableLogic(support, env.svc).AdminUserDisable(&types.AdminUserStatusRequest{Identity: "u-2"}); err != nil {
+		t.Fatalf("disable user failed: %v", err)
+	}
+	if _, ok := env.rdb.data[utils.SessionKey(sid)]; ok {
+		t.Fatal("sessions of disabled user must be revoked")
+	}
+	if _, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "bob", Password: "Passw0rd!"}); !errors.Is(err, errDisabledAccount) {
+		t.Fatalf("disabled user login should fail, got %v", err)
+	}
+	if _, err := issueTokenPair(env.ctx, env.svc, utils.JwtPayLoad{Identity: "u-2", Name: "bob"}); !errors.Is(err, errDisabledAccount) {
+		t.Fatalf("disabled user must not get tokens, got %v", err)
+	}
+
+	reset, err := NewAdminPasswordResetLogic(env.ctx, env.svc).AdminPasswordReset(&types.AdminPasswordResetRequest{Identity: "u-2"})
+	if err != nil {
+		t.Fatalf("reset password failed: %v", err)
+	}
+	if _, err := NewAdminUserEnableLogic(env.ctx, env.svc).AdminUserEnable(&types.AdminUserStatusRequest{Identity: "u-2"}); err != nil {
+		t.Fatalf("enable user failed: %v", err)
+	}
+	resp, err := NewLoginLogic(env.ctx, env.svc).Login(&types.LoginRequest{Name: "bob", Password: reset.Password})
+	if err != nil || resp.Token == "" {
+		t.Fatalf("login with temporary password failed: %v", err)
+	}
+
+	roles, err := NewAdminRoleListLogic(env.ctx, env.svc).AdminRoleList(&types.AdminRoleListRequest{})
+	if err != nil {
+		t.Fatalf("list roles failed: %v", err)
+	}
+	if len(roles.List) != 2 || roles.List[1].Role != "support" || len(roles.List[1].Permissions) != 2 || roles.List[1].UserCount != 1 {
+		t.Fatalf("unexpected roles: %+v", roles.List)
+	}
+	for _, event := range []string{common.EventAdminRolePermissions, common.EventAdminRoleChanged, common.EventAdminUserDisabled,
+		common.EventAdminPasswordReset, common.EventAdminUserEnabled} {
+		if has, _ := env.eng.Where("event_type = ? AND actor_identity <> ''", event).Exist(new(models.FileEventLog)); !has {
+			t.Fatalf("admin action %s not audited", event)
+		}
+	}
+
+	// 只能授予权限不超过自己的角色
+	if _, err := NewAdminRolePermissionsLogic(env.ctx, env.svc).AdminRolePermissions(&types.AdminRolePermissionsRequest{
+		Role: "auditor", Permissions: []string{common.PermAuditRead},
+	}); err != nil {
+		t.Fatalf("set auditor permissions failed: %v", err)
+	}
+	if _, err := NewAdminUserRoleLogic(support, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: "u-2", Role: "auditor"}); err == nil {
+		t.Fatal("support must not grant a role with permissions it lacks")
+	}
+	if _, err := NewAdminUserRoleLogic(support, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: "u-2", Role: "helpdesk"}); err != nil {
+		t.Fatalf("support should grant a role without extra permissions: %v", err)
+	}
+
+	// 不能重置密码、禁用或启用角色权限超出自己的用户
+	if _, err := NewAdminUserRoleLogic(env.ctx, env.svc).AdminUserRole(&types.AdminUserRoleRequest{Identity: "u-2", Role: "auditor"}); err != nil {
+		t.Fatalf("admin change role failed: %v", err)
+	}
+	if _, err := NewAdminPasswordResetLogic(support, env.svc).AdminPasswordReset(&types.AdminPasswordResetRequest{Identity: "u-2"}); err == nil {
+		t.Fatal("support must not reset the password of a more privileged user")
+	}
+	if _, err := NewAdminUserDisableLogic(support, env.svc).AdminUserDisable(&types.AdminUserStatusRequest{Identity: "u-2"}); err == nil {
+		t.Fatal("support must not disable a more privileged user")
+	}
+}
+
+// TestStorageQuota 验证配额在转存时生效，删除、恢复与清理时同步调整用量，管理员可调整配额。
+func TestStorageQuota(t *testing.T) {
+	env := newTestEnv(t)
+	t.Setenv("DEFAULT_QUOTA_BYTES", "1000")
+	if _, err := env.eng.Insert(
+		&models.UserBasic{Identity: "u-1", Name: "alice", Email: "alice@example.com"},
+		&models.UserBasic{Identity: "u-9", Name: "root", Email: "root@example.com", Role: common.RoleAdmin},
+		&models.RepositoryPool{Identity: "r-1", Hash: "h1", Size: 400},
+		&models.RepositoryPool{Identity: "r-2", Hash: "h2", Size: 700},
+	); err != nil {
+		t.Fatalf("insert fixtures failed: %v", err)
+	}
+	folder := &models.UserRepository{Identity: "dir", UserIdentity: "u-1", Name: "docs", Status: common.StatusActive}
+	if _, err := env.eng.InsertOne(folder); err != nil {
+		t.Fatalf("insert folder failed: %v", err)
+	}
+	if _, err := env.eng.InsertOne(&models.UserRepository{Identity: "f-1", UserIdentity: "u-1", ParentId: folder.Id, Name: "a.bin",
+		RepositoryIdentity: "r-1", Status: common.StatusActive}); err != nil {
+		t.Fatalf("insert file failed: %v", err)
+	}
+	usage := func() *types.UserQuotaResponse {
+		resp, err := NewUserQuotaLogic(env.ctx, env.svc).UserQuota(&types.UserQuotaRequest{})
+		if err != nil {
+			t.Fatalf("query quota failed: %v", err)
+		}
+		return resp
+	}
+
+	// 首次查询按已有文件统计用量
+	if q := usage(); q.QuotaBytes != 1000 || q.UsedBytes != 400 || q.TrashBytes != 0 {
+		t.Fatalf("unexpected initial usage: %+v", q)
+	}
+	save := &types.SaveResourceRequest{RepositoryIdentity: "r-2", Name: "b.bin"}
+	if _, err := NewSaveResourceLogic(env.ctx, env.svc).SaveResource(save); !errors.Is(err, utils.ErrQuotaExceeded) {
+		t.Fatalf("save beyond quota should fail, got %v", err)
+	}
+	adminCtx := context.WithValue(context.Background(), "user_identity", "u-9")
+	if _, err := NewAdminUserQuotaLogic(adminCtx, env.svc).AdminUserQuota(&types.AdminUserQuotaRequest{Identity: "u-1", QuotaBytes: 2000}); err != nil {
+		t.Fatalf("set quota failed: %v", err)
+	}
+	if _, err := NewSaveResourceLogic(env.ctx, env.svc).SaveResource(save); err != nil {
+		t.Fatalf("save within quota failed: %v", err)
+	}
+	if q := usage(); q.QuotaBytes != 2000 || q.UsedBytes != 1100 {
+		t.Fatalf("unexpected usage after save: %+v", q)
+	}
+
+	if _, err := NewUserFolderDeleteLogic(env.ctx, env.svc).UserFolderDelete(&types.UserFolderDeleteRequest{Identity: "dir"}); err != nil {
+		t.Fatalf("delete folder failed: %v", err)
+	}
+	if q := usage(); q.UsedBytes != 700 || q.TrashBytes != 400 {
+		t.Fatalf("unexpected usage after delete: %+v", q)
+	}
+	if _, err := NewUserFileRestoreLogic(env.ctx, env.svc).UserFileRestore(&types.UserFileRestoreRequest{Identity: "f-1"}); err == nil {
+		t.Fatal("restoring a file inside a deleted folder should fail")
+	}
+	restored, err := NewUserFileRestoreLogic(env.ctx, env.svc).UserFileRestore(&types.UserFileRestoreRequest{Identity: "dir"})
+	if err != nil {
+		t.Fatalf("restore folder failed: %v", err)
+	}
+	if restored.Count != 2 {
+		t.Fatalf("expected folder and file restored, got %d", restored.Count)
+	}
+	if has, _ := env.eng.Where("identity = ? AND status = ?", "f-1", common.StatusActive).Exist(new(models.UserRepository)); !has {
+		t.Fatal("file not visible after restore")
+	}
+	if q := usage(); q.UsedBytes != 1100 || q.TrashBytes != 0 {
+		t.Fatalf("unexpected usage after restore: %+v", q)
+	}
+
+	// 再次删除并让回收站过期，清理后释放回收站占用
+	if _, err := NewUserFolderDeleteLogic(env.ctx, env.svc).UserFolderDelete(&types.UserFolderDeleteRequest{Identity: "dir"}); err != nil {
+		t.Fatalf("delete folder failed: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Format(common.DataTimeFormat)
+	if _, err := env.eng.Exec("UPDATE user_repository SET expire_at = ? WHERE status = ?", past, common.StatusDeleted); err != nil {
+		t.Fatalf("expire trash failed: %v", err)
+	}
+	purgeExpired(context.Background(), env.svc)
+	if q := usage(); q.UsedBytes != 700 || q.TrashBytes != 0 {
+		t.Fatalf("unexpected usage after purge: %+v", q)
+	}
+
+	// 预占为单条条件更新：超出配额的预占不修改用量，连续预占不会越过配额
+	granted := 0
+	for i := 0; i < 20; i++ {
+		err := utils.ReserveQuota(env.eng, "u-1", 100, 0)
+		if err == nil {
+			granted++
+		} else if !errors.Is(err, utils.ErrQuotaExceeded) {
+			t.Fatalf("reserve failed: %v", err)
+		}
+	}
+	if q := usage(); granted != 13 || q.UsedBytes != 2000 {
+		t.Fatalf("reservations should stop at the quota: granted=%d %+v", granted, q)
+	}
+	utils.ReleaseQuota(env.eng, "u-1", 1300, 0)
+	if q := usage(); q.UsedBytes != 700 {
+		t.Fatalf("unexpected usage after release: %+v", q)
+	}
+}
+
+// TestGroupSharedSpace 验证群组空间按成员角色授权文件的浏览、创建、删除与下载。
+func TestGroupSharedSpace(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.Nullable("email").Insert(
+		&models.UserBasic{Identity: "u-1", Name: "alice"},
+		&models.UserBasic{Identity: "u-2", Name: "bob"},
+		&models.UserBasic{Identity: "u-3", Name: "carol"},
+		&models.RepositoryPool{Identity: "r-1", Hash: "h1", Size: 10},
+	); err != nil {
+		t.Fatalf("insert fixtures failed: %v", err)
+	}
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	carolCtx := context.WithValue(context.Background(), "user_identity", "u-3")
+	outsiderCtx := context.WithValue(context.Background(), "user_identity", "u-4")
+
+	group, err := NewGroupCreateLogic(env.ctx, env.svc).GroupCreate(&types.GroupCreateRequest{Name: "项目组"})
+	if err != nil {
+		t.Fatalf("create group failed: %v", err)
+	}
+	addLogic := NewGroupMemberAddLogic(env.ctx, env.svc)
+	if _, err := addLogic.GroupMemberAdd(&types.GroupMemberAddRequest{Identity: group.Identity, UserName: "bob", Role: common.GroupRoleEditor}); err != nil {
+		t.Fatalf("add editor failed: %v", err)
+	}
+	if _, err := addLogic.GroupMemberAdd(&types.GroupMemberAddRequest{Identity: group.Identity, UserName: "carol"}); err != nil {
+		t.Fatalf("add viewer failed: %v", err)
+	}
+	if _, err := NewGroupMemberAddLogic(bobCtx, env.svc).GroupMemberAdd(&types.GroupMemberAddRequest{Identity: group.Identity, UserName: "carol"}); !errors.Is(err, errGroupRoleDenied) {
+		t.Fatalf("editor should not manage members, got %v", err)
+	}
+	groups, err := NewGroupListLogic(carolCtx, env.svc).GroupList(&types.GroupListRequest{})
+	if err != nil || len(groups.List) != 1 || groups.List[0].Role != common.GroupRoleViewer || groups.List[0].RootId != group.RootId {
+		t.Fatalf("unexpected group list: %+v err=%v", groups, err)
+	}
+
+	// 编辑者在群组根目录下创建的文件夹归属群组空间
+	folder, err := NewUserFolderCreateLogic(bobCtx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{ParentId: group.RootId, Name: "设计稿"})
+	if err != nil {
+		t.Fatalf("editor create folder failed: %v", err)
+	}
+	created := new(models.UserRepository)
+	if _, err := env.eng.Where("identity = ?", folder.Identity).Get(created); err != nil || created.UserIdentity != group.Identity {
+		t.Fatalf("folder should belong to group: %+v err=%v", created, err)
+	}
+	if _, err := NewUserFolderCreateLogic(carolCtx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{ParentId: group.RootId, Name: "x"}); !errors.Is(err, errGroupRoleDenied) {
+		t.Fatalf("viewer should not create folder, got %v", err)
+	}
+	list, err := NewUserFileListLogic(carolCtx, env.svc).UserFileList(&types.UserFileListRequest{Id: group.RootId})
+	if err != nil || list.Count != 1 {
+		t.Fatalf("viewer list failed: %+v err=%v", list, err)
+	}
+	if _, err := NewUserFileListLogic(outsiderCtx, env.svc).UserFileList(&types.UserFileListRequest{Id: group.RootId}); err == nil {
+		t.Fatal("non member should not list group folder")
+	}
+
+	// 群组文件对成员可下载，对非成员不可见
+	if _, err := env.eng.InsertOne(&models.UserRepository{Identity: "gf", UserIdentity: group.Identity, ParentId: created.Id,
+		Name: "a.psd", RepositoryIdentity: "r-1", Status: common.StatusActive}); err != nil {
+		t.Fatalf("insert group file failed: %v", err)
+	}
+	if ref, err := groupRepository(env.svc, "u-3", "r-1"); err != nil || ref == nil || ref.Identity != "gf" {
+		t.Fatalf("viewer should download group file: %+v %v", ref, err)
+	}
+	if ref, _ := groupRepository(env.svc, "u-4", "r-1"); ref != nil {
+		t.Fatal("non member should not download group file")
+	}
+	// 成员下载群组文件时，审计记录文件所属的群组空间
+	_, _ = NewDownloadURLLogic(carolCtx, env.svc).DownloadURL(&types.DownloadURLRequest{RepositoryIdentity: "r-1"})
+	download := new(models.FileEventLog)
+	if has, err := env.eng.Where("event_type = ? AND actor_identity = ?", common.EventDownload, "u-3").Get(download); err != nil || !has || download.UserIdentity != group.Identity {
+		t.Fatalf("group download should be audited with its space: %+v %v", download, err)
+	}
+
+	if _, err := NewUserFolderDeleteLogic(carolCtx, env.svc).UserFolderDelete(&types.UserFolderDeleteRequest{Identity: folder.Identity}); !errors.Is(err, errGroupRoleDenied) {
+		t.Fatalf("viewer should not delete, got %v", err)
+	}
+	if _, err := NewUserFolderDeleteLogic(bobCtx, env.svc).UserFolderDelete(&types.UserFolderDeleteRequest{Identity: group.RootIdentity}); err == nil {
+		t.Fatal("group root should not be deleted")
+	}
+	if _, err := NewGroupDeleteLogic(env.ctx, env.svc).GroupDelete(&types.GroupDeleteRequest{Identity: group.Identity}); err == nil {
+		t.Fatal("non-empty group should not be deleted")
+	}
+	if _, err := NewUserFolderDeleteLogic(bobCtx, env.svc).UserFolderDelete(&types.UserFolderDeleteRequest{Identity: folder.Identity}); err != nil {
+		t.Fatalf("editor delete failed: %v", err)
+	}
+
+	// 转让群组后原所有者降为编辑者
+	if _, err := NewGroupMemberRoleLogic(env.ctx, env.svc).GroupMemberRole(&types.GroupMemberRoleRequest{Identity: group.Identity, UserIdentity: "u-2", Role: common.GroupRoleOwner}); err != nil {
+		t.Fatalf("transfer ownership failed: %v", err)
+	}
+	if role, _ := groupRole(env.svc, group.Identity, "u-1"); role != common.GroupRoleEditor {
+		t.Fatalf("previous owner role mismatch: %s", role)
+	}
+	if _, err := NewGroupDeleteLogic(env.ctx, env.svc).GroupDelete(&types.GroupDeleteRequest{Identity: group.Identity}); !errors.Is(err, errGroupRoleDenied) {
+		t.Fatalf("editor should not delete group, got %v", err)
+	}
+	if _, err := NewGroupDeleteLogic(bobCtx, env.svc).GroupDelete(&types.GroupDeleteRequest{Identity: group.Identity}); err != nil {
+		t.Fatalf("delete group failed: %v", err)
+	}
+	if role, _ := groupRole(env.svc, group.Identity, "u-3"); role != "" {
+		t.Fatalf("members should be removed with group, got %s", role)
+	}
+	if list, err := NewUserFileListLogic(carolCtx, env.svc).UserFileList(&types.UserFileListRequest{Id: group.RootId}); err != nil || list.Count != 0 {
+		t.Fatalf("deleted group folder should be empty: %+v err=%v", list, err)
+	}
+}
+
+// TestAuditLog 验证文件操作记录操作人与 IP，并可按条件查询个人操作记录与全站审计日志。
+func TestAuditLog(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.Nullable("email").Insert(
+		&models.UserBasic{Identity: "u-1", Name: "alice"},
+		&models.UserBasic{Identity: "u-2", Name: "bob"},
+		&models.UserBasic{Identity: "u-9", Name: "root", Role: common.RoleAdmin},
+	); err != nil {
+		t.Fatalf("insert users failed: %v", err)
+	}
+	ctx := context.WithValue(env.ctx, "client_ip", "10.0.0.1")
+	folder, err := NewUserFolderCreateLogic(ctx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{Name: "docs"})
+	if err != nil {
+		t.Fatalf("create folder failed: %v", err)
+	}
+	if _, err := NewUserFileNameUpdateLogic(ctx, env.svc).UserFileNameUpdate(&types.UserFileNameUpdateRequest{Identity: folder.Identity, Name: "文档"}); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	if _, err := NewUserFolderCreateLogic(bobCtx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{Name: "bob"}); err != nil {
+		t.Fatalf("create bob folder failed: %v", err)
+	}
+
+	activity, err := NewUserActivityLogic(env.ctx, env.svc).UserActivity(&types.UserActivityRequest{})
+	if err != nil {
+		t.Fatalf("query activity failed: %v", err)
+	}
+	if activity.Count != 2 || activity.List[0].EventType != common.EventRename {
+		t.Fatalf("unexpected activity: %+v", activity.List)
+	}
+	rename := activity.List[0]
+	if rename.ActorIdentity != "u-1" || rename.UserIdentity != "u-1" || rename.Ip != "10.0.0.1" || rename.Detail != "name=docs->文档" {
+		t.Fatalf("unexpected rename event: %+v", rename)
+	}
+	filtered, err := NewUserActivityLogic(env.ctx, env.svc).UserActivity(&types.UserActivityRequest{EventType: common.EventFolderCreate, Start: time.Now().Format("2006-01-02"), End: time.Now().Format("2006-01-02")})
+	if err != nil || filtered.Count != 1 {
+		t.Fatalf("event filter mismatch: %+v err=%v", filtered, err)
+	}
+	if _, err := NewUserActivityLogic(env.ctx, env.svc).UserActivity(&types.UserActivityRequest{Start: "yesterday"}); err == nil {
+		t.Fatal("invalid time should be rejected")
+	}
+	if _, err := NewUserActivityLogic(env.ctx, env.svc).UserActivity(&types.UserActivityRequest{Format: "xml"}); err == nil {
+		t.Fatal("invalid format should be rejected")
+	}
+
+	if _, err := NewAdminAuditLogic(env.ctx, env.svc).AdminAudit(&types.AdminAuditRequest{}); err == nil {
+		t.Fatal("user without audit permission should be rejected")
+	}
+	adminCtx := context.WithValue(context.Background(), "user_identity", "u-9")
+	audit, err := NewAdminAuditLogic(adminCtx, env.svc).AdminAudit(&types.AdminAuditRequest{EventType: common.EventFolderCreate})
+	if err != nil || audit.Count != 2 {
+		t.Fatalf("admin audit mismatch: %+v err=%v", audit, err)
+	}
+	export, err := NewAdminAuditLogic(adminCtx, env.svc).AdminAudit(&types.AdminAuditRequest{ActorIdentity: "u-2", Format: "csv"})
+	if err != nil || len(export.List) != 1 || export.List[0].ActorIdentity != "u-2" {
+		t.Fatalf("admin export mismatch: %+v err=%v", export, err)
+	}
+}
+
+// TestAuditChainVerify 验证审计日志哈希链：篡改任一落库字段、删除中间日志与删除末尾日志均能定位到第一处断链。
+func TestAuditChainVerify(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.Nullable("email").Insert(
+		&models.UserBasic{Identity: "u-1", Name: "alice"},
+		&models.UserBasic{Identity: "u-9", Name: "root", Role: common.RoleAdmin},
+		&models.FileEventLog{Identity: "legacy", EventType: common.EventDelete, UserIdentity: "u-1"},
+	); err != nil {
+		t.Fatalf("insert fixtures failed: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		recordFileEvent(env.ctx, env.svc, common.EventFolderCreate, "u-1", 0, "", fmt.Sprintf("name=f%d", i))
+	}
+	if _, err := NewAdminAuditVerifyLogic(env.ctx, env.svc).AdminAuditVerify(&types.AdminAuditVerifyRequest{}); err == nil {
+		t.Fatal("user without audit permission should be rejected")
+	}
+	adminCtx := context.WithValue(context.Background(), "user_identity", "u-9")
+	verify := func() *types.AdminAuditVerifyResponse {
+		resp, err := NewAdminAuditVerifyLogic(adminCtx, env.svc).AdminAuditVerify(&types.AdminAuditVerifyRequest{})
+		if err != nil {
+			t.Fatalf("verify failed: %v", err)
+		}
+		return resp
+	}
+	if resp := verify(); !resp.Valid || resp.Checked != 4 || resp.Legacy != 1 || resp.HeadHash == "" {
+		t.Fatalf("intact chain should be valid: %+v", resp)
+	}
+
+	var logs []models.FileEventLog
+	if err := env.eng.Where("hash != ''").Asc("id").Find(&logs); err != nil || len(logs) != 4 {
+		t.Fatalf("load logs failed: %d err=%v", len(logs), err)
+	}
+	table, err := env.eng.TableInfo(new(models.FileEventLog))
+	if err != nil {
+		t.Fatalf("load table info failed: %v", err)
+	}
+	for _, column := range table.Columns() {
+		if column.Name == "id" {
+			continue
+		}
+		rows, err := env.eng.QueryString(fmt.Sprintf("SELECT %s FROM file_event_log WHERE id = ?", column.Name), logs[1].Id)
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("load %s failed: %v", column.Name, err)
+		}
+		original := rows[0][column.Name]
+		if _, err := env.eng.Exec(fmt.Sprintf("UPDATE file_event_log SET %s = ? WHERE id = ?", column.Name), original+"x", logs[1].Id); err != nil {
+			t.Fatalf("tamper %s failed: %v", column.Name, err)
+		}
+		if resp := verify(); resp.Valid {
+			t.Fatalf("tampered %s not reported: %+v", column.Name, resp)
+		}
+		if _, err := env.eng.Exec(fmt.Sprintf("UPDATE file_event_log SET %s = ? WHERE id = ?", column.Name), original, logs[1].Id); err != nil {
+			t.Fatalf("restore %s failed: %v", column.Name, err)
+		}
+		if resp := verify(); !resp.Valid {
+			t.Fatalf("restored %s should be valid: %+v", column.Name, resp)
+		}
+	}
+	if _, err := env.eng.Exec("UPDATE file_event_log SET detail = ? WHERE id = ?", "name=forged", logs[1].Id); err != nil {
+		t.Fatalf("tamper failed: %v", err)
+	}
+	if resp := verify(); resp.Valid || resp.BrokenId != logs[1].Id || resp.BrokenIdentity != logs[1].Identity {
+		t.Fatalf("tampered row not reported: %+v", resp)
+	}
+	if _, err := env.eng.Exec("DELETE FROM file_event_log WHERE id = ?", logs[1].Id); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if resp := verify(); resp.Valid || resp.BrokenId != logs[2].Id {
+		t.Fatalf("deleted row not reported: %+v", resp)
+	}
+
+	env2 := newTestEnv(t)
+	env2.eng.Nullable("email").Insert(&models.UserBasic{Identity: "u-9", Name: "root", Role: common.RoleAdmin})
+	for i := 0; i < 2; i++ {
+		recordFileEvent(env2.ctx, env2.svc, common.EventFolderCreate, "u-1", 0, "", "")
+	}
+	if _, err := env2.eng.Exec("DELETE FROM file_event_log WHERE id = (SELECT MAX(id) FROM file_event_log)"); err != nil {
+		t.Fatalf("delete tail failed: %v", err)
+	}
+	resp, err := NewAdminAuditVerifyLogic(adminCtx, env2.svc).AdminAuditVerify(&types.AdminAuditVerifyRequest{})
+	if err != nil || resp.Valid || resp.Reason == "" {
+		t.Fatalf("truncated chain not reported: %+v err=%v", resp, err)
+	}
+}
+
+// TestWebhookDelivery 验证 webhook 按文件夹与事件类型匹配、签名投递、失败重试、手动重新投递、内网地址拦截与权限隔离。
+func TestWebhookDelivery(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.Nullable("email").Insert(
+		&models.UserBasic{Identity: "u-1", Name: "alice"},
+		&models.UserBasic{Identity: "u-2", Name: "bob"},
+		&models.UserBasic{Identity: "u-9", Name: "root", Role: common.RoleAdmin},
+	); err != nil {
+		t.Fatalf("insert users failed: %v", err)
+	}
+	var mu sync.Mutex
+	status := http.StatusInternalServerError
+	var received []*http.Request
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	inbox, err := NewUserFolderCreateLogic(env.ctx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{Name: "inbox"})
+	if err != nil {
+		t.Fatalf("create folder failed: %v", err)
+	}
+	folder := new(models.UserRepository)
+	if _, err := env.eng.Where("identity = ?", inbox.Identity).Get(folder); err != nil {
+		t.Fatalf("load folder failed: %v", err)
+	}
+	create := NewCreateWebhookLogic(env.ctx, env.svc)
+	// 默认拒绝内网回调地址；测试接收方在本机，需显式允许
+	for _, u := range []string{receiver.URL, "http://169.254.169.254/latest", "http://[::1]:8080/hook", "http://10.1.2.3/hook"} {
+		if _, err := create.CreateWebhook(&types.CreateWebhookRequest{Url: u}); err == nil {
+			t.Fatalf("private webhook target should be rejected: %s", u)
+		}
+	}
+	utils.SetWebhookAllowPrivate(true)
+	t.Cleanup(func() { utils.SetWebhookAllowPrivate(false) })
+	for _, req := range []*types.CreateWebhookRequest{
+		{Url: "ftp://example.com/hook"},
+		{Url: receiver.URL, EventTypes: []string{"login_locked"}},
+		{Url: receiver.URL, Global: true},
+		{Url: receiver.URL, Secret: "short"},
+	} {
+		if _, err := create.CreateWebhook(req); err == nil {
+			t.Fatalf("invalid webhook should be rejected: %+v", req)
+		}
+	}
+	hook, err := create.CreateWebhook(&types.CreateWebhookRequest{Url: receiver.URL, ParentId: folder.Id, EventTypes: []string{common.EventFolderCreate}})
+	if err != nil || !strings.HasPrefix(hook.Secret, "whsec_") {
+		t.Fatalf("create webhook failed: %+v err=%v", hook, err)
+	}
+	adminCtx := context.WithValue(context.Background(), "user_identity", "u-9")
+	global, err := NewCreateWebhookLogic(adminCtx, env.svc).CreateWebhook(&types.CreateWebhookRequest{Url: receiver.URL, Global: true})
+	if err != nil {
+		t.Fatalf("create global webhook failed: %v", err)
+	}
+
+	if _, err := NewUserFolderCreateLogic(env.ctx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{Name: "sub", ParentId: folder.Id}); err != nil {
+		t.Fatalf("create sub folder failed: %v", err)
+	}
+	if _, err := NewUserFolderCreateLogic(env.ctx, env.svc).UserFolderCreate(&types.UserFolderCreateRequest{Name: "outside"}); err != nil {
+		t.Fatalf("create outside folder failed: %v", err)
+	}
+	deliveries := func(ctx context.Context, identity, status string) *types.WebhookDeliveryListResponse {
+		resp, err := NewWebhookDeliveryListLogic(ctx, env.svc).WebhookDeliveryList(&types.WebhookDeliveryListRequest{Identity: identity, Status: status})
+		if err != nil {
+			t.Fatalf("list deliveries failed: %v", err)
+		}
+		return resp
+	}
+	// 未配置 RabbitMQ 时投递记录标记为入队失败，可手动重新投递
+	scoped := deliveries(env.ctx, hook.Identity, "")
+	if scoped.Count != 1 || scoped.List[0].Status != common.WebhookStatusFailed || !strings.Contains(scoped.List[0].Error, "入队") {
+		t.Fatalf("scoped webhook deliveries mismatch: %+v", scoped.List)
+	}
+	if all := deliveries(adminCtx, global.Identity, ""); all.Count != 2 {
+		t.Fatalf("global webhook should receive every event: %d", all.Count)
+	}
+
+	first := scoped.List[0]
+	if _, err := env.eng.Where("identity = ?", first.Identity).Cols("status").Update(&models.WebhookDelivery{Status: common.WebhookStatusPending}); err != nil {
+		t.Fatalf("reset delivery failed: %v", err)
+	}
+	result, err := mq.AttemptWebhookDelivery(context.Background(), env.svc, first.Identity, mq.WebhookMaxAttempts())
+	if err != nil || result.Status != common.WebhookStatusPending || result.Attempts != 1 || result.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("failed attempt should wait for retry: %+v err=%v", result, err)
+	}
+	mu.Lock()
+	status = http.StatusNoContent
+	mu.Unlock()
+	result, err = mq.AttemptWebhookDelivery(context.Background(), env.svc, first.Identity, mq.WebhookMaxAttempts())
+	if err != nil || result.Status != common.WebhookStatusSuccess || result.Attempts != 2 {
+		t.Fatalf("retry should succeed: %+v err=%v", result, err)
+	}
+	last := received[len(received)-1]
+	timestamp, _ := strconv.ParseInt(last.Header.Get(utils.WebhookTimestampHeader), 10, 64)
+	if last.Header.Get(utils.WebhookSignatureHeader) != utils.WebhookSignature(hook.Secret, timestamp, bodies[len(bodies)-1]) ||
+		last.Header.Get(utils.WebhookEventHeader) != common.EventFolderCreate || last.Header.Get(utils.WebhookDeliveryHeader) != first.Identity {
+		t.Fatalf("signature headers mismatch: %v", last.Header)
+	}
+	var payload types.WebhookPayload
+	if err := json.Unmarshal(bodies[len(bodies)-1], &payload); err != nil || payload.FolderId != folder.Id || payload.SpaceIdentity != "u-1" || payload.EventIdentity == "" {
+		t.Fatalf("payload mismatch: %+v err=%v", payload, err)
+	}
+
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	if _, err := NewWebhookRedeliverLogic(bobCtx, env.svc).WebhookRedeliver(&types.WebhookRedeliverRequest{Identity: first.Identity}); err == nil {
+		t.Fatal("other user should not redeliver")
+	}
+	redelivered, err := NewWebhookRedeliverLogic(env.ctx, env.svc).WebhookRedeliver(&types.WebhookRedeliverRequest{Identity: first.Identity})
+	if err != nil || redelivered.Status != common.WebhookStatusSuccess || redelivered.Identity == first.Identity || redelivered.Payload != first.Payload {
+		t.Fatalf("redeliver mismatch: %+v err=%v", redelivered, err)
+	}
+	if ok := deliveries(env.ctx, hook.Identity, common.WebhookStatusSuccess); ok.Count != 2 {
+		t.Fatalf("success deliveries mismatch: %d", ok.Count)
+	}
+
+	// 投递时拨号前再次校验目标 IP（防止 DNS 重绑定），错误信息不回显网络细节
+	utils.SetWebhookAllowPrivate(false)
+	if _, err := env.eng.Where("identity = ?", first.Identity).Cols("status").Update(&models.WebhookDelivery{Status: common.WebhookStatusPending}); err != nil {
+		t.Fatalf("reset delivery failed: %v", err)
+	}
+	result, err = mq.AttemptWebhookDelivery(context.Background(), env.svc, first.Identity, mq.WebhookMaxAttempts())
+	if err != nil || result.Status != common.WebhookStatusPending || result.ResponseCode != 0 ||
+		!strings.Contains(result.Error, "公网") || strings.Contains(result.Error, "127.0.0.1") {
+		t.Fatalf("private target should be refused at dial time: %+v err=%v", result, err)
+	}
+	utils.SetWebhookAllowPrivate(true)
+
+	if list, err := NewWebhookListLogic(env.ctx, env.svc).WebhookList(&types.WebhookListRequest{}); err != nil || list.Count != 1 || list.List[0].ParentId != folder.Id {
+		t.Fatalf("list mismatch: %+v err=%v", list, err)
+	}
+	if _, err := NewWebhookListLogic(env.ctx, env.svc).WebhookList(&types.WebhookListRequest{Global: true}); err == nil {
+		t.Fatal("global list requires permission")
+	}
+	if _, err := NewWebhookDeleteLogic(bobCtx, env.svc).WebhookDelete(&types.WebhookDeleteRequest{Identity: hook.Identity}); err == nil {
+		t.Fatal("other user should not delete webhook")
+	}
+	if _, err := NewWebhookDeleteLogic(env.ctx, env.svc).WebhookDelete(&types.WebhookDeleteRequest{Identity: hook.Identity}); err != nil {
+		t.Fatalf("delete webhook failed: %v", err)
+	}
+	if _, err := env.eng.Where("identity = ?", first.Identity).Cols("status").Update(&models.WebhookDelivery{Status: common.WebhookStatusPending}); err != nil {
+		t.Fatalf("reset delivery failed: %v", err)
+	}
+	result, err = mq.AttemptWebhookDelivery(context.Background(), env.svc, first.Identity, mq.WebhookMaxAttempts())
+	if err != nil || result.Status != common.WebhookStatusFailed {
+		t.Fatalf("delivery of deleted webhook should fail: %+v err=%v", result, err)
+	}
+}
+
+// TestRealtimeNotifications 验证存储用量首次越过告警阈值与分享被他人查看时推送实时通知。
+func TestRealtimeNotifications(t *testing.T) {
+	env := newTestEnv(t)
+	t.Setenv("DEFAULT_QUOTA_BYTES", "1000")
+	if _, err := env.eng.Insert(
+		&models.RepositoryPool{Identity: "r-1", Hash: "h1", Name: "a.bin", Size: 500},
+		&models.RepositoryPool{Identity: "r-2", Hash: "h2", Name: "b.bin", Size: 450},
+		&models.RepositoryPool{Identity: "r-3", Hash: "h3", Name: "c.bin", Size: 10},
+		&models.ShareBasic{Identity: "s-1", UserIdentity: "u-2", RepositoryIdentity: "r-1"},
+		&models.UserRepository{Identity: "f-2", UserIdentity: "u-2", Name: "a.bin", RepositoryIdentity: "r-1", Status: common.StatusActive},
+	); err != nil {
+		t.Fatalf("insert fixtures failed: %v", err)
+	}
+	sub, err := env.svc.Notifier.Subscribe("u-1")
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer sub.Close()
+	owner, err := env.svc.Notifier.Subscribe("u-2")
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer owner.Close()
+
+	save := NewSaveResourceLogic(env.ctx, env.svc)
+	for _, req := range []*types.SaveResourceRequest{
+		{RepositoryIdentity: "r-1", Name: "a.bin"},
+		{RepositoryIdentity: "r-2", Name: "b.bin"},
+		{RepositoryIdentity: "r-3", Name: "c.bin"},
+	} {
+		if _, err := save.SaveResource(req); err != nil {
+			t.Fatalf("save %s failed: %v", req.Name, err)
+		}
+	}
+	if len(sub.C) != 1 {
+		t.Fatalf("expected exactly one quota warning, got %d", len(sub.C))
+	}
+	event := <-sub.C
+	var notice types.QuotaNotice
+	if err := json.Unmarshal(event.Data, &notice); err != nil || event.Type != common.NotifyQuotaWarning || notice.UsedBytes != 950 || notice.Percent != 95 {
+		t.Fatalf("unexpected quota warning: %+v %s", event, event.Data)
+	}
+
+	if _, err := NewGetShareRecordLogic(env.ctx, env.svc).GetShareRecord(&types.GetShareRecordRequest{Identity: "s-1"}); err != nil {
+		t.Fatalf("get share failed: %v", err)
+	}
+	ownerCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	if _, err := NewGetShareRecordLogic(ownerCtx, env.svc).GetShareRecord(&types.GetShareRecordRequest{Identity: "s-1"}); err != nil {
+		t.Fatalf("get share failed: %v", err)
+	}
+	if len(owner.C) != 1 {
+		t.Fatalf("expected one share notification, got %d", len(owner.C))
+	}
+	event = <-owner.C
+	var access types.ShareAccessNotice
+	if err := json.Unmarshal(event.Data, &access); err != nil || event.Type != common.NotifyShareAccessed ||
+		access.ShareIdentity != "s-1" || access.Action != common.ShareActionView || access.Visitor != "u-1" {
+		t.Fatalf("unexpected share notification: %+v %s", event, event.Data)
+	}
+}
+
+// TestNotificationInbox 验证定向分享与回收站清理提醒（含群组空间）写入站内通知，以及未读数缓存与标记已读。
+func TestNotificationInbox(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.eng.Nullable("email").Insert(
+		&models.UserBasic{Identity: "u-1", Name: "owner"},
+		&models.UserBasic{Identity: "u-2", Name: "bob"},
+	); err != nil {
+		t.Fatalf("insert users failed: %v", err)
+	}
+	folder := &models.UserRepository{Identity: "dir", UserIdentity: "u-1", Name: "docs", Status: common.StatusActive}
+	if _, err := env.eng.InsertOne(folder); err != nil {
+		t.Fatalf("insert folder failed: %v", err)
+	}
+	grant := &types.CreateShareGrantRequest{Identity: "dir", Users: []string{"bob"}, Permission: common.SharePermissionView}
+	if _, err := NewCreateShareGrantLogic(env.ctx, env.svc).CreateShareGrant(grant); err != nil {
+		t.Fatalf("create grant failed: %v", err)
+	}
+
+	// 删除的文件夹与其子项两天后清理，只提醒一次且不重复计数子项
+	expireAt := time.Now().Add(48 * time.Hour).Format(common.DataTimeFormat)
+	trash := &models.UserRepository{Identity: "old", UserIdentity: "u-2", Name: "old", Status: common.StatusDeleted, ExpireAt: expireAt}
+	if _, err := env.eng.InsertOne(trash); err != nil {
+		t.Fatalf("insert trash failed: %v", err)
+	}
+	if _, err := env.eng.InsertOne(&models.UserRepository{Identity: "old-1", UserIdentity: "u-2", ParentId: trash.Id, Name: "a.txt",
+		RepositoryIdentity: "r-1", Status: common.StatusDeleted, ExpireAt: expireAt}); err != nil {
+		t.Fatalf("insert trash file failed: %v", err)
+	}
+	warnUpcomingPurges(context.Background(), env.svc)
+	warnUpcomingPurges(context.Background(), env.svc)
+
+	bobCtx := context.WithValue(context.Background(), "user_identity", "u-2")
+	list, err := NewNotificationListLogic(bobCtx, env.svc).NotificationList(&types.NotificationListRequest{})
+	if err != nil {
+		t.Fatalf("list notifications failed: %v", err)
+	}
+	if list.Count != 2 || list.Unread != 2 || list.List[0].Type != common.InboxTrashPurge || list.List[1].Type != common.InboxShareReceived {
+		t.Fatalf("unexpected inbox: %+v", list)
+	}
+	var purge types.TrashPurgeNotice
+	if err := json.Unmarshal([]byte(list.List[0].Data), &purge); err != nil || purge.Count != 1 || purge.Names[0] != "old" {
+		t.Fatalf("unexpected purge notice: %s", list.List[0].Data)
+	}
+	cached, _ := env.rdb.Get(context.Background(), utils.NotificationUnreadKey("u-2")).Result()
+	if version, _ := env.rdb.Get(context.Background(), utils.NotificationUnreadVersionKey("u-2")).Result(); cached != version+":2" {
+		t.Fatalf("unread count not cached: %q", cached)
+	}
+
+	// 并发查询在失效之后写回的旧值不会被采用
+	mq.AddNotification(context.Background(), env.svc, "u-2", common.InboxShareReceived, "t", "c", nil)
+	env.rdb.Set(context.Background(), utils.NotificationUnreadKey("u-2"), cached, utils.NotificationUnreadTTL)
+	if n, err := unreadCount(context.Background(), env.svc, "u-2"); err != nil || n != 3 {
+		t.Fatalf("stale unread count served: %d %v", n, err)
+	}
+	if _, err := env.eng.Where("user_identity = ? AND title = ?", "u-2", "t").Delete(new(models.Notification)); err != nil {
+		t.Fatalf("delete notification failed: %v", err)
+	}
+	mq.InvalidateUnread(context.Background(), env.svc, "u-2")
+
+	// 其他用户无法标记不属于自己的通知
+	if resp, err := NewNotificationReadLogic(env.ctx, env.svc).NotificationRead(&types.NotificationReadRequest{Identities: []string{list.List[1].Identity}}); err != nil || resp.Count != 0 {
+		t.Fatalf("foreign read should not apply: %+v %v", resp, err)
+	}
+	read, err := NewNotificationReadLogic(bobCtx, env.svc).NotificationRead(&types.NotificationReadRequest{Identities: []string{list.List[1].Identity}})
+	if err != nil || read.Count != 1 || read.Unread != 1 {
+		t.Fatalf("mark read failed: %+v %v", read, err)
+	}
+	unread, err := NewNotificationListLogic(bobCtx, env.svc).NotificationList(&types.NotificationListRequest{Unread: true})
+	if err != nil || unread.Count != 1 || unread.List[0].Type != common.InboxTrashPurge {
+		t.Fatalf("unexpected unread list: %+v %v", unread, err)
+	}
+	all, err := NewNotificationReadAllLogic(bobCtx, env.svc).NotificationReadAll(&types.NotificationReadAllRequest{})
+	if err != nil || all.Count != 1 || all.Unread != 0 {
+		t.Fatalf("mark all read failed: %+v %v", all, err)
+	}
+
+	// 群组回收站的清理提醒发给 editor 及以上成员，而不是群组标识
+	if _, err := env.eng.Insert(
+		&models.GroupBasic{Identity: "g-1", Name: "team", OwnerIdentity: "u-1"},
+		&models.GroupMember{GroupIdentity: "g-1", UserIdentity: "u-1", Role: common.GroupRoleOwner},
+		&models.GroupMember{GroupIdentity: "g-1", UserIdentity: "u-2", Role: common.GroupRoleViewer},
+		&models.UserRepository{Identity: "g-old", UserIdentity: "g-1", Name: "plan", Status: common.StatusDeleted, ExpireAt: expireAt},
+	); err != nil {
+		t.Fatalf("insert group fixtures failed: %v", err)
+	}
+	warnUpcomingPurges(context.Background(), env.svc)
+	ownerList, err := NewNotificationListLogic(env.ctx, env.svc).NotificationList(&types.NotificationListRequest{})
+	if err != nil || ownerList.Count != 1 || ownerList.List[0].Type != common.InboxTrashPurge || !strings.Contains(ownerList.List[0].Content, "team") {
+		t.Fatalf("group owner should be notified: %+v %v", ownerList, err)
+	}
+	var groupPurge types.TrashPurgeNotice
+	if err := json.Unmarshal([]byte(ownerList.List[0].Data), &groupPurge); err != nil || groupPurge.SpaceIdentity != "g-1" || groupPurge.GroupName != "team" {
+		t.Fatalf("unexpected group purge notice: %s", ownerList.List[0].Data)
+	}
+	for _, user := range []string{"u-2", "g-1"} {
+		if cnt, _ := env.eng.Where("user_identity = ? AND is_read = ?", user, false).Count(new(models.Notification)); cnt != 0 {
+			t.Fatalf("%s should not receive group purge notice: %d", user, cnt)
+		}
+	}
+}
+
+// TestTransactionalMail 验证邮件按请求语言渲染后经本地 SMTP 服务送达，发送失败时按次数重试并最终放弃。
+func TestTransactionalMail(t *testing.T) {
+	env := newTestEnv(t)
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("start smtp server failed: %v", err)
+	}
+	t.Cleanup(server.Close)
+	oldStartTLS := utils.EmailStartTLS()
+	utils.SetEmailConfig(true, server.Host, server.Port, "noreply@example.com", "pw")
+	utils.SetEmailStartTLS(false)
+	t.Cleanup(func() { utils.SetEmailStartTLS(oldStartTLS) })
+
+	ctx := context.WithValue(env.ctx, "accept_language", "en-US,en;q=0.9")
+	req := &types.SendVerificationCodeRequest{Email: "erin@example.com", Purpose: common.CodePurposeReset}
+	if _, err := env.eng.Insert(&models.UserBasic{Identity: "u-9", Name: "erin", Email: "erin@example.com"}); err != nil {
+		t.Fatalf("insert user failed: %v", err)
+	}
+	if _, err := NewSendVerificationCodeLogic(ctx, env.svc).SendVerificationCode(req); err != nil {
+		t.Fatalf("send verification code failed: %v", err)
+	}
+	msgs, ok := server.Wait(1, time.Second)
+	if !ok {
+		t.Fatal("verification mail not delivered")
+	}
+	code, _ := env.rdb.Get(env.ctx, verificationCodeKey(common.CodePurposeReset, "erin@example.com")).Result()
+	if msgs[0].To[0] != "erin@example.com" || msgs[0].Subject() != "Your CloudDisk password reset code" {
+		t.Fatalf("unexpected mail: %v %q", msgs[0].To, msgs[0].Subject())
+	}
+	if code == "" || !strings.Contains(msgs[0].HTML(), code) {
+		t.Fatalf("mail body missing code %q", code)
+	}
+
+	subject, body, err := utils.RenderMail(common.MailUploadReceived, common.LangZh, map[string]any{"Name": "a.txt", "Size": int64(1024)})
+	if err != nil {
+		t.Fatalf("render mail failed: %v", err)
+	}
+	task := &types.MailTask{Identity: "m-1", To: "erin@example.com", Template: common.MailUploadReceived, Subject: subject, HTML: body}
+	server.FailNext(1)
+	if retry := mq.DeliverMail(task); !retry || task.Attempts != 1 {
+		t.Fatalf("failed delivery should be retried: %v %d", retry, task.Attempts)
+	}
+	if retry := mq.DeliverMail(task); retry {
+		t.Fatal("successful delivery should not be retried")
+	}
+	if msgs, ok := server.Wait(2, time.Second); !ok || msgs[1].Subject() != subject {
+		t.Fatalf("retried mail not delivered: %v", ok)
+	}
+
+	server.FailNext(len(common.MailRetryDelays) + 1)
+	task = &types.MailTask{Identity: "m-2", To: "erin@example.com", Subject: subject, HTML: body}
+	for i := 0; i < len(common.MailRetryDelays); i++ {
+		if !mq.DeliverMail(task) {
+			t.Fatalf("attempt %d should be retried", i+1)
+		}
+	}
+	if mq.DeliverMail(task) {
+		t.Fatal("mail should be dropped after the last retry")
+	}
+
+	// 验证码类邮件不会在过期后发送或重试
+	server.FailNext(2)
+	task = &types.MailTask{Identity: "m-3", To: "erin@example.com", Template: common.MailVerification, Subject: subject, HTML: body,
+		ExpireAt: time.Now().Add(time.Minute).Unix()}
+	if !mq.DeliverMail(task) {
+		t.Fatal("retry within expiry should be scheduled")
+	}
+	if mq.DeliverMail(task) {
+		t.Fatal("retry past expiry should be dropped")
+	}
+	task = &types.MailTask{Identity: "m-4", To: "erin@example.com", Template: common.MailVerification, Subject: subject, HTML: body,
+		ExpireAt: time.Now().Add(-time.Second).Unix()}
+	if mq.DeliverMail(task) || task.Attempts != 0 {
+		t.Fatalf("expired mail should not be sent: %+v", task)
+	}
+	if _, ok := server.Wait(3, 100*time.Millisecond); ok {
+		t.Fatal("expired mail was delivered")
+	}
+}
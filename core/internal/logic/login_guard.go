@@ -0,0 +1,201 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// loginFailWindow 登录失败计数的滑动窗口。
+	loginFailWindow = 15 * time.Minute
+	// loginDelayAfter 同一账号失败多少次后开始递增延迟。
+	loginDelayAfter = 3
+	// loginMaxDelay 递增延迟的上限。
+	loginMaxDelay = time.Minute
+	// loginLockAfter 同一账号失败多少次后临时锁定。
+	loginLockAfter = 10
+	// loginLockDuration 账号锁定时长。
+	loginLockDuration = 30 * time.Minute
+	// loginIPLimit 同一 IP 在窗口内允许的失败次数。
+	loginIPLimit = 50
+)
+
+// loginGuard 基于 Redis 滑动窗口的登录防暴力破解。
+type loginGuard struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// newLoginGuard 创建登录防护。
+func newLoginGuard(ctx context.Context, svcCtx *svc.ServiceContext) *loginGuard {
+	return &loginGuard{ctx: ctx, svcCtx: svcCtx}
+}
+
+// loginSubject 返回登录防护的账号计数主体：已存在的账号按用户标识计数，
+// 不存在的用户名按去除首尾空白并转小写后的名称计数，使大小写或尾随空格不同的写法共用同一失败额度。
+func loginSubject(name string, user *models.UserBasic) string {
+	if user != nil && user.Identity != "" {
+		return "user:" + user.Identity
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(name))
+}
+
+// check 在校验密码前检查 IP 封禁、账号锁定与递增延迟；subject 由 loginSubject 生成。
+func (g *loginGuard) check(subject, ip string) error {
+	if ip != "" {
+		n, err := g.failures("ip:" + ip)
+		if err != nil {
+			return err
+		}
+		if n >= loginIPLimit {
+			return errors.New("登录失败次数过多，请稍后再试")
+		}
+	}
+	if wait, err := g.remaining("login_lock:" + subject); err != nil {
+		return err
+	} else if wait > 0 {
+		return fmt.Errorf("账号已被临时锁定，请 %d 分钟后再试或通过邮件验证码解锁", int(wait.Minutes())+1)
+	}
+	if wait, err := g.remaining("login_delay:" + subject); err != nil {
+		return err
+	} else if wait > 0 {
+		return fmt.Errorf("登录尝试过于频繁，请 %d 秒后再试", int(wait.Seconds())+1)
+	}
+	return nil
+}
+
+// recordFailure 记录一次失败，按失败次数设置延迟或锁定账号；user 为空表示用户名不存在。
+func (g *loginGuard) recordFailure(subject, ip string, user *models.UserBasic) {
+	if ip != "" {
+		if _, err := g.addFailure("ip:" + ip); err != nil {
+			logx.Errorf("login guard record ip failed ip=%s err=%v", ip, err)
+		}
+	}
+	n, err := g.addFailure("account:" + subject)
+	if err != nil {
+		logx.Errorf("login guard record account failed subject=%s err=%v", subject, err)
+		return
+	}
+	if n >= loginLockAfter && user != nil {
+		g.lock(subject, user)
+		return
+	}
+	if n >= loginDelayAfter {
+		delay := time.Second << uint(n-loginDelayAfter)
+		if delay > loginMaxDelay {
+			delay = loginMaxDelay
+		}
+		g.setUntil("login_delay:"+subject, delay)
+	}
+}
+
+// reset 登录成功或解锁后清空账号的失败记录。
+func (g *loginGuard) reset(subject string) {
+	_ = g.svcCtx.RedisClient.Del(g.ctx, loginFailKey("account:"+subject), "login_delay:"+subject, "login_lock:"+subject).Err()
+}
+
+// lock 锁定账号，发送解锁验证码邮件并记录审计事件。
+func (g *loginGuard) lock(subject string, user *models.UserBasic) {
+	g.setUntil("login_lock:"+subject, loginLockDuration)
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		logx.Errorf("login guard unlock code failed name=%s err=%v", user.Name, err)
+		return
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	if err := g.svcCtx.RedisClient.Set(g.ctx, unlockCodeKey(user.Identity), code, loginLockDuration).Err(); err != nil {
+		logx.Errorf("login guard store unlock code failed name=%s err=%v", user.Name, err)
+	}
+	_ = g.svcCtx.RedisClient.Del(g.ctx, unlockAttemptsKey(user.Identity)).Err()
+	if err := utils.RecordFileEvent(g.svcCtx.DBEngine, fileEvent(g.ctx, common.EventLoginLocked, user.Identity, "", "")); err != nil {
+		logx.Errorf("login guard audit failed name=%s err=%v", user.Name, err)
+	}
+	logx.Infof("account locked after repeated login failures name=%s", user.Name)
+
+	if !utils.EmailEnabled() || user.Email == "" {
+		logx.Infof("邮箱发送已禁用或未绑定邮箱，账号解锁验证码未发送 name=%s", user.Name)
+		return
+	}
+	err = mq.SendMail(g.ctx, g.svcCtx, user.Email, common.MailAccountUnlock, utils.MailLang(user.Language, requestLanguage(g.ctx)), map[string]any{
+		"Code":    code,
+		"Minutes": int(loginLockDuration.Minutes()),
+	})
+	if err != nil {
+		logx.Errorf("发送账号解锁邮件失败: %v", err)
+	}
+}
+
+// failures 返回窗口内的失败次数。
+func (g *loginGuard) failures(subject string) (int64, error) {
+	key := loginFailKey(subject)
+	min := strconv.FormatInt(time.Now().Add(-loginFailWindow).UnixMilli(), 10)
+	if err := g.svcCtx.RedisClient.ZRemRangeByScore(g.ctx, key, "-inf", "("+min).Err(); err != nil {
+		return 0, err
+	}
+	return g.svcCtx.RedisClient.ZCard(g.ctx, key).Result()
+}
+
+// addFailure 追加一次失败并返回窗口内的失败次数。
+func (g *loginGuard) addFailure(subject string) (int64, error) {
+	key := loginFailKey(subject)
+	now := time.Now()
+	if err := g.svcCtx.RedisClient.ZAdd(g.ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: utils.UUID()}).Err(); err != nil {
+		return 0, err
+	}
+	_ = g.svcCtx.RedisClient.Expire(g.ctx, key, loginFailWindow).Err()
+	return g.failures(subject)
+}
+
+// setUntil 写入带截止时间的限制键。
+func (g *loginGuard) setUntil(key string, ttl time.Duration) {
+	until := time.Now().Add(ttl).UnixMilli()
+	if err := g.svcCtx.RedisClient.Set(g.ctx, key, until, ttl).Err(); err != nil {
+		logx.Errorf("login guard set %s failed: %v", key, err)
+	}
+}
+
+// remaining 返回限制键的剩余时长，不存在时为 0。
+func (g *loginGuard) remaining(key string) (time.Duration, error) {
+	val, err := g.svcCtx.RedisClient.Get(g.ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	until, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return time.Until(time.UnixMilli(until)), nil
+}
+
+// unlockCodeKey 返回账号解锁验证码的键。
+func unlockCodeKey(userIdentity string) string {
+	return "login_unlock:" + userIdentity
+}
+
+// unlockAttemptsKey 返回解锁验证码校验次数的有序集合键。
+func unlockAttemptsKey(userIdentity string) string {
+	return "login_unlock_attempts:" + userIdentity
+}
+
+// loginFailKey 返回失败计数的有序集合键。
+func loginFailKey(subject string) string {
+	return "login_fail:" + subject
+}
@@ -35,25 +35,121 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic
 func (l *LoginLogic) Login(req *types.LoginRequest) (resp *types.LoginResponse, err error) {
 
 	user := new(models.UserBasic)
-	// 根据用户名和密码在数据库中查找用户
-    has, err := l.svcCtx.DBEngine.Where("name = ? AND password = ?", req.Name, utils.Md5(utils.DecodeMaybeBase64(req.Password))).Get(user)
+	// 根据用户名查找用户并校验密码
+	has, err := l.svcCtx.DBEngine.Where("name = ?", req.Name).Get(user)
 	if err != nil {
 		return nil, err
 	}
-	if !has {
-		return nil, errors.New("用户名或密码错误")
+	// 失败计数按用户标识记录，数据库比较时视为同一账号的不同写法共用失败额度
+	subject := loginSubject(req.Name, nil)
+	if has {
+		subject = loginSubject(req.Name, user)
 	}
-	// 生成token
-	token, err := utils.GenToken(utils.JwtPayLoad{
+	clientIP, _ := l.ctx.Value("client_ip").(string)
+	guard := newLoginGuard(l.ctx, l.svcCtx)
+	if err := guard.check(subject, clientIP); err != nil {
+		return nil, err
+	}
+	password := utils.DecodeMaybeBase64(req.Password)
+	// 启用 LDAP 时，本地不存在或已绑定目录的账号交由目录服务认证
+	directory, err := ldapManaged(l.svcCtx, user, has)
+	if err != nil {
+		return nil, err
+	}
+	if directory {
+		dirUser, ok, err := ldapAuthenticate(l.svcCtx, req.Name, password)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if !has {
+				user = nil
+			}
+			guard.recordFailure(subject, clientIP, user)
+			return nil, errors.New("用户名或密码错误")
+		}
+		user = dirUser
+	} else {
+		if !has {
+			guard.recordFailure(subject, clientIP, nil)
+			return nil, errors.New("用户名或密码错误")
+		}
+		ok, needsRehash := utils.VerifyPassword(user.Password, password)
+		if !ok {
+			guard.recordFailure(subject, clientIP, user)
+			return nil, errors.New("用户名或密码错误")
+		}
+		// 旧的 MD5 哈希在登录成功后透明升级
+		if needsRehash {
+			l.rehashPassword(user.Identity, password)
+		}
+	}
+	if user.Disabled {
+		return nil, errDisabledAccount
+	}
+
+	// 已开启两步验证或角色强制要求时，返回登录挑战而非令牌；失败计数保留到第二因素验证通过后才清空
+	if resp, err := twoFactorChallenge(l.ctx, l.svcCtx, user); err != nil || resp != nil {
+		return resp, err
+	}
+	guard.reset(subject)
+	// 生成访问令牌与刷新令牌
+	pair, err := issueTokenPair(l.ctx, l.svcCtx, utils.JwtPayLoad{
 		Id:       user.Id,
 		Identity: user.Identity,
 		Name:     user.Name,
-	}, l.svcCtx.Config.Auth.AccessSecret, l.svcCtx.Config.Auth.AccessExpire)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.LoginResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		Name:         req.Name,
+	}, nil
+}
+
+// rehashPassword 使用当前算法重新生成密码哈希，失败不影响本次登录。
+func (l *LoginLogic) rehashPassword(identity, password string) {
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		l.Errorf("password rehash failed identity=%s err=%v", identity, err)
+		return
+	}
+	if _, err := l.svcCtx.DBEngine.Where("identity = ?", identity).Cols("password").Update(&models.UserBasic{Password: hashed}); err != nil {
+		l.Errorf("password rehash update failed identity=%s err=%v", identity, err)
+		return
+	}
+	l.Infof("password hash upgraded identity=%s", identity)
+}
+
+// twoFactorChallenge 判断是否需要两步验证，需要时创建登录挑战；无需时返回 nil。
+// 密码登录与单点登录共用，保证任何登录方式都不能绕过第二因素。
+func twoFactorChallenge(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic) (*types.LoginResponse, error) {
+	totp, has, err := loadUserTotp(svcCtx, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	setup := false
+	if !has || !totp.Enabled {
+		required, err := roleRequiresTwoFactor(svcCtx, user.Role)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		setup = true
+	}
+	token, err := createLoginChallenge(ctx, svcCtx, user.Identity, setup)
 	if err != nil {
 		return nil, err
 	}
 	return &types.LoginResponse{
-		Token: token,
-		Name:  req.Name,
+		Name:                   user.Name,
+		TwoFactorRequired:      true,
+		TwoFactorSetupRequired: setup,
+		ChallengeToken:         token,
 	}, nil
 }
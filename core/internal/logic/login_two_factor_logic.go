@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// LoginTwoFactorLogic 两步登录逻辑。
+type LoginTwoFactorLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewLoginTwoFactorLogic 创建两步登录逻辑。
+func NewLoginTwoFactorLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginTwoFactorLogic {
+	return &LoginTwoFactorLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// LoginTwoFactor 校验登录挑战的第二因素，通过后签发令牌。
+// 对强制绑定的挑战，验证通过即同时开启两步验证并返回恢复码。
+func (l *LoginTwoFactorLogic) LoginTwoFactor(req *types.LoginTwoFactorRequest) (resp *types.LoginTwoFactorResponse, err error) {
+	challenge, err := loadLoginChallenge(l.ctx, l.svcCtx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	user := new(models.UserBasic)
+	has, err := l.svcCtx.DBEngine.Where("identity = ?", challenge.Identity).Get(user)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("用户不存在")
+	}
+	// 第二因素的失败与密码失败共用账号计数，跨挑战累计，达到上限后锁定账号
+	clientIP, _ := l.ctx.Value("client_ip").(string)
+	guard := newLoginGuard(l.ctx, l.svcCtx)
+	if err := guard.check(loginSubject(user.Name, user), clientIP); err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if challenge.Setup {
+		totp, hasTotp, err := loadUserTotp(l.svcCtx, user.Identity)
+		if err != nil {
+			return nil, err
+		}
+		if !hasTotp || totp.Enabled {
+			return nil, errors.New("请先绑定认证器")
+		}
+		ok, err = verifyTOTPOnce(l.ctx, l.svcCtx, user.Identity, totp.Secret, req.Code)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ok, err = verifySecondFactor(l.ctx, l.svcCtx, user.Identity, req.Code)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		failLoginChallenge(l.ctx, l.svcCtx, req.ChallengeToken, challenge)
+		guard.recordFailure(loginSubject(user.Name, user), clientIP, user)
+		return nil, errors.New("验证码错误")
+	}
+	// 删除成功才视为本次持有者，防止同一挑战被并发重复使用
+	deleted, err := l.svcCtx.RedisClient.Del(l.ctx, "login_challenge:"+req.ChallengeToken).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, errors.New("登录挑战无效或已过期，请重新登录")
+	}
+
+	guard.reset(loginSubject(user.Name, user))
+
+	resp = &types.LoginTwoFactorResponse{Name: user.Name}
+	if challenge.Setup {
+		if resp.RecoveryCodes, err = enableTwoFactor(l.svcCtx, user.Identity); err != nil {
+			return nil, err
+		}
+	}
+	pair, err := issueTokenPair(l.ctx, l.svcCtx, utils.JwtPayLoad{
+		Id:       user.Id,
+		Identity: user.Identity,
+		Name:     user.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Token = pair.AccessToken
+	resp.RefreshToken = pair.RefreshToken
+	resp.ExpiresIn = pair.ExpiresIn
+	return resp, nil
+}
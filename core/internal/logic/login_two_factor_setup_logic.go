@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// LoginTwoFactorSetupLogic 登录时绑定两步验证逻辑。
+type LoginTwoFactorSetupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewLoginTwoFactorSetupLogic 创建登录时绑定两步验证逻辑。
+func NewLoginTwoFactorSetupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginTwoFactorSetupLogic {
+	return &LoginTwoFactorSetupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// LoginTwoFactorSetup 角色强制两步验证但尚未绑定时，凭登录挑战生成 TOTP 密钥。
+func (l *LoginTwoFactorSetupLogic) LoginTwoFactorSetup(req *types.LoginTwoFactorSetupRequest) (resp *types.TwoFactorSetupResponse, err error) {
+	challenge, err := loadLoginChallenge(l.ctx, l.svcCtx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Setup {
+		return nil, errors.New("当前登录无需绑定两步验证")
+	}
+	user := new(models.UserBasic)
+	has, err := l.svcCtx.DBEngine.Where("identity = ?", challenge.Identity).Get(user)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("用户不存在")
+	}
+	return beginTwoFactorSetup(l.svcCtx, user)
+}
@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// LogoutLogic 退出登录逻辑。
+type LogoutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewLogoutLogic 创建退出登录逻辑。
+func NewLogoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LogoutLogic {
+	return &LogoutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Logout 吊销当前访问令牌并注销当前会话，同时作废提交的刷新令牌。
+func (l *LogoutLogic) Logout(req *types.LogoutRequest) (resp *types.LogoutResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	if jti, _ := l.ctx.Value("token_id").(string); jti != "" {
+		ttl := utils.AccessTokenTTL(l.svcCtx.Config.Auth.AccessExpire)
+		if exp, ok := l.ctx.Value("token_expire").(time.Time); ok {
+			ttl = time.Until(exp)
+		}
+		if ttl > 0 {
+			if err := l.svcCtx.RedisClient.Set(l.ctx, utils.RevokedTokenKey(jti), userIdentity, ttl).Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if sid, _ := l.ctx.Value("session_id").(string); sid != "" {
+		if _, err := revokeSessions(l.ctx, l.svcCtx, userIdentity, sid); err != nil {
+			return nil, err
+		}
+	}
+
+	if token := strings.TrimSpace(req.RefreshToken); token != "" {
+		payload, err := loadRefreshToken(l.ctx, l.svcCtx, token)
+		if err == nil && payload.Identity == userIdentity {
+			if err := l.svcCtx.RedisClient.Del(l.ctx, refreshTokenKey(token)).Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &types.LogoutResponse{}, nil
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// unreadCount 查询用户未读通知数，优先读取 Redis 缓存，未命中时统计数据库并写回缓存。
+// 缓存值为 "版本:数量"，版本须与当前版本键一致才有效；版本在统计前读取，
+// 统计期间发生的变更会写入新版本，因此写回的旧值不会被后续查询采用。
+func unreadCount(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) (int64, error) {
+	key := utils.NotificationUnreadKey(userIdentity)
+	version, err := svcCtx.RedisClient.Get(ctx, utils.NotificationUnreadVersionKey(userIdentity)).Result()
+	cacheable := err == nil || errors.Is(err, redis.Nil)
+	if cacheable {
+		if val, err := svcCtx.RedisClient.Get(ctx, key).Result(); err == nil {
+			if v, count, ok := strings.Cut(val, ":"); ok && v == version {
+				if n, err := strconv.ParseInt(count, 10, 64); err == nil {
+					return n, nil
+				}
+			}
+		}
+	}
+	n, err := svcCtx.DBEngine.Where("user_identity = ? AND is_read = ?", userIdentity, false).Count(new(models.Notification))
+	if err != nil {
+		return 0, err
+	}
+	if cacheable {
+		_ = svcCtx.RedisClient.Set(ctx, key, version+":"+strconv.FormatInt(n, 10), utils.NotificationUnreadTTL).Err()
+	}
+	return n, nil
+}
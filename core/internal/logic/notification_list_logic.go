@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// NotificationListLogic 站内通知列表逻辑。
+type NotificationListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewNotificationListLogic 创建站内通知列表逻辑。
+func NewNotificationListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationListLogic {
+	return &NotificationListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationList 按时间倒序分页获取当前用户的站内通知，unread 为 true 时仅返回未读通知。
+func (l *NotificationListLogic) NotificationList(req *types.NotificationListRequest) (resp *types.NotificationListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.PageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	query := l.svcCtx.DBEngine.Where("user_identity = ?", userIdentity)
+	if req.Unread {
+		query = query.And("is_read = ?", false)
+	}
+	var notifications []models.Notification
+	cnt, err := query.Desc("id").Limit(size, (page-1)*size).FindAndCount(&notifications)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := unreadCount(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*types.NotificationItem, 0, len(notifications))
+	for i := range notifications {
+		list = append(list, mq.NotificationItem(&notifications[i]))
+	}
+	return &types.NotificationListResponse{List: list, Count: cnt, Unread: unread}, nil
+}
@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// NotificationReadAllLogic 全部通知标记已读逻辑。
+type NotificationReadAllLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewNotificationReadAllLogic 创建全部通知标记已读逻辑。
+func NewNotificationReadAllLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationReadAllLogic {
+	return &NotificationReadAllLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationReadAll 将当前用户的全部未读通知标记为已读。
+func (l *NotificationReadAllLogic) NotificationReadAll(req *types.NotificationReadAllRequest) (resp *types.NotificationReadResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	return markNotificationsRead(l.ctx, l.svcCtx, userIdentity, nil)
+}
@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// NotificationReadLogic 标记通知已读逻辑。
+type NotificationReadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewNotificationReadLogic 创建标记通知已读逻辑。
+func NewNotificationReadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationReadLogic {
+	return &NotificationReadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// notificationReadMaxBatch 单次标记已读的最大通知数。
+const notificationReadMaxBatch = 100
+
+// NotificationRead 将当前用户的指定通知标记为已读，返回本次标记数与剩余未读数。
+func (l *NotificationReadLogic) NotificationRead(req *types.NotificationReadRequest) (resp *types.NotificationReadResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	if len(req.Identities) == 0 {
+		return nil, errors.New("通知标识不能为空")
+	}
+	if len(req.Identities) > notificationReadMaxBatch {
+		return nil, errors.New("单次最多标记 100 条通知")
+	}
+	return markNotificationsRead(l.ctx, l.svcCtx, userIdentity, req.Identities)
+}
+
+// markNotificationsRead 将用户的未读通知标记为已读，identities 为空表示全部；标记后使未读数缓存失效。
+func markNotificationsRead(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string, identities []string) (*types.NotificationReadResponse, error) {
+	query := svcCtx.DBEngine.Where("user_identity = ? AND is_read = ?", userIdentity, false)
+	if len(identities) > 0 {
+		query = query.In("identity", identities)
+	}
+	cnt, err := query.Cols("is_read", "read_at").
+		Update(&models.Notification{IsRead: true, ReadAt: time.Now().Format(common.DataTimeFormat)})
+	if err != nil {
+		return nil, err
+	}
+	if cnt > 0 {
+		mq.InvalidateUnread(ctx, svcCtx, userIdentity)
+	}
+	unread, err := unreadCount(ctx, svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NotificationReadResponse{Count: cnt, Unread: unread}, nil
+}
@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/notify"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// NotificationStreamLogic 实时通知推送逻辑。
+type NotificationStreamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewNotificationStreamLogic 创建实时通知推送逻辑。
+func NewNotificationStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationStreamLogic {
+	return &NotificationStreamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationStream 订阅当前用户的实时通知，调用方负责关闭返回的订阅。
+func (l *NotificationStreamLogic) NotificationStream(req *types.NotificationStreamRequest) (*notify.Subscription, error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	return l.svcCtx.Notifier.Subscribe(userIdentity)
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// NotificationUnreadLogic 未读通知数逻辑。
+type NotificationUnreadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewNotificationUnreadLogic 创建未读通知数逻辑。
+func NewNotificationUnreadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationUnreadLogic {
+	return &NotificationUnreadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationUnread 获取当前用户的未读通知数。
+func (l *NotificationUnreadLogic) NotificationUnread(req *types.NotificationUnreadRequest) (resp *types.NotificationUnreadResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	unread, err := unreadCount(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NotificationUnreadResponse{Unread: unread}, nil
+}
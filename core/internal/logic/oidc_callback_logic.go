@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// OIDCCallbackLogic 单点登录回调逻辑。
+type OIDCCallbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewOIDCCallbackLogic 创建单点登录回调逻辑。
+func NewOIDCCallbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OIDCCallbackLogic {
+	return &OIDCCallbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// OIDCCallback 使用授权码换取并校验 ID Token，映射（必要时开通）本地用户后签发常规令牌；需要两步验证时返回登录挑战。
+func (l *OIDCCallbackLogic) OIDCCallback(req *types.OIDCCallbackRequest) (resp *types.OIDCCallbackResponse, err error) {
+	code, state := strings.TrimSpace(req.Code), strings.TrimSpace(req.State)
+	if code == "" || state == "" {
+		return nil, errors.New("授权码或 state 不能为空")
+	}
+	// state 须与发起登录的浏览器所持 Cookie 一致，防止他人的授权码被注入当前浏览器（登录 CSRF）
+	bound, _ := l.ctx.Value("oidc_state").(string)
+	if subtle.ConstantTimeCompare([]byte(bound), []byte(state)) != 1 {
+		return nil, errors.New("登录请求无效或已过期，请重新登录")
+	}
+	provider, err := oidcProvider(l.ctx, l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := takeOIDCState(l.ctx, l.svcCtx, state)
+	if err != nil {
+		return nil, err
+	}
+	c := l.svcCtx.Config.OIDC
+	rawToken, err := utils.ExchangeOIDCCode(l.ctx, provider, c.ClientId, c.ClientSecret, c.RedirectURL, code, s.Verifier)
+	if err != nil {
+		l.Errorf("oidc code exchange failed: %v", err)
+		return nil, errors.New("单点登录失败，请重试")
+	}
+	claims, err := utils.VerifyIDToken(l.ctx, provider, c.ClientId, rawToken, s.Nonce)
+	if err != nil {
+		l.Errorf("oidc id_token invalid: %v", err)
+		return nil, errors.New("单点登录失败，请重试")
+	}
+	user, err := resolveOIDCUser(l.svcCtx, provider.Issuer, claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errDisabledAccount
+	}
+	// 与密码登录相同：已开启两步验证或角色强制要求时返回登录挑战，由 /login/2fa 完成登录
+	challenge, err := twoFactorChallenge(l.ctx, l.svcCtx, user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &types.OIDCCallbackResponse{
+			Name:                   challenge.Name,
+			TwoFactorRequired:      challenge.TwoFactorRequired,
+			TwoFactorSetupRequired: challenge.TwoFactorSetupRequired,
+			ChallengeToken:         challenge.ChallengeToken,
+		}, nil
+	}
+	pair, err := issueTokenPair(l.ctx, l.svcCtx, utils.JwtPayLoad{
+		Id:       user.Id,
+		Identity: user.Identity,
+		Name:     user.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.OIDCCallbackResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		Name:         user.Name,
+	}, nil
+}
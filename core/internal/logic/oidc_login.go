@@ -0,0 +1,241 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// oidcStateTTL 单点登录授权请求（state）的有效期。
+const oidcStateTTL = 10 * time.Minute
+
+// maxOIDCNameAttempts 自动开通账号时生成不重复用户名的最大尝试次数。
+const maxOIDCNameAttempts = 20
+
+// defaultOIDCScopes 默认申请的权限范围。
+var defaultOIDCScopes = []string{"openid", "profile", "email"}
+
+// oidcState 授权请求期间保存的 PKCE 与 nonce。
+type oidcState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// oidcProvider 读取单点登录配置并获取身份提供方元数据，未配置时返回错误。
+func oidcProvider(ctx context.Context, svcCtx *svc.ServiceContext) (*utils.OIDCProvider, error) {
+	c := svcCtx.Config.OIDC
+	if c.Issuer == "" || c.ClientId == "" || c.RedirectURL == "" {
+		return nil, errors.New("未启用单点登录")
+	}
+	return utils.DiscoverOIDC(ctx, c.Issuer)
+}
+
+// oidcScopes 返回申请的权限范围，保证包含 openid。
+func oidcScopes(svcCtx *svc.ServiceContext) []string {
+	scopes := svcCtx.Config.OIDC.Scopes
+	if len(scopes) == 0 {
+		return defaultOIDCScopes
+	}
+	for _, s := range scopes {
+		if s == "openid" {
+			return scopes
+		}
+	}
+	return append([]string{"openid"}, scopes...)
+}
+
+// oidcStateKey 返回授权请求状态在 Redis 中的键。
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
+
+// takeOIDCState 读取并删除授权请求状态，保证 state 只能使用一次。
+func takeOIDCState(ctx context.Context, svcCtx *svc.ServiceContext, state string) (*oidcState, error) {
+	val, err := svcCtx.RedisClient.Get(ctx, oidcStateKey(state)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, errors.New("登录请求无效或已过期，请重新登录")
+	}
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := svcCtx.RedisClient.Del(ctx, oidcStateKey(state)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, errors.New("登录请求无效或已过期，请重新登录")
+	}
+	s := new(oidcState)
+	if err := json.Unmarshal([]byte(val), s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resolveOIDCUser 将身份提供方账号映射到本地用户：优先按 issuer+sub 绑定关系查找，
+// 其次在配置允许时按已验证邮箱关联已有的非特权用户，都没有时自动开通新用户。
+func resolveOIDCUser(svcCtx *svc.ServiceContext, issuer string, claims *utils.OIDCClaims) (*models.UserBasic, error) {
+	link := new(models.UserExternalIdentity)
+	has, err := svcCtx.DBEngine.Where("issuer = ? AND subject = ?", issuer, claims.Subject).Get(link)
+	if err != nil {
+		return nil, err
+	}
+	user := new(models.UserBasic)
+	if has {
+		has, err = svcCtx.DBEngine.Where("identity = ?", link.UserIdentity).Get(user)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, errors.New("绑定的用户不存在")
+		}
+		return user, nil
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if !oidcDomainAllowed(svcCtx.Config.OIDC.AllowedDomains, email, claims.EmailVerified) {
+		return nil, errors.New("该账号不允许通过单点登录访问")
+	}
+	has = false
+	if email != "" {
+		if has, err = svcCtx.DBEngine.Where("email = ?", email).Get(user); err != nil {
+			return nil, err
+		}
+	}
+	if has {
+		// 邮箱已被本地账号占用：仅在显式开启且邮箱已验证时关联，特权账号一律不自动关联，避免被身份提供方接管
+		if !svcCtx.Config.OIDC.LinkVerifiedEmail || !claims.EmailVerified {
+			return nil, errors.New("该邮箱已绑定本地账号，请使用原有方式登录")
+		}
+		privileged, err := rolePrivileged(svcCtx, user.Role)
+		if err != nil {
+			return nil, err
+		}
+		if privileged {
+			return nil, errors.New("特权账号不支持通过单点登录自动关联")
+		}
+	} else {
+		if user, err = provisionOIDCUser(svcCtx, claims, email); err != nil {
+			return nil, err
+		}
+	}
+	link = &models.UserExternalIdentity{
+		Identity:     utils.UUID(),
+		UserIdentity: user.Identity,
+		Issuer:       issuer,
+		Subject:      claims.Subject,
+		Email:        email,
+	}
+	if _, err := svcCtx.DBEngine.Insert(link); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// rolePrivileged 判断角色是否为管理员或拥有任意管理权限。
+func rolePrivileged(svcCtx *svc.ServiceContext, role string) (bool, error) {
+	if role == common.RoleAdmin {
+		return true, nil
+	}
+	if role == "" {
+		return false, nil
+	}
+	cnt, err := svcCtx.DBEngine.Where("role = ?", role).Count(new(models.RolePermission))
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+// oidcDomainAllowed 校验邮箱域名是否在允许列表中，未配置列表时不限制。
+func oidcDomainAllowed(domains []string, email string, verified bool) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if !verified || at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range domains {
+		if strings.EqualFold(strings.TrimSpace(d), domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// provisionOIDCUser 首次单点登录时开通本地账号，本地密码为随机值（仅可通过单点登录或重置密码使用）。
+func provisionOIDCUser(svcCtx *svc.ServiceContext, claims *utils.OIDCClaims, email string) (*models.UserBasic, error) {
+	base := strings.TrimSpace(claims.PreferredUsername)
+	if base == "" && email != "" {
+		base = email[:strings.LastIndex(email, "@")]
+	}
+	if base == "" {
+		base = strings.TrimSpace(claims.Name)
+	}
+	if base == "" {
+		base = "user"
+	}
+	name, err := uniqueUserName(svcCtx, base)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := utils.Base62Code(32)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := utils.HashPassword(secret)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.UserBasic{
+		Identity: utils.UUID(),
+		Name:     name,
+		Password: hashed,
+		Email:    email,
+	}
+	// 目录或身份提供方未返回邮箱时写入 NULL，不占用邮箱唯一索引
+	if _, err := svcCtx.DBEngine.Nullable("email").InsertOne(user); err != nil {
+		if utils.IsDuplicateKeyError(err) {
+			return nil, errEmailTaken
+		}
+		return nil, err
+	}
+	if user.Id == 0 {
+		if _, err := svcCtx.DBEngine.Where("identity = ?", user.Identity).Get(user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// uniqueUserName 生成不与已有用户冲突的用户名，如 "alice"、"alice2"。
+func uniqueUserName(svcCtx *svc.ServiceContext, base string) (string, error) {
+	candidate := base
+	for i := 2; i <= maxOIDCNameAttempts; i++ {
+		cnt, err := svcCtx.DBEngine.Unscoped().Where("name = ?", candidate).Count(new(models.UserBasic))
+		if err != nil {
+			return "", err
+		}
+		if cnt == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	suffix, err := utils.Base62Code(6)
+	if err != nil {
+		return "", err
+	}
+	return base + "_" + suffix, nil
+}
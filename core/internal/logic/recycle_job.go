@@ -2,10 +2,14 @@ package logic
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
 	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
 	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
 
@@ -23,17 +27,91 @@ func StartRecycleJob(ctx context.Context, svcCtx *svc.ServiceContext) {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
+				warnUpcomingPurges(ctx, svcCtx)
 				purgeExpired(ctx, svcCtx)
 			}
 		}
 	}()
 }
 
+// trashPurgeNoticeWindow 回收站项目距离永久删除不足该时长时提醒用户。
+const trashPurgeNoticeWindow = 3 * 24 * time.Hour
+
+// trashPurgeNoticeNames 清理提醒中列出的项目名称数上限。
+const trashPurgeNoticeNames = 5
+
+// warnUpcomingPurges 提醒用户回收站中即将被永久删除的项目，按用户与清理日期汇总，同一日期只提醒一次。
+// 只统计最上层的删除项目，随文件夹一起删除的子项不重复计数。
+func warnUpcomingPurges(ctx context.Context, svcCtx *svc.ServiceContext) {
+	now := time.Now()
+	var items []models.UserRepository
+	err := svcCtx.DBEngine.Unscoped().Table("user_repository").
+		Where("status = ? AND expire_at != '' AND expire_at > ? AND expire_at <= ?", common.StatusDeleted,
+			now.Format(common.DataTimeFormat), now.Add(trashPurgeNoticeWindow).Format(common.DataTimeFormat)).
+		Cols("id", "user_identity", "parent_id", "name", "expire_at").
+		Asc("expire_at").
+		Find(&items)
+	if err != nil {
+		logx.Errorf("purge notice list failed: %v", err)
+		return
+	}
+	deleted := make(map[int64]struct{}, len(items))
+	for _, item := range items {
+		deleted[item.Id] = struct{}{}
+	}
+	type batch struct{ user, date string }
+	notices := map[batch]*types.TrashPurgeNotice{}
+	var order []batch
+	for _, item := range items {
+		if _, ok := deleted[item.ParentId]; ok {
+			continue
+		}
+		expireAt, err := time.ParseInLocation(common.DataTimeFormat, item.ExpireAt, time.Local)
+		if err != nil {
+			continue
+		}
+		key := batch{item.UserIdentity, expireAt.Format(auditDateFormat)}
+		notice, ok := notices[key]
+		if !ok {
+			notice = &types.TrashPurgeNotice{Date: key.date}
+			notices[key] = notice
+			order = append(order, key)
+		}
+		notice.Count++
+		if len(notice.Names) < trashPurgeNoticeNames {
+			notice.Names = append(notice.Names, item.Name)
+		}
+	}
+	for _, key := range order {
+		// 群组空间的项目通知有权恢复的成员，而不是群组标识本身
+		recipients, groupName, err := spaceRecipients(svcCtx, key.user)
+		if err != nil {
+			logx.Errorf("purge notice recipients failed space=%s err=%v", key.user, err)
+			continue
+		}
+		ok, err := svcCtx.RedisClient.SetNX(ctx, utils.TrashPurgeNoticeKey(key.user, key.date), "1", trashPurgeNoticeWindow+24*time.Hour).Result()
+		if err != nil || !ok {
+			continue
+		}
+		notice := notices[key]
+		trash := "回收站"
+		if groupName != "" {
+			notice.SpaceIdentity, notice.GroupName = key.user, groupName
+			trash = fmt.Sprintf("群组「%s」回收站", groupName)
+		}
+		content := fmt.Sprintf("%s中的 %d 个项目（%s 等）将于 %s 被永久删除，如需保留请尽快恢复。", trash, notice.Count, strings.Join(notice.Names, "、"), notice.Date)
+		for _, user := range recipients {
+			mq.AddNotification(ctx, svcCtx, user, common.InboxTrashPurge, "回收站即将清理", content, notice)
+		}
+	}
+}
+
 // purgeExpired 清理过期删除的文件。
 func purgeExpired(ctx context.Context, svcCtx *svc.ServiceContext) {
 	now := time.Now().Format(common.DataTimeFormat)
 	var expired []models.UserRepository
-	err := svcCtx.DBEngine.Table("user_repository").
+	// 回收站中的记录已设置 deleted_at，需要绕过软删除过滤
+	err := svcCtx.DBEngine.Unscoped().Table("user_repository").
 		Where("status = ? AND expire_at != '' AND expire_at <= ?", common.StatusDeleted, now).
 		Find(&expired)
 	if err != nil {
@@ -61,7 +139,7 @@ func purgeExpired(ctx context.Context, svcCtx *svc.ServiceContext) {
 			continue
 		}
 		repo := new(models.RepositoryPool)
-		has, err := svcCtx.DBEngine.Where("identity = ?", repoID).Get(repo)
+		has, err := svcCtx.DBEngine.Unscoped().Where("identity = ?", repoID).Get(repo)
 		if err != nil || !has {
 			continue
 		}
@@ -75,19 +153,30 @@ func purgeExpired(ctx context.Context, svcCtx *svc.ServiceContext) {
 		if objectKey != "" {
 			if err := utils.DeleteOSSObject(ctx, objectKey); err != nil {
 				logx.Errorf("oss delete failed: %v", err)
+				utils.ObserveRecyclePurge(err)
 				continue
 			}
 		}
 		_, _ = svcCtx.DBEngine.Table("repository_pool").
 			Where("identity = ?", repoID).
 			Update(map[string]any{"status": common.StatusPurged, "deleted_at": now})
+		sizes, err := utils.UserFileSizes(svcCtx.DBEngine, "ur.repository_identity = ? AND ur.status = ? AND ur.expire_at != '' AND ur.expire_at <= ?",
+			repoID, common.StatusDeleted, now)
+		if err != nil {
+			logx.Errorf("purge usage query failed: %v", err)
+		}
 		_, _ = svcCtx.DBEngine.Table("user_repository").
 			Where("repository_identity = ? AND status = ? AND expire_at != '' AND expire_at <= ?", repoID, common.StatusDeleted, now).
 			Update(map[string]any{"status": common.StatusPurged})
-		_, _ = svcCtx.DBEngine.Insert(&models.FileEventLog{
-			Identity:           utils.UUID(),
+		for userIdentity, size := range sizes {
+			if err := utils.AddUsage(svcCtx.DBEngine, userIdentity, 0, -size); err != nil {
+				logx.Errorf("purge usage update failed user=%s err=%v", userIdentity, err)
+			}
+		}
+		_ = utils.RecordFileEvent(svcCtx.DBEngine, &models.FileEventLog{
 			RepositoryIdentity: repoID,
 			EventType:          common.EventPurge,
 		})
+		utils.ObserveRecyclePurge(nil)
 	}
 }
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RefreshTokenLogic 刷新令牌逻辑。
+type RefreshTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRefreshTokenLogic 创建刷新令牌逻辑。
+func NewRefreshTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RefreshTokenLogic {
+	return &RefreshTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌对，旧刷新令牌随即作废（轮换）。
+func (l *RefreshTokenLogic) RefreshToken(req *types.RefreshTokenRequest) (resp *types.RefreshTokenResponse, err error) {
+	token := strings.TrimSpace(req.RefreshToken)
+	if token == "" {
+		return nil, errors.New("刷新令牌不能为空")
+	}
+	payload, err := loadRefreshToken(l.ctx, l.svcCtx, token)
+	if err != nil {
+		return nil, err
+	}
+	if payload.SessionId != "" {
+		if err := renewSession(l.ctx, l.svcCtx, payload.Identity, payload.SessionId); err != nil {
+			return nil, err
+		}
+	}
+	// 删除成功才视为本次持有者，防止同一刷新令牌被并发重复使用
+	deleted, err := l.svcCtx.RedisClient.Del(l.ctx, refreshTokenKey(token)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, errors.New("刷新令牌无效或已过期")
+	}
+	// 与登录一致校验账号状态，禁用前签发的刷新令牌在此作废
+	if err := ensureUserEnabled(l.svcCtx, payload.Identity); err != nil {
+		return nil, err
+	}
+
+	pair, err := issueTokenPair(l.ctx, l.svcCtx, *payload)
+	if err != nil {
+		return nil, err
+	}
+	return &types.RefreshTokenResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+	}, nil
+}
@@ -4,6 +4,7 @@
 package logic
 
 import (
+	"cloud_disk/core/common"
 	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
 	"context"
@@ -11,7 +12,6 @@ import (
 
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
-	"fmt"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -34,40 +34,39 @@ func NewRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Register
 
 // Register 执行注册。
 func (l *RegisterLogic) Register(req *types.RegisterRequest) (resp *types.RegisterResponse, err error) {
+	email := normalizeEmail(req.Email)
 	user := new(models.UserBasic)
 	// 先根据用户名在数据库中查找用户是否存在
-	has, err := l.svcCtx.DBEngine.Where("name = ? or email = ?", req.Name, req.Email).Get(user)
+	has, err := l.svcCtx.DBEngine.Where("name = ? or email = ?", req.Name, email).Get(user)
 	if err != nil {
 		return nil, err
 	}
 	if has {
 		return nil, errors.New("用户已存在")
 	}
-	// 查询验证码
-	var code string
-	err = l.svcCtx.RedisClient.Get(l.ctx, fmt.Sprintf("verification_code:%s", req.Email)).Scan(&code)
-	if err != nil {
-		logx.Errorf("查询验证码失败: %v", err)
+	// 校验并销毁注册验证码，验证码正确后创建用户
+	if err := consumeVerificationCode(l.ctx, l.svcCtx, common.CodePurposeRegister, email, req.Code); err != nil {
 		return nil, err
 	}
-	if code == "" {
-		return nil, errors.New("验证码已过期或无效")
-	}
-	// 验证码正确，创建用户
-	if req.Code != code {
-		return nil, errors.New("验证码错误")
-	}
 	uuid := utils.UUID()
 
+	hashed, err := utils.HashPassword(utils.DecodeMaybeBase64(req.Password))
+	if err != nil {
+		return nil, err
+	}
 	// 创建用户模型对象
-    userModel := &models.UserBasic{
-        Name:     req.Name,
-        Password: utils.Md5(utils.DecodeMaybeBase64(req.Password)),
-        Email:    req.Email,
-        Identity: uuid,
-    }
+	userModel := &models.UserBasic{
+		Name:     req.Name,
+		Password: hashed,
+		Email:    email,
+		Identity: uuid,
+		Language: utils.MailLang(requestLanguage(l.ctx)),
+	}
 	// 插入数据库
 	affected, err := l.svcCtx.DBEngine.InsertOne(userModel)
+	if utils.IsDuplicateKeyError(err) {
+		return nil, errors.New("用户已存在")
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -77,17 +76,19 @@ func (l *RegisterLogic) Register(req *types.RegisterRequest) (resp *types.Regist
 
 	// 直接使用插入的用户数据生成token，无需再次查询
 	// xorm 的 InsertOne 会自动将自增 ID 填充到 userModel.Id 中
-	token, err := utils.GenToken(utils.JwtPayLoad{
+	pair, err := issueTokenPair(l.ctx, l.svcCtx, utils.JwtPayLoad{
 		Id:       userModel.Id,
 		Identity: userModel.Identity,
 		Name:     userModel.Name,
-	}, l.svcCtx.Config.Auth.AccessSecret, l.svcCtx.Config.Auth.AccessExpire)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &types.RegisterResponse{
-		Token: token,
-		Name:  req.Name,
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		Name:         req.Name,
 	}, nil
 
 }
@@ -1,13 +1,13 @@
 package logic
 
 import (
+	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
 	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -36,18 +36,9 @@ func (l *ResetPasswordLogic) ResetPassword(req *types.ResetPasswordRequest) (res
 		return nil, err
 	}
 
-	var cached string
-	err = l.svcCtx.RedisClient.Get(l.ctx, fmt.Sprintf("verification_code:%s", email)).Scan(&cached)
-	if err != nil {
-		logx.Errorf("password reset query code failed email=%s err=%v", email, err)
+	if err := consumeVerificationCode(l.ctx, l.svcCtx, common.CodePurposeReset, email, code); err != nil {
 		return nil, err
 	}
-	if cached == "" {
-		return nil, errors.New("验证码已过期或无效")
-	}
-	if cached != code {
-		return nil, errors.New("验证码错误")
-	}
 
 	user := new(models.UserBasic)
 	has, err := l.svcCtx.DBEngine.Where("email = ?", email).Get(user)
@@ -59,7 +50,11 @@ func (l *ResetPasswordLogic) ResetPassword(req *types.ResetPasswordRequest) (res
 		return nil, errors.New("用户不存在")
 	}
 
-    update := &models.UserBasic{Password: utils.Md5(utils.DecodeMaybeBase64(newPassword))}
+	hashed, err := utils.HashPassword(utils.DecodeMaybeBase64(newPassword))
+	if err != nil {
+		return nil, err
+	}
+	update := &models.UserBasic{Password: hashed}
 	affected, err := l.svcCtx.DBEngine.Where("email = ?", email).Cols("password").Update(update)
 	if err != nil {
 		logx.Severef("password reset update failed email=%s err=%v", email, err)
@@ -69,15 +64,18 @@ func (l *ResetPasswordLogic) ResetPassword(req *types.ResetPasswordRequest) (res
 		return nil, errors.New("更新失败")
 	}
 
-	_, _ = l.svcCtx.RedisClient.Del(l.ctx, fmt.Sprintf("verification_code:%s", email)).Result()
+	revoked, err := revokeUserSessions(l.ctx, l.svcCtx, user.Identity)
+	if err != nil {
+		return nil, err
+	}
 
-	logx.Infof("password reset success email=%s", email)
+	logx.Infof("password reset success email=%s sessions_revoked=%d", email, revoked)
 	return &types.ResetPasswordResponse{Message: "密码重置成功"}, nil
 }
 
 // normalizeResetPasswordInput 标准化并校验重置密码参数。
 func normalizeResetPasswordInput(req *types.ResetPasswordRequest) (string, string, string, error) {
-	email := strings.TrimSpace(req.Email)
+	email := normalizeEmail(req.Email)
 	code := strings.TrimSpace(req.Code)
 	newPassword := strings.TrimSpace(req.NewPassword)
 	if email == "" || code == "" || newPassword == "" {
@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SaveJobLogic 转存任务进度查询逻辑。
+type SaveJobLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSaveJobLogic 创建转存任务进度查询逻辑。
+func NewSaveJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveJobLogic {
+	return &SaveJobLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SaveJob 查询转存任务进度。
+func (l *SaveJobLogic) SaveJob(req *types.SaveJobRequest) (resp *types.SaveJobResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok || userIdentity == "" {
+		return nil, errors.New("用户身份验证失败")
+	}
+	if req.JobId == "" {
+		return nil, errors.New("任务 ID 不能为空")
+	}
+	notFound := errors.New("任务不存在或已过期")
+	val, err := l.svcCtx.RedisClient.Get(l.ctx, "save_job:"+req.JobId).Result()
+	if err == redis.Nil {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := new(saveJobState)
+	if err := json.Unmarshal([]byte(val), state); err != nil {
+		return nil, err
+	}
+	// 他人的任务按不存在处理，避免泄露任务信息
+	if state.Owner != userIdentity {
+		return nil, notFound
+	}
+	return &types.SaveJobResponse{
+		JobId:    req.JobId,
+		Status:   state.Status,
+		Total:    state.Total,
+		Done:     state.Done,
+		Identity: state.Identity,
+		Error:    state.Error,
+	}, nil
+}
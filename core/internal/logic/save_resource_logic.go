@@ -5,8 +5,10 @@ package logic
 
 import (
 	"context"
+	"fmt"
 
 	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
 	"cloud_disk/core/models"
@@ -54,6 +56,10 @@ func (l *SaveResourceLogic) SaveResource(req *types.SaveResourceRequest) (resp *
 	if !has {
 		return nil, errors.New("资源不存在")
 	}
+	// 转存的文件计入当前用户的存储空间，写入前原子预占
+	if err := utils.ReserveQuota(l.svcCtx.DBEngine, userIdentity, repo.Size, 0); err != nil {
+		return nil, err
+	}
 
 	// 创造结构体并存入
 	data := models.UserRepository{
@@ -67,8 +73,11 @@ func (l *SaveResourceLogic) SaveResource(req *types.SaveResourceRequest) (resp *
 	}
 	_, err = l.svcCtx.DBEngine.Insert(&data)
 	if err != nil {
+		utils.ReleaseQuota(l.svcCtx.DBEngine, userIdentity, repo.Size, 0)
 		return nil, err
 	}
+	mq.NotifyQuota(l.ctx, l.svcCtx, userIdentity, repo.Size)
+	recordFileEvent(l.ctx, l.svcCtx, common.EventSave, userIdentity, req.ParentId, repo.Identity, fmt.Sprintf("name=%s parent_id=%d", req.Name, req.ParentId))
 	return &types.SaveResourceResponse{
 		Identity: data.Identity,
 	}, nil
@@ -0,0 +1,324 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"xorm.io/xorm"
+)
+
+// saveJobTTL 转存任务状态在 Redis 中的保留时长。
+const saveJobTTL = 24 * time.Hour
+
+// maxUniqueNameAttempts 自动重命名的最大尝试次数。
+const maxUniqueNameAttempts = 1000
+
+// SaveShareFolderLogic 转存分享文件夹逻辑。
+type SaveShareFolderLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSaveShareFolderLogic 创建转存分享文件夹逻辑。
+func NewSaveShareFolderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveShareFolderLogic {
+	return &SaveShareFolderLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// saveJobState 转存任务状态，Owner 为发起用户，仅本人可查询。
+type saveJobState struct {
+	Owner    string `json:"owner"`
+	Status   string `json:"status"`
+	Total    int    `json:"total"`
+	Done     int    `json:"done"`
+	Identity string `json:"identity"`
+	Error    string `json:"error"`
+}
+
+// SaveShareFolder 将分享的文件夹整棵子树以引用方式转存到当前用户目录下。
+func (l *SaveShareFolderLogic) SaveShareFolder(req *types.SaveShareFolderRequest) (resp *types.SaveShareFolderResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	if req.ShareIdentity == "" {
+		return nil, errors.New("分享标识不能为空")
+	}
+	share, has, err := loadShare(l.svcCtx, req.ShareIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("分享不存在")
+	}
+	expired, err := isShareExpired(share, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, errors.New("分享已过期")
+	}
+	perm, err := shareGrantPermission(l.svcCtx, share, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if !permissionAllows(perm, common.SharePermissionDownload) {
+		return nil, errors.New("无权转存该分享")
+	}
+	if share.UserRepositoryIdentity == "" {
+		return nil, errors.New("该分享不是文件夹分享")
+	}
+
+	root := new(models.UserRepository)
+	has, err = l.svcCtx.DBEngine.
+		Where("identity = ? AND (status != ? OR status IS NULL)", share.UserRepositoryIdentity, common.StatusDeleted).
+		Get(root)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("分享的文件夹不存在")
+	}
+	if req.ParentId != 0 {
+		cnt, err := l.svcCtx.DBEngine.
+			Where("id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.ParentId, userIdentity, common.StatusDeleted).
+			Count(new(models.UserRepository))
+		if err != nil {
+			return nil, err
+		}
+		if cnt == 0 {
+			return nil, errors.New("目标文件夹不存在")
+		}
+	}
+
+	nodes, err := collectSubtree(l.svcCtx.DBEngine, root)
+	if err != nil {
+		return nil, err
+	}
+	size, err := subtreeSize(l.svcCtx.DBEngine, nodes)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ReserveQuota(l.svcCtx.DBEngine, userIdentity, size, 0); err != nil {
+		return nil, err
+	}
+
+	recordFileEvent(l.ctx, l.svcCtx, common.EventSave, userIdentity, req.ParentId, "",
+		fmt.Sprintf("share=%s name=%s items=%d parent_id=%d", share.Identity, root.Name, len(nodes), req.ParentId))
+	if len(nodes) <= utils.SaveFolderSyncLimit() {
+		identity, err := copySubtree(l.svcCtx.DBEngine, nodes, userIdentity, req.ParentId, nil)
+		if err != nil {
+			utils.ReleaseQuota(l.svcCtx.DBEngine, userIdentity, size, 0)
+			return nil, err
+		}
+		mq.NotifyQuota(l.ctx, l.svcCtx, userIdentity, size)
+		return &types.SaveShareFolderResponse{Identity: identity, Status: common.JobStatusDone}, nil
+	}
+
+	jobId := utils.UUID()
+	state := &saveJobState{Owner: userIdentity, Status: common.JobStatusPending, Total: len(nodes)}
+	if err := putSaveJob(l.ctx, l.svcCtx, jobId, state); err != nil {
+		utils.ReleaseQuota(l.svcCtx.DBEngine, userIdentity, size, 0)
+		return nil, err
+	}
+	go runSaveJob(l.svcCtx, jobId, nodes, size, userIdentity, req.ParentId)
+	return &types.SaveShareFolderResponse{JobId: jobId, Status: common.JobStatusPending}, nil
+}
+
+// runSaveJob 后台执行转存任务，持续更新进度并实时通知发起用户。
+func runSaveJob(svcCtx *svc.ServiceContext, jobId string, nodes []models.UserRepository, size int64, userIdentity string, parentId int64) {
+	ctx := context.Background()
+	state := &saveJobState{Owner: userIdentity, Status: common.JobStatusRunning, Total: len(nodes)}
+	report := func() {
+		_ = putSaveJob(ctx, svcCtx, jobId, state)
+		mq.Notify(ctx, svcCtx, userIdentity, common.NotifyJobProgress, &types.JobNotice{
+			JobIdentity: jobId,
+			Status:      state.Status,
+			Total:       state.Total,
+			Done:        state.Done,
+			Error:       state.Error,
+		})
+	}
+	report()
+	identity, err := copySubtree(svcCtx.DBEngine, nodes, userIdentity, parentId, func(done int) {
+		if done%100 == 0 {
+			state.Done = done
+			report()
+		}
+	})
+	if err != nil {
+		logx.Errorf("save folder job failed job=%s err=%v", jobId, err)
+		state.Status = common.JobStatusFailed
+		state.Error = err.Error()
+		// 复制在事务中进行，失败时没有写入任何记录，释放预占的空间
+		utils.ReleaseQuota(svcCtx.DBEngine, userIdentity, size, 0)
+	} else {
+		state.Status = common.JobStatusDone
+		state.Done = len(nodes)
+		state.Identity = identity
+		mq.NotifyQuota(ctx, svcCtx, userIdentity, size)
+	}
+	report()
+}
+
+// putSaveJob 写入转存任务状态。
+func putSaveJob(ctx context.Context, svcCtx *svc.ServiceContext, jobId string, state *saveJobState) error {
+	body, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return svcCtx.RedisClient.Set(ctx, "save_job:"+jobId, string(body), saveJobTTL).Err()
+}
+
+// errSubtreeTooDeep 文件夹层级超过 maxShareAncestorDepth 时返回，避免只转存部分子树。
+var errSubtreeTooDeep = fmt.Errorf("文件夹层级超过 %d 层，无法转存", maxShareAncestorDepth)
+
+// collectSubtree 按层级广度优先收集子树节点，保证父节点排在子节点之前；层级过深时返回错误。
+func collectSubtree(eng *xorm.Engine, root *models.UserRepository) ([]models.UserRepository, error) {
+	nodes := []models.UserRepository{*root}
+	level := []int64{root.Id}
+	for depth := 0; len(level) > 0; depth++ {
+		if depth >= maxShareAncestorDepth {
+			return nil, errSubtreeTooDeep
+		}
+		var children []models.UserRepository
+		err := eng.In("parent_id", level).
+			Where("user_identity = ? AND (status != ? OR status IS NULL)", root.UserIdentity, common.StatusDeleted).
+			Asc("id").
+			Find(&children)
+		if err != nil {
+			return nil, err
+		}
+		level = level[:0]
+		for _, child := range children {
+			nodes = append(nodes, child)
+			if child.RepositoryIdentity == "" {
+				level = append(level, child.Id)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// subtreeSize 统计子树中文件的总大小。
+func subtreeSize(eng *xorm.Engine, nodes []models.UserRepository) (int64, error) {
+	counts := map[string]int{}
+	for _, node := range nodes {
+		if node.RepositoryIdentity != "" {
+			counts[node.RepositoryIdentity]++
+		}
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	var repos []models.RepositoryPool
+	if err := eng.In("identity", ids).Cols("identity", "size").Find(&repos); err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, repo := range repos {
+		total += repo.Size * int64(counts[repo.Identity])
+	}
+	return total, nil
+}
+
+// copySubtree 在事务中为目标用户重建子树，文件仅复制引用；返回新根节点标识。
+func copySubtree(eng *xorm.Engine, nodes []models.UserRepository, userIdentity string, parentId int64, progress func(done int)) (string, error) {
+	if len(nodes) == 0 {
+		return "", errors.New("分享的文件夹不存在")
+	}
+	session := eng.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return "", err
+	}
+
+	rootName, err := uniqueChildName(session, userIdentity, parentId, nodes[0].Name, nodes[0].RepositoryIdentity == "")
+	if err != nil {
+		_ = session.Rollback()
+		return "", err
+	}
+	idMap := map[int64]int64{}
+	rootIdentity := ""
+	for i, node := range nodes {
+		data := &models.UserRepository{
+			Identity:           utils.UUID(),
+			UserIdentity:       userIdentity,
+			RepositoryIdentity: node.RepositoryIdentity,
+			Ext:                node.Ext,
+			Name:               node.Name,
+			Status:             common.StatusActive,
+		}
+		if i == 0 {
+			data.ParentId = parentId
+			data.Name = rootName
+			rootIdentity = data.Identity
+		} else {
+			data.ParentId = idMap[node.ParentId]
+		}
+		if _, err := session.Insert(data); err != nil {
+			_ = session.Rollback()
+			return "", err
+		}
+		if node.RepositoryIdentity == "" {
+			if data.Id == 0 {
+				if _, err := session.Where("identity = ?", data.Identity).Get(data); err != nil {
+					_ = session.Rollback()
+					return "", err
+				}
+			}
+			idMap[node.Id] = data.Id
+		}
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+	if err := session.Commit(); err != nil {
+		return "", err
+	}
+	return rootIdentity, nil
+}
+
+// uniqueChildName 在目标目录下生成不冲突的名称，如 "报告 (1).pdf"。
+func uniqueChildName(session *xorm.Session, userIdentity string, parentId int64, name string, isDir bool) (string, error) {
+	ext := ""
+	if !isDir {
+		ext = path.Ext(name)
+	}
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; i <= maxUniqueNameAttempts; i++ {
+		cnt, err := session.Table("user_repository").
+			Where("name = ? AND parent_id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", candidate, parentId, userIdentity, common.StatusDeleted).
+			Count(new(models.UserRepository))
+		if err != nil {
+			return "", err
+		}
+		if cnt == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	return "", errors.New("同名文件过多，无法自动重命名")
+}
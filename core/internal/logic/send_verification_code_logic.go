@@ -5,14 +5,12 @@ package logic
 
 import (
 	"context"
-	"crypto/rand"
-	"fmt"
-	"math/big"
+	"errors"
 
+	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
-	"cloud_disk/core/utils"
-	"time"
+	"cloud_disk/core/models"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -33,33 +31,47 @@ func NewSendVerificationCodeLogic(ctx context.Context, svcCtx *svc.ServiceContex
 	}
 }
 
-// SendVerificationCode 发送验证码。
+// SendVerificationCode 发送指定用途（默认 register）的验证码，受邮箱冷却与邮箱、IP 发送频率限制。
 func (l *SendVerificationCodeLogic) SendVerificationCode(req *types.SendVerificationCodeRequest) (resp *types.SendVerificationCodeResponse, err error) {
-	// 生成随机 6 位数验证码
-	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
-	if err != nil {
+	email := normalizeEmail(req.Email)
+	if email == "" {
+		return nil, errors.New("邮箱不能为空")
+	}
+	// 兼容未传用途的旧客户端，默认为注册验证码
+	if req.Purpose == "" {
+		req.Purpose = common.CodePurposeRegister
+	}
+	if !verificationPurposes[req.Purpose] {
+		return nil, errors.New("验证码用途不合法")
+	}
+	clientIP, _ := l.ctx.Value("client_ip").(string)
+	if err := checkVerificationSendLimit(l.ctx, l.svcCtx, email, clientIP); err != nil {
 		return nil, err
 	}
-	code := fmt.Sprintf("%06d", n.Int64())
+	resp = &types.SendVerificationCodeResponse{Message: "验证码已发送"}
 
-	if !utils.EmailEnabled() {
-		logx.Infof("邮箱发送已禁用，验证码: %s", code)
-	} else {
-		go func() {
-			sendErr := utils.SendEmail(req.Email, code)
-			if sendErr != nil {
-				logx.Errorf("发送验证码邮件失败: %v", sendErr)
-			}
-		}()
+	// 重置密码时不存在的邮箱不发送，但返回相同结果，避免探测已注册邮箱
+	if req.Purpose == common.CodePurposeReset {
+		has, err := l.svcCtx.DBEngine.Where("email = ?", email).Exist(new(models.UserBasic))
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return resp, nil
+		}
 	}
-	// 向 Redis 中存储验证码
-	err = l.svcCtx.RedisClient.Set(l.ctx, fmt.Sprintf("verification_code:%s", req.Email), code, 5*time.Minute).Err()
+
+	code, err := issueVerificationCode(l.ctx, l.svcCtx, req.Purpose, email)
 	if err != nil {
-		logx.Errorf("向 Redis 存储验证码失败: %v", err)
+		l.Errorf("向 Redis 存储验证码失败: %v", err)
+		releaseVerificationCooldown(l.ctx, l.svcCtx, email)
 		return nil, err
 	}
-
-	return &types.SendVerificationCodeResponse{
-		Message: "验证码已发送",
-	}, nil
+	if err := sendVerificationEmail(l.ctx, l.svcCtx, req.Purpose, email, code); err != nil {
+		l.Errorf("发送验证码邮件失败 email=%s err=%v", email, err)
+		_ = l.svcCtx.RedisClient.Del(l.ctx, verificationCodeKey(req.Purpose, email)).Err()
+		releaseVerificationCooldown(l.ctx, l.svcCtx, email)
+		return nil, errors.New("验证码发送失败，请稍后重试")
+	}
+	return resp, nil
 }
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SessionListLogic 登录会话列表逻辑。
+type SessionListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSessionListLogic 创建登录会话列表逻辑。
+func NewSessionListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SessionListLogic {
+	return &SessionListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SessionList 获取当前用户未过期的登录会话，并标记当前会话。
+func (l *SessionListLogic) SessionList(req *types.SessionListRequest) (resp *types.SessionListResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	current, _ := l.ctx.Value("session_id").(string)
+	var sessions []models.UserSession
+	err = l.svcCtx.DBEngine.Where("user_identity = ? AND expire_at > ?", userIdentity, time.Now().Format(common.DataTimeFormat)).
+		Desc("last_seen_at").
+		Find(&sessions)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*types.SessionItem, 0, len(sessions))
+	for _, s := range sessions {
+		list = append(list, &types.SessionItem{
+			Identity:   s.Identity,
+			Device:     s.Device,
+			Ip:         s.Ip,
+			UserAgent:  s.UserAgent,
+			LastSeenAt: s.LastSeenAt,
+			CreatedAt:  s.CreatedAt,
+			Current:    s.Identity == current,
+		})
+	}
+	return &types.SessionListResponse{List: list}, nil
+}
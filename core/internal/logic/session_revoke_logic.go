@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SessionRevokeLogic 注销指定登录会话逻辑。
+type SessionRevokeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSessionRevokeLogic 创建注销指定登录会话逻辑。
+func NewSessionRevokeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SessionRevokeLogic {
+	return &SessionRevokeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SessionRevoke 注销当前用户的指定会话，该会话的令牌立即失效。
+func (l *SessionRevokeLogic) SessionRevoke(req *types.SessionRevokeRequest) (resp *types.SessionRevokeResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	cnt, err := l.svcCtx.DBEngine.Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).Count(new(models.UserSession))
+	if err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, errors.New("会话不存在")
+	}
+	if _, err := revokeSessions(l.ctx, l.svcCtx, userIdentity, req.Identity); err != nil {
+		return nil, err
+	}
+	return &types.SessionRevokeResponse{}, nil
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SessionRevokeOthersLogic 注销其他登录会话逻辑。
+type SessionRevokeOthersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSessionRevokeOthersLogic 创建注销其他登录会话逻辑。
+func NewSessionRevokeOthersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SessionRevokeOthersLogic {
+	return &SessionRevokeOthersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SessionRevokeOthers 注销当前会话以外的全部会话。
+func (l *SessionRevokeOthersLogic) SessionRevokeOthers(req *types.SessionRevokeOthersRequest) (resp *types.SessionRevokeOthersResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	current, _ := l.ctx.Value("session_id").(string)
+	var sessions []models.UserSession
+	if err := l.svcCtx.DBEngine.Where("user_identity = ? AND identity != ?", userIdentity, current).Cols("identity").Find(&sessions); err != nil {
+		return nil, err
+	}
+	sids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		sids = append(sids, s.Identity)
+	}
+	cnt, err := revokeSessions(l.ctx, l.svcCtx, userIdentity, sids...)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SessionRevokeOthersResponse{Count: cnt}, nil
+}
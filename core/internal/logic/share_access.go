@@ -0,0 +1,210 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+)
+
+// maxShareAncestorDepth 向上查找祖先目录的最大层数，防止脏数据导致死循环。
+const maxShareAncestorDepth = 64
+
+// sharePermissionRank 分享权限等级，数值越大权限越高。
+var sharePermissionRank = map[string]int{
+	common.SharePermissionView:     1,
+	common.SharePermissionDownload: 2,
+	common.SharePermissionEdit:     3,
+}
+
+// permissionAllows 判断已有权限是否满足所需权限。
+func permissionAllows(have, need string) bool {
+	return have != "" && sharePermissionRank[have] >= sharePermissionRank[need]
+}
+
+// higherPermission 返回两个权限中较高的一个。
+func higherPermission(a, b string) string {
+	if sharePermissionRank[b] > sharePermissionRank[a] {
+		return b
+	}
+	return a
+}
+
+// isShareExpired 判断分享是否已过期。
+func isShareExpired(share *models.ShareBasic, now time.Time) (bool, error) {
+	if share.ExpiredTime <= 0 {
+		return false, nil
+	}
+	createdAt, err := time.Parse(common.DataTimeFormat, share.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return createdAt.Add(time.Duration(share.ExpiredTime) * time.Second).Before(now), nil
+}
+
+// userEmail 查询用户邮箱。
+func userEmail(svcCtx *svc.ServiceContext, userIdentity string) (string, error) {
+	user := new(models.UserBasic)
+	has, err := svcCtx.DBEngine.Where("identity = ?", userIdentity).Cols("email").Get(user)
+	if err != nil || !has {
+		return "", err
+	}
+	return user.Email, nil
+}
+
+// shareGrantPermission 查询用户在某个分享上被授予的最高权限。
+func shareGrantPermission(svcCtx *svc.ServiceContext, share *models.ShareBasic, userIdentity string) (string, error) {
+	if share.UserIdentity == userIdentity {
+		return common.SharePermissionEdit, nil
+	}
+	if share.Scope != common.ShareScopeRestricted {
+		return common.SharePermissionDownload, nil
+	}
+	email, err := userEmail(svcCtx, userIdentity)
+	if err != nil {
+		return "", err
+	}
+	var grants []models.ShareGrant
+	err = svcCtx.DBEngine.Where("share_identity = ?", share.Identity).
+		And("grantee_identity = ? OR (grantee_email != '' AND grantee_email = ?)", userIdentity, email).
+		Find(&grants)
+	if err != nil {
+		return "", err
+	}
+	perm := ""
+	for _, g := range grants {
+		perm = higherPermission(perm, g.Permission)
+	}
+	return perm, nil
+}
+
+// grantedPermission 查询用户对某个文件（含其祖先目录上的分享）被授予的最高权限。
+func grantedPermission(svcCtx *svc.ServiceContext, userIdentity string, item *models.UserRepository) (string, error) {
+	if item.UserIdentity == userIdentity {
+		return common.SharePermissionEdit, nil
+	}
+	ids := []string{item.Identity}
+	parentId := item.ParentId
+	for depth := 0; parentId != 0 && depth < maxShareAncestorDepth; depth++ {
+		parent := new(models.UserRepository)
+		has, err := svcCtx.DBEngine.Where("id = ? AND user_identity = ?", parentId, item.UserIdentity).Get(parent)
+		if err != nil {
+			return "", err
+		}
+		if !has {
+			break
+		}
+		ids = append(ids, parent.Identity)
+		parentId = parent.ParentId
+	}
+
+	var shares []models.ShareBasic
+	err := svcCtx.DBEngine.In("user_repository_identity", ids).
+		Where("user_identity = ? AND scope = ?", item.UserIdentity, common.ShareScopeRestricted).
+		Find(&shares)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	perm := ""
+	for i := range shares {
+		expired, err := isShareExpired(&shares[i], now)
+		if err != nil || expired {
+			continue
+		}
+		p, err := shareGrantPermission(svcCtx, &shares[i], userIdentity)
+		if err != nil {
+			return "", err
+		}
+		perm = higherPermission(perm, p)
+	}
+	return perm, nil
+}
+
+// grantedRepository 查询用户通过定向分享对其拥有指定权限的存储文件记录，没有权限时返回 nil。
+func grantedRepository(svcCtx *svc.ServiceContext, userIdentity, repositoryIdentity, need string) (*models.UserRepository, error) {
+	var refs []models.UserRepository
+	err := svcCtx.DBEngine.
+		Where("repository_identity = ? AND (status != ? OR status IS NULL)", repositoryIdentity, common.StatusDeleted).
+		Find(&refs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range refs {
+		perm, err := grantedPermission(svcCtx, userIdentity, &refs[i])
+		if err != nil {
+			return nil, err
+		}
+		if permissionAllows(perm, need) {
+			return &refs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// shareEditable 判断用户是否通过定向分享对文件夹拥有编辑权限（文件夹本身或其祖先目录以 edit 权限分享给该用户）。
+func shareEditable(svcCtx *svc.ServiceContext, userIdentity string, folder *models.UserRepository) (bool, error) {
+	if folder.RepositoryIdentity != "" {
+		return false, nil
+	}
+	perm, err := grantedPermission(svcCtx, userIdentity, folder)
+	if err != nil {
+		return false, err
+	}
+	return permissionAllows(perm, common.SharePermissionEdit), nil
+}
+
+// editableNode 查询用户可修改的未删除文件或文件夹：空间角色需为 editor 及以上，或其所在文件夹以 edit 权限分享给该用户。
+// 分享授予的编辑权限只作用于分享文件夹内部，分享的文件夹本身不能通过它修改。
+func editableNode(svcCtx *svc.ServiceContext, userIdentity, identity string) (*models.UserRepository, error) {
+	node, err := spaceOfNode(svcCtx, userIdentity, identity, common.GroupRoleEditor)
+	if err != errNodeNotFound {
+		return node, err
+	}
+	node = new(models.UserRepository)
+	has, err := svcCtx.DBEngine.
+		Where("identity = ? AND (status != ? OR status IS NULL)", identity, common.StatusDeleted).
+		Get(node)
+	if err != nil {
+		return nil, err
+	}
+	if !has || node.ParentId == 0 {
+		return nil, errNodeNotFound
+	}
+	parent := new(models.UserRepository)
+	has, err = svcCtx.DBEngine.
+		Where("id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", node.ParentId, node.UserIdentity, common.StatusDeleted).
+		Get(parent)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errNodeNotFound
+	}
+	ok, err := shareEditable(svcCtx, userIdentity, parent)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errNodeNotFound
+	}
+	return node, nil
+}
+
+// notifyShareAccess 通知分享者其分享被访问，分享者本人访问时不通知。
+func notifyShareAccess(ctx context.Context, svcCtx *svc.ServiceContext, share *models.ShareBasic, action, name string) {
+	visitor, _ := ctx.Value("user_identity").(string)
+	if visitor == share.UserIdentity {
+		return
+	}
+	mq.Notify(ctx, svcCtx, share.UserIdentity, common.NotifyShareAccessed, &types.ShareAccessNotice{
+		ShareIdentity: share.Identity,
+		Name:          name,
+		Action:        action,
+		Visitor:       visitor,
+	})
+}
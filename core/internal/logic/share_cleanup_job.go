@@ -0,0 +1,140 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// shareCleanupBatchSize 每批扫描的分享数量。
+const shareCleanupBatchSize = 500
+
+// StartShareCleanupJob 启动过期分享清理任务。
+func StartShareCleanupJob(ctx context.Context, svcCtx *svc.ServiceContext) {
+	interval := utils.ShareCleanupInterval()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cleanupShares(ctx, svcCtx)
+			}
+		}
+	}()
+}
+
+// cleanupShares 分批软删除已过期或源文件已删除的分享，并清理其缓存。
+func cleanupShares(ctx context.Context, svcCtx *svc.ServiceContext) {
+	now := time.Now()
+	// created_at 以字符串存储，跨数据库的日期运算不可移植，仅在 SQL 中筛出设置了有效期的分享，再逐条比较
+	forEachShareBatch(func(last string) ([]models.ShareBasic, error) {
+		var shares []models.ShareBasic
+		err := svcCtx.DBEngine.Where("expired_time > 0 AND identity > ?", last).
+			Asc("identity").Limit(shareCleanupBatchSize).Find(&shares)
+		return shares, err
+	}, func(share *models.ShareBasic) {
+		expired, err := isShareExpired(share, now)
+		if err != nil {
+			logx.Errorf("share cleanup check failed share=%s err=%v", share.Identity, err)
+			return
+		}
+		if expired {
+			removeShareLogged(ctx, svcCtx, share, common.EventShareExpired)
+		}
+	})
+	forEachShareBatch(func(last string) ([]models.ShareBasic, error) {
+		return orphanedShares(svcCtx, last)
+	}, func(share *models.ShareBasic) {
+		removeShareLogged(ctx, svcCtx, share, common.EventShareOrphaned)
+	})
+}
+
+// forEachShareBatch 按 identity 分页调用 list，逐条处理查询到的分享，直到不足一批。
+func forEachShareBatch(list func(last string) ([]models.ShareBasic, error), handle func(share *models.ShareBasic)) {
+	last := ""
+	for {
+		shares, err := list(last)
+		if err != nil {
+			logx.Errorf("share cleanup list failed: %v", err)
+			return
+		}
+		for i := range shares {
+			last = shares[i].Identity
+			handle(&shares[i])
+		}
+		if len(shares) < shareCleanupBatchSize {
+			return
+		}
+	}
+}
+
+// orphanedShares 通过 LEFT JOIN 查询源文件已删除（不存在、在回收站或已清理）的分享，按 identity 分页。
+func orphanedShares(svcCtx *svc.ServiceContext, last string) ([]models.ShareBasic, error) {
+	var shares []models.ShareBasic
+	err := svcCtx.DBEngine.Alias("s").Select("s.*").
+		Join("LEFT", []string{new(models.UserRepository).TableName(), "ur"},
+			"((s.user_repository_identity != '' AND ur.identity = s.user_repository_identity)"+
+				" OR ((s.user_repository_identity = '' OR s.user_repository_identity IS NULL)"+
+				" AND ur.user_identity = s.user_identity AND ur.repository_identity = s.repository_identity))"+
+				" AND (ur.status IS NULL OR ur.status NOT IN (?, ?))"+
+				" AND (ur.deleted_at IS NULL OR ur.deleted_at = '' OR ur.deleted_at = ?)",
+			common.StatusDeleted, common.StatusPurged, zeroDeletedAt).
+		Where("ur.id IS NULL AND s.identity > ?", last).
+		Asc("s.identity").Limit(shareCleanupBatchSize).Find(&shares)
+	return shares, err
+}
+
+// zeroDeletedAt xorm 对未软删除的字符串 deleted 列使用的零值。
+const zeroDeletedAt = "0001-01-01 00:00:00"
+
+// removeShareLogged 清理分享，失败只记录日志。
+func removeShareLogged(ctx context.Context, svcCtx *svc.ServiceContext, share *models.ShareBasic, event string) {
+	if err := removeShare(ctx, svcCtx, share, event); err != nil {
+		logx.Errorf("share cleanup remove failed share=%s err=%v", share.Identity, err)
+	}
+}
+
+// removeShare 软删除分享及其授权，清理下载链接缓存并记录事件。
+func removeShare(ctx context.Context, svcCtx *svc.ServiceContext, share *models.ShareBasic, event string) error {
+	if _, err := svcCtx.DBEngine.Where("identity = ?", share.Identity).Delete(new(models.ShareBasic)); err != nil {
+		return err
+	}
+	if _, err := svcCtx.DBEngine.Where("share_identity = ?", share.Identity).Delete(new(models.ShareGrant)); err != nil {
+		logx.Errorf("share cleanup grants failed share=%s err=%v", share.Identity, err)
+	}
+	deleteKeysByPattern(ctx, svcCtx.RedisClient, "share_download_url:"+share.Identity+":*")
+	deleteKeysByPattern(ctx, svcCtx.RedisClient, "lock:share_download_url:"+share.Identity+":*")
+	return utils.RecordFileEvent(svcCtx.DBEngine, &models.FileEventLog{
+		RepositoryIdentity: share.RepositoryIdentity,
+		UserIdentity:       share.UserIdentity,
+		EventType:          event,
+	})
+}
+
+// deleteKeysByPattern 按模式扫描并删除 Redis 键。
+func deleteKeysByPattern(ctx context.Context, rdb svc.RedisClient, pattern string) {
+	var cursor uint64
+	for {
+		keys, next, err := rdb.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			logx.Errorf("redis scan failed pattern=%s err=%v", pattern, err)
+			return
+		}
+		if len(keys) > 0 {
+			_ = rdb.Del(ctx, keys...).Err()
+		}
+		if next == 0 {
+			return
+		}
+		cursor = next
+	}
+}
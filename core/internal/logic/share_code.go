@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+)
+
+const (
+	// shareCodeLength 分享短码长度。
+	shareCodeLength = 8
+	// maxShareCodeAttempts 生成短码时的最大重试次数。
+	maxShareCodeAttempts = 5
+)
+
+// insertShareWithCode 为分享生成短码并执行写入，短码与已有记录冲突（唯一索引）时换码重试。
+func insertShareWithCode(share *models.ShareBasic, insert func() error) error {
+	for i := 0; i < maxShareCodeAttempts; i++ {
+		code, err := utils.Base62Code(shareCodeLength)
+		if err != nil {
+			return err
+		}
+		share.Code = code
+		err = insert()
+		if err == nil {
+			return nil
+		}
+		if !utils.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return errors.New("分享短码生成失败，请重试")
+}
+
+// loadShare 按分享标识或短码查询分享。
+func loadShare(svcCtx *svc.ServiceContext, key string) (*models.ShareBasic, bool, error) {
+	share := new(models.ShareBasic)
+	has, err := svcCtx.DBEngine.Where("identity = ? OR (code != '' AND code = ?)", key, key).Get(share)
+	return share, has, err
+}
+
+// shareURL 返回分享的公开访问地址，优先使用短码。
+func shareURL(share *models.ShareBasic) string {
+	if share.Code != "" {
+		return utils.ShareBaseURL() + share.Code
+	}
+	return utils.ShareBaseURL() + share.Identity
+}
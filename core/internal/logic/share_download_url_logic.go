@@ -39,24 +39,33 @@ func (l *ShareDownloadURLLogic) ShareDownloadURL(req *types.ShareDownloadURLRequ
 	}
 	expires := normalizeExpires(req.Expires)
 
-	share := new(models.ShareBasic)
-	has, err := l.svcCtx.DBEngine.Where("identity = ?", req.ShareIdentity).Get(share)
+	share, has, err := loadShare(l.svcCtx, req.ShareIdentity)
 	if err != nil {
 		return nil, err
 	}
 	if !has {
 		return nil, errors.New("分享不存在")
 	}
-	if share.ExpiredTime > 0 {
-		createdAt, parseErr := time.Parse(common.DataTimeFormat, share.CreatedAt)
-		if parseErr != nil {
-			return nil, parseErr
+	expired, err := isShareExpired(share, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, errors.New("分享已过期")
+	}
+	if share.Scope == common.ShareScopeRestricted {
+		userIdentity, _ := l.ctx.Value("user_identity").(string)
+		perm, permErr := shareGrantPermission(l.svcCtx, share, userIdentity)
+		if permErr != nil {
+			return nil, permErr
 		}
-		if createdAt.Add(time.Duration(share.ExpiredTime) * time.Second).Before(time.Now()) {
-			return nil, errors.New("分享已过期")
+		if !permissionAllows(perm, common.SharePermissionDownload) {
+			return nil, errors.New("无权下载该分享")
 		}
 	}
 
+	recordFileEvent(l.ctx, l.svcCtx, common.EventDownload, share.UserIdentity, nodeFolder(l.svcCtx, share.UserRepositoryIdentity), share.RepositoryIdentity, "share="+share.Identity)
+
 	repo := new(models.RepositoryPool)
 	has, err = l.svcCtx.DBEngine.Where("identity = ?", share.RepositoryIdentity).Get(repo)
 	if err != nil {
@@ -65,6 +74,7 @@ func (l *ShareDownloadURLLogic) ShareDownloadURL(req *types.ShareDownloadURLRequ
 	if !has {
 		return nil, errors.New("文件不存在")
 	}
+	notifyShareAccess(l.ctx, l.svcCtx, share, common.ShareActionDownload, repo.Name)
 	objectKey := repo.ObjectKey
 	if objectKey == "" {
 		objectKey = utils.ObjectKeyFromPath(repo.Path)
@@ -73,7 +83,8 @@ func (l *ShareDownloadURLLogic) ShareDownloadURL(req *types.ShareDownloadURLRequ
 		return nil, errors.New("文件未绑定对象键")
 	}
 
-	cacheKey := fmt.Sprintf("share_download_url:%s:%d", req.ShareIdentity, expires)
+	// 按分享标识缓存：请求可能使用短码，清理任务按标识删除缓存
+	cacheKey := fmt.Sprintf("share_download_url:%s:%d", share.Identity, expires)
 	if url, ok := getCachedShareURL(l.ctx, l.svcCtx.RedisClient, cacheKey); ok {
 		return &types.ShareDownloadURLResponse{URL: url, Expires: expires}, nil
 	}
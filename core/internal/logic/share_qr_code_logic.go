@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// defaultQRCodeSize 二维码默认边长（像素）。
+	defaultQRCodeSize = 256
+	// maxQRCodeSize 二维码最大边长（像素）。
+	maxQRCodeSize = 1024
+)
+
+// ShareQRCodeLogic 分享二维码逻辑。
+type ShareQRCodeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewShareQRCodeLogic 创建分享二维码逻辑。
+func NewShareQRCodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ShareQRCodeLogic {
+	return &ShareQRCodeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ShareQRCode 生成分享公开地址的 PNG 二维码。
+func (l *ShareQRCodeLogic) ShareQRCode(req *types.ShareQRCodeRequest) ([]byte, error) {
+	if req.Identity == "" {
+		return nil, errors.New("分享标识不能为空")
+	}
+	share, has, err := loadShare(l.svcCtx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("分享不存在")
+	}
+	expired, err := isShareExpired(share, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, errors.New("分享已过期")
+	}
+	if share.Scope == common.ShareScopeRestricted {
+		userIdentity, _ := l.ctx.Value("user_identity").(string)
+		perm, permErr := shareGrantPermission(l.svcCtx, share, userIdentity)
+		if permErr != nil {
+			return nil, permErr
+		}
+		if !permissionAllows(perm, common.SharePermissionView) {
+			return nil, errors.New("无权查看该分享")
+		}
+	}
+
+	size := req.Size
+	if size <= 0 {
+		size = defaultQRCodeSize
+	}
+	if size > maxQRCodeSize {
+		size = maxQRCodeSize
+	}
+	return qrcode.Encode(shareURL(share), qrcode.Medium, size)
+}
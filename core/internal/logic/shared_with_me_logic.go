@@ -0,0 +1,135 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SharedWithMeLogic 与我共享列表逻辑。
+type SharedWithMeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSharedWithMeLogic 创建与我共享列表逻辑。
+func NewSharedWithMeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SharedWithMeLogic {
+	return &SharedWithMeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SharedWithMe 获取定向分享给当前用户的文件列表。
+func (l *SharedWithMeLogic) SharedWithMe(req *types.SharedWithMeRequest) (resp *types.SharedWithMeResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.PageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	email, err := userEmail(l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	var grants []models.ShareGrant
+	err = l.svcCtx.DBEngine.
+		Where("grantee_identity = ? OR (grantee_email != '' AND grantee_email = ?)", userIdentity, email).
+		Find(&grants)
+	if err != nil {
+		return nil, err
+	}
+	perms := map[string]string{}
+	for _, g := range grants {
+		perms[g.ShareIdentity] = higherPermission(perms[g.ShareIdentity], g.Permission)
+	}
+	if len(perms) == 0 {
+		return &types.SharedWithMeResponse{List: []*types.SharedWithMeItem{}}, nil
+	}
+	shareIds := make([]string, 0, len(perms))
+	for id := range perms {
+		shareIds = append(shareIds, id)
+	}
+
+	var shares []models.ShareBasic
+	if err = l.svcCtx.DBEngine.In("identity", shareIds).Desc("id").Find(&shares); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	list := make([]*types.SharedWithMeItem, 0, len(shares))
+	owners := map[string]string{}
+	for i := range shares {
+		share := &shares[i]
+		if expired, parseErr := isShareExpired(share, now); parseErr != nil || expired {
+			continue
+		}
+		item := new(models.UserRepository)
+		has, err := l.svcCtx.DBEngine.
+			Where("identity = ? AND (status != ? OR status IS NULL)", share.UserRepositoryIdentity, common.StatusDeleted).
+			Get(item)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			continue
+		}
+		entry := &types.SharedWithMeItem{
+			ShareIdentity:      share.Identity,
+			Id:                 item.Id,
+			Identity:           item.Identity,
+			Name:               item.Name,
+			Ext:                item.Ext,
+			RepositoryIdentity: item.RepositoryIdentity,
+			IsDir:              item.RepositoryIdentity == "",
+			Permission:         perms[share.Identity],
+			CreatedAt:          share.CreatedAt,
+		}
+		if !entry.IsDir {
+			repo := new(models.RepositoryPool)
+			if _, err := l.svcCtx.DBEngine.Where("identity = ?", item.RepositoryIdentity).Cols("size").Get(repo); err != nil {
+				return nil, err
+			}
+			entry.Size = repo.Size
+		}
+		if name, ok := owners[share.UserIdentity]; ok {
+			entry.OwnerName = name
+		} else {
+			owner := new(models.UserBasic)
+			if _, err := l.svcCtx.DBEngine.Where("identity = ?", share.UserIdentity).Cols("name").Get(owner); err != nil {
+				return nil, err
+			}
+			owners[share.UserIdentity] = owner.Name
+			entry.OwnerName = owner.Name
+		}
+		list = append(list, entry)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt > list[j].CreatedAt })
+
+	total := int64(len(list))
+	start := (page - 1) * size
+	if start > len(list) {
+		start = len(list)
+	}
+	end := start + size
+	if end > len(list) {
+		end = len(list)
+	}
+	return &types.SharedWithMeResponse{List: list[start:end], Count: total}, nil
+}
@@ -0,0 +1,75 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// refreshTokenLength 刷新令牌长度（base62 字符数）。
+const refreshTokenLength = 43
+
+// tokenPair 访问令牌与刷新令牌。
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// refreshTokenKey 返回刷新令牌在 Redis 中的键，仅保存令牌的哈希。
+func refreshTokenKey(token string) string {
+	return "refresh_token:" + utils.HashToken(token)
+}
+
+// issueTokenPair 签发短期访问令牌，并生成保存在 Redis 中的刷新令牌；未绑定会话时为本次登录创建会话。
+func issueTokenPair(ctx context.Context, svcCtx *svc.ServiceContext, payload utils.JwtPayLoad) (*tokenPair, error) {
+	if payload.SessionId == "" {
+		// 新登录（含两步验证、单点登录）统一拦截已禁用账号
+		if err := ensureUserEnabled(svcCtx, payload.Identity); err != nil {
+			return nil, err
+		}
+		sid, err := createSession(ctx, svcCtx, payload.Identity)
+		if err != nil {
+			return nil, err
+		}
+		payload.SessionId = sid
+	}
+	ttl := utils.AccessTokenTTL(svcCtx.Config.Auth.AccessExpire)
+	access, _, err := utils.GenAccessToken(payload, svcCtx.Config.Auth.AccessSecret, ttl)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := utils.Base62Code(refreshTokenLength)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := svcCtx.RedisClient.Set(ctx, refreshTokenKey(refresh), string(body), utils.RefreshTokenTTL()).Err(); err != nil {
+		return nil, err
+	}
+	return &tokenPair{AccessToken: access, RefreshToken: refresh, ExpiresIn: int64(ttl.Seconds())}, nil
+}
+
+// loadRefreshToken 读取刷新令牌对应的用户信息，令牌不存在时返回错误。
+func loadRefreshToken(ctx context.Context, svcCtx *svc.ServiceContext, token string) (*utils.JwtPayLoad, error) {
+	val, err := svcCtx.RedisClient.Get(ctx, refreshTokenKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, errors.New("刷新令牌无效或已过期")
+	}
+	if err != nil {
+		return nil, err
+	}
+	payload := new(utils.JwtPayLoad)
+	if err := json.Unmarshal([]byte(val), payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
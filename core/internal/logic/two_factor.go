@@ -0,0 +1,234 @@
+package logic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/skip2/go-qrcode"
+	"github.com/zeromicro/go-zero/core/logx"
+	"xorm.io/xorm"
+)
+
+const (
+	// twoFactorIssuer 认证器应用中显示的发行方名称。
+	twoFactorIssuer = "CloudDisk"
+	// totpSkew 允许的时钟偏差（时间步数）。
+	totpSkew = 1
+	// recoveryCodeCount 每次生成的恢复码数量。
+	recoveryCodeCount = 10
+	// loginChallengeTTL 两步登录挑战的有效期。
+	loginChallengeTTL = 5 * time.Minute
+	// loginChallengeMaxAttempts 单个挑战允许的验证码错误次数。
+	loginChallengeMaxAttempts = 5
+)
+
+// loginChallenge 两步登录挑战，保存在 Redis 中。
+type loginChallenge struct {
+	Identity string `json:"identity"`
+	Setup    bool   `json:"setup"`
+	Attempts int    `json:"attempts"`
+	ExpireAt int64  `json:"expire_at"`
+}
+
+// loadUserTotp 查询用户的 TOTP 配置。
+func loadUserTotp(svcCtx *svc.ServiceContext, userIdentity string) (*models.UserTotp, bool, error) {
+	totp := new(models.UserTotp)
+	has, err := svcCtx.DBEngine.Where("user_identity = ?", userIdentity).Get(totp)
+	return totp, has, err
+}
+
+// roleRequiresTwoFactor 判断角色是否被管理员要求开启两步验证。
+func roleRequiresTwoFactor(svcCtx *svc.ServiceContext, role string) (bool, error) {
+	policy := new(models.RolePolicy)
+	has, err := svcCtx.DBEngine.Where("role = ?", role).Get(policy)
+	if err != nil || !has {
+		return false, err
+	}
+	return policy.RequireTwoFactor, nil
+}
+
+// beginTwoFactorSetup 为用户生成待激活的 TOTP 密钥及二维码。
+func beginTwoFactorSetup(svcCtx *svc.ServiceContext, user *models.UserBasic) (*types.TwoFactorSetupResponse, error) {
+	totp, has, err := loadUserTotp(svcCtx, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if has && totp.Enabled {
+		return nil, errors.New("两步验证已开启")
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		_, err = svcCtx.DBEngine.Where("user_identity = ?", user.Identity).Cols("secret").Update(&models.UserTotp{Secret: secret})
+	} else {
+		_, err = svcCtx.DBEngine.Insert(&models.UserTotp{UserIdentity: user.Identity, Secret: secret})
+	}
+	if err != nil {
+		return nil, err
+	}
+	account := user.Email
+	if account == "" {
+		account = user.Name
+	}
+	uri := utils.TOTPProvisioningURI(twoFactorIssuer, account, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, defaultQRCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TwoFactorSetupResponse{
+		Secret:     secret,
+		OtpauthURL: uri,
+		QrCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// verifyTOTPOnce 校验 TOTP 验证码，同一时间步的验证码只能使用一次。
+func verifyTOTPOnce(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, secret, code string) (bool, error) {
+	step, ok := utils.VerifyTOTP(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	key := "totp_used:" + userIdentity + ":" + strconv.FormatInt(step, 10)
+	return svcCtx.RedisClient.SetNX(ctx, key, "1", time.Duration(2*totpSkew+1)*30*time.Second).Result()
+}
+
+// verifySecondFactor 校验已开启两步验证用户的 TOTP 验证码或一次性恢复码。
+func verifySecondFactor(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, code string) (bool, error) {
+	totp, has, err := loadUserTotp(svcCtx, userIdentity)
+	if err != nil {
+		return false, err
+	}
+	if !has || !totp.Enabled {
+		return false, errors.New("未开启两步验证")
+	}
+	code = strings.TrimSpace(code)
+	if ok, err := verifyTOTPOnce(ctx, svcCtx, userIdentity, totp.Secret, code); err != nil || ok {
+		return ok, err
+	}
+	return useRecoveryCode(svcCtx, userIdentity, code)
+}
+
+// useRecoveryCode 校验并消耗一次性恢复码。
+func useRecoveryCode(svcCtx *svc.ServiceContext, userIdentity, code string) (bool, error) {
+	if code == "" {
+		return false, nil
+	}
+	affected, err := svcCtx.DBEngine.
+		Where("user_identity = ? AND code_hash = ? AND (used_at = '' OR used_at IS NULL)", userIdentity, recoveryCodeHash(code)).
+		Cols("used_at").
+		Update(&models.UserRecoveryCode{UsedAt: time.Now().Format(common.DataTimeFormat)})
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// recoveryCodeHash 计算恢复码哈希，忽略大小写与分隔符。
+func recoveryCodeHash(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// enableTwoFactor 激活用户的 TOTP 并生成新的恢复码，恢复码明文仅返回这一次。
+func enableTwoFactor(svcCtx *svc.ServiceContext, userIdentity string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.UserRecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.Base62Code(10)
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(raw[:5] + "-" + raw[5:])
+		codes = append(codes, code)
+		rows = append(rows, models.UserRecoveryCode{UserIdentity: userIdentity, CodeHash: recoveryCodeHash(code)})
+	}
+	_, err := svcCtx.DBEngine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		if _, err := session.Where("user_identity = ?", userIdentity).Cols("enabled").Update(&models.UserTotp{Enabled: true}); err != nil {
+			return nil, err
+		}
+		if _, err := session.Where("user_identity = ?", userIdentity).Delete(new(models.UserRecoveryCode)); err != nil {
+			return nil, err
+		}
+		return session.Insert(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// createLoginChallenge 密码校验通过后创建两步登录挑战，返回挑战令牌。
+func createLoginChallenge(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string, setup bool) (string, error) {
+	token, err := utils.Base62Code(32)
+	if err != nil {
+		return "", err
+	}
+	challenge := &loginChallenge{Identity: userIdentity, Setup: setup, ExpireAt: time.Now().Add(loginChallengeTTL).Unix()}
+	if err := putLoginChallenge(ctx, svcCtx, token, challenge); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// loadLoginChallenge 读取两步登录挑战。
+func loadLoginChallenge(ctx context.Context, svcCtx *svc.ServiceContext, token string) (*loginChallenge, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.New("登录挑战不能为空")
+	}
+	val, err := svcCtx.RedisClient.Get(ctx, "login_challenge:"+token).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, errors.New("登录挑战无效或已过期，请重新登录")
+	}
+	if err != nil {
+		return nil, err
+	}
+	challenge := new(loginChallenge)
+	if err := json.Unmarshal([]byte(val), challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// putLoginChallenge 写入两步登录挑战，保持原有过期时间。
+func putLoginChallenge(ctx context.Context, svcCtx *svc.ServiceContext, token string, challenge *loginChallenge) error {
+	ttl := time.Until(time.Unix(challenge.ExpireAt, 0))
+	if ttl <= 0 {
+		return svcCtx.RedisClient.Del(ctx, "login_challenge:"+token).Err()
+	}
+	body, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	return svcCtx.RedisClient.Set(ctx, "login_challenge:"+token, string(body), ttl).Err()
+}
+
+// failLoginChallenge 记录一次验证码错误，超过次数后作废挑战。
+func failLoginChallenge(ctx context.Context, svcCtx *svc.ServiceContext, token string, challenge *loginChallenge) {
+	challenge.Attempts++
+	var err error
+	if challenge.Attempts >= loginChallengeMaxAttempts {
+		err = svcCtx.RedisClient.Del(ctx, "login_challenge:"+token).Err()
+	} else {
+		err = putLoginChallenge(ctx, svcCtx, token, challenge)
+	}
+	if err != nil {
+		logx.Errorf("login challenge update failed identity=%s err=%v", challenge.Identity, err)
+	}
+}
@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"xorm.io/xorm"
+)
+
+// TwoFactorDisableLogic 关闭两步验证逻辑。
+type TwoFactorDisableLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTwoFactorDisableLogic 创建关闭两步验证逻辑。
+func NewTwoFactorDisableLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorDisableLogic {
+	return &TwoFactorDisableLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TwoFactorDisable 校验验证码或恢复码后关闭两步验证；角色强制要求时不允许关闭。
+func (l *TwoFactorDisableLogic) TwoFactorDisable(req *types.TwoFactorDisableRequest) (resp *types.TwoFactorDisableResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(l.ctx, l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	required, err := roleRequiresTwoFactor(l.svcCtx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return nil, errors.New("当前角色要求开启两步验证，无法关闭")
+	}
+	ok, err := verifySecondFactor(l.ctx, l.svcCtx, user.Identity, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("验证码错误")
+	}
+	_, err = l.svcCtx.DBEngine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		if _, err := session.Where("user_identity = ?", user.Identity).Delete(new(models.UserTotp)); err != nil {
+			return nil, err
+		}
+		return session.Where("user_identity = ?", user.Identity).Delete(new(models.UserRecoveryCode))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.TwoFactorDisableResponse{Message: "两步验证已关闭"}, nil
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TwoFactorEnableLogic 开启两步验证逻辑。
+type TwoFactorEnableLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTwoFactorEnableLogic 创建开启两步验证逻辑。
+func NewTwoFactorEnableLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorEnableLogic {
+	return &TwoFactorEnableLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TwoFactorEnable 校验认证器生成的验证码后开启两步验证，并返回一次性恢复码。
+func (l *TwoFactorEnableLogic) TwoFactorEnable(req *types.TwoFactorEnableRequest) (resp *types.TwoFactorEnableResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(l.ctx, l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	totp, has, err := loadUserTotp(l.svcCtx, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if totp.Enabled {
+		return nil, errors.New("两步验证已开启")
+	}
+	ok, err := verifyTOTPOnce(l.ctx, l.svcCtx, user.Identity, totp.Secret, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("验证码错误")
+	}
+	codes, err := enableTwoFactor(l.svcCtx, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TwoFactorEnableResponse{RecoveryCodes: codes}, nil
+}
@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TwoFactorSetupLogic 两步验证绑定逻辑。
+type TwoFactorSetupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTwoFactorSetupLogic 创建两步验证绑定逻辑。
+func NewTwoFactorSetupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorSetupLogic {
+	return &TwoFactorSetupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TwoFactorSetup 为当前用户生成待激活的 TOTP 密钥与二维码。
+func (l *TwoFactorSetupLogic) TwoFactorSetup(req *types.TwoFactorSetupRequest) (resp *types.TwoFactorSetupResponse, err error) {
+	if err := rejectPersonalAccessToken(l.ctx); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(l.ctx, l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	return beginTwoFactorSetup(l.svcCtx, user)
+}
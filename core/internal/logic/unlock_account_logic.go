@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UnlockAccountLogic 账号解锁逻辑。
+type UnlockAccountLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUnlockAccountLogic 创建账号解锁逻辑。
+func NewUnlockAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UnlockAccountLogic {
+	return &UnlockAccountLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UnlockAccount 使用锁定邮件中的验证码解除账号锁定。
+func (l *UnlockAccountLogic) UnlockAccount(req *types.UnlockAccountRequest) (resp *types.UnlockAccountResponse, err error) {
+	name := strings.TrimSpace(req.Name)
+	code := strings.TrimSpace(req.Code)
+	if name == "" || code == "" {
+		return nil, errors.New("参数不能为空")
+	}
+	user := new(models.UserBasic)
+	has, err := l.svcCtx.DBEngine.Where("name = ?", name).Get(user)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("验证码已过期或无效")
+	}
+	if err := consumeCappedCode(l.ctx, l.svcCtx.RedisClient, unlockCodeKey(user.Identity), unlockAttemptsKey(user.Identity), code, loginLockDuration); err != nil {
+		return nil, err
+	}
+
+	newLoginGuard(l.ctx, l.svcCtx).reset(loginSubject(name, user))
+	if err := utils.RecordFileEvent(l.svcCtx.DBEngine, fileEvent(l.ctx, common.EventLoginUnlocked, user.Identity, "", "")); err != nil {
+		l.Errorf("unlock audit failed identity=%s err=%v", user.Identity, err)
+	}
+	return &types.UnlockAccountResponse{Message: "账号已解锁"}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
 	"cloud_disk/core/internal/types"
+	"cloud_disk/core/utils"
 	"context"
 	"encoding/json"
 	"errors"
@@ -36,8 +37,12 @@ func (l *UploadFileLogic) UploadFile(req *types.UploadFileRequest, isExisted boo
 	if !ok {
 		return nil, errors.New("用户身份验证失败")
 	}
+	owner, err := spaceOfParent(l.svcCtx, userIdentity, req.ParentId, common.GroupRoleEditor)
+	if err != nil {
+		return nil, err
+	}
 	uploadEvent := &types.UploadEvent{
-		UserIdentity:       userIdentity,
+		UserIdentity:       owner,
 		ParentId:           req.ParentId,
 		FilePath:           localFilePath,
 		Ext:                req.Ext,
@@ -46,7 +51,9 @@ func (l *UploadFileLogic) UploadFile(req *types.UploadFileRequest, isExisted boo
 		IsExisted:          isExisted,
 		RepositoryIdentity: repositoryIdentity,
 		Hash:               hash,
+		ActorIdentity:      userIdentity,
 	}
+	uploadEvent.ClientIp, _ = l.ctx.Value("client_ip").(string)
 	body, err := json.Marshal(uploadEvent)
 	if err != nil {
 		return nil, err // 序列化失败直接返回，不用发 MQ
@@ -58,6 +65,40 @@ func (l *UploadFileLogic) UploadFile(req *types.UploadFileRequest, isExisted boo
 	return &types.UploadFileResponse{Message: "文件上传开始"}, nil
 }
 
+// UploadOwner 解析上传目标目录所属的空间，上传到群组目录需要编辑权限。
+func (l *UploadFileLogic) UploadOwner(parentId int64) (string, error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return "", errors.New("用户身份验证失败")
+	}
+	return spaceOfParent(l.svcCtx, userIdentity, parentId, common.GroupRoleEditor)
+}
+
+// UploadLimit 返回读取请求体前可接受的最大字节数：取个人空间与可编辑群组空间剩余配额中的最大值，任一空间不限额时 limited 为 false。
+func (l *UploadFileLogic) UploadLimit() (remaining int64, limited bool, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return 0, false, errors.New("用户身份验证失败")
+	}
+	groups, err := memberGroups(l.svcCtx, userIdentity, common.GroupRoleEditor)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, owner := range append([]string{userIdentity}, groups...) {
+		left, ownerLimited, err := utils.RemainingQuota(l.svcCtx.DBEngine, owner)
+		if err != nil {
+			return 0, false, err
+		}
+		if !ownerLimited {
+			return 0, false, nil
+		}
+		if left > remaining {
+			remaining = left
+		}
+	}
+	return remaining, true, nil
+}
+
 func (l *UploadFileLogic) PublishUploadEvent(body []byte) error {
 	if l.svcCtx.RabbitMQConn == nil {
 		return errors.New("RabbitMQ 未初始化")
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UploadLinkDeleteLogic 删除匿名上传链接逻辑。
+type UploadLinkDeleteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUploadLinkDeleteLogic 创建删除匿名上传链接逻辑。
+func NewUploadLinkDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadLinkDeleteLogic {
+	return &UploadLinkDeleteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UploadLinkDelete 删除（软删除）匿名上传链接。
+func (l *UploadLinkDeleteLogic) UploadLinkDelete(req *types.UploadLinkDeleteRequest) (resp *types.UploadLinkDeleteResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	affected, err := l.svcCtx.DBEngine.Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).Delete(new(models.UploadLink))
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, errors.New("上传链接不存在")
+	}
+	return &types.UploadLinkDeleteResponse{}, nil
+}
@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UploadLinkInfoLogic 匿名上传链接信息逻辑。
+type UploadLinkInfoLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUploadLinkInfoLogic 创建匿名上传链接信息逻辑。
+func NewUploadLinkInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadLinkInfoLogic {
+	return &UploadLinkInfoLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UploadLinkInfo 获取匿名上传链接的公开信息，供上传页展示。
+func (l *UploadLinkInfoLogic) UploadLinkInfo(req *types.UploadLinkInfoRequest) (resp *types.UploadLinkInfoResponse, err error) {
+	link, err := loadActiveUploadLink(l.svcCtx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	owner := new(models.UserBasic)
+	if _, err := l.svcCtx.DBEngine.Where("identity = ?", link.UserIdentity).Cols("name").Get(owner); err != nil {
+		return nil, err
+	}
+	folderName := ""
+	if link.ParentId != 0 {
+		folder := new(models.UserRepository)
+		if _, err := l.svcCtx.DBEngine.Where("id = ?", link.ParentId).Cols("name").Get(folder); err != nil {
+			return nil, err
+		}
+		folderName = folder.Name
+	}
+	return &types.UploadLinkInfoResponse{
+		OwnerName:    owner.Name,
+		FolderName:   folderName,
+		ExpireAt:     link.ExpireAt,
+		MaxSize:      link.MaxSize,
+		AllowedExts:  splitAllowedExts(link.AllowedExts),
+		NeedPassword: link.Password != "",
+	}, nil
+}
+
+// loadActiveUploadLink 查询未过期的上传链接。
+func loadActiveUploadLink(svcCtx *svc.ServiceContext, identity string) (*models.UploadLink, error) {
+	if identity == "" {
+		return nil, errors.New("上传链接标识不能为空")
+	}
+	link := new(models.UploadLink)
+	has, err := svcCtx.DBEngine.Where("identity = ?", identity).Get(link)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("上传链接不存在")
+	}
+	expireAt, err := time.ParseInLocation(common.DataTimeFormat, link.ExpireAt, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if expireAt.Before(time.Now()) {
+		return nil, errors.New("上传链接已过期")
+	}
+	return link, nil
+}
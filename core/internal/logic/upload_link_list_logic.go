@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UploadLinkListLogic 匿名上传链接列表逻辑。
+type UploadLinkListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUploadLinkListLogic 创建匿名上传链接列表逻辑。
+func NewUploadLinkListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadLinkListLogic {
+	return &UploadLinkListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UploadLinkList 获取当前用户创建的匿名上传链接。
+func (l *UploadLinkListLogic) UploadLinkList(req *types.UploadLinkListRequest) (resp *types.UploadLinkListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.PageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	var links []models.UploadLink
+	cnt, err := l.svcCtx.DBEngine.Where("user_identity = ?", userIdentity).
+		Desc("created_at").
+		Limit(size, (page-1)*size).
+		FindAndCount(&links)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*types.UploadLinkItem, 0, len(links))
+	for _, link := range links {
+		list = append(list, &types.UploadLinkItem{
+			Identity:     link.Identity,
+			ParentId:     link.ParentId,
+			ExpireAt:     link.ExpireAt,
+			MaxSize:      link.MaxSize,
+			AllowedExts:  splitAllowedExts(link.AllowedExts),
+			NeedPassword: link.Password != "",
+			UploadCount:  link.UploadCount,
+			CreatedAt:    link.CreatedAt,
+		})
+	}
+	return &types.UploadLinkListResponse{List: list, Count: cnt}, nil
+}
@@ -0,0 +1,99 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UploadLinkUploadLogic 匿名上传逻辑。
+type UploadLinkUploadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUploadLinkUploadLogic 创建匿名上传逻辑。
+func NewUploadLinkUploadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadLinkUploadLogic {
+	return &UploadLinkUploadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AuthorizeUploadLink 在读取请求体前校验链接有效期与密码。
+func (l *UploadLinkUploadLogic) AuthorizeUploadLink(req *types.UploadLinkUploadRequest) (*models.UploadLink, error) {
+	link, err := loadActiveUploadLink(l.svcCtx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if link.Password != "" {
+		if ok, _ := utils.VerifyPassword(link.Password, utils.DecodeMaybeBase64(req.Password)); !ok {
+			return nil, errors.New("上传链接密码错误")
+		}
+	}
+	return link, nil
+}
+
+// CheckUploadFile 在保存文件内容前校验扩展名、大小与所有者剩余空间。
+func (l *UploadLinkUploadLogic) CheckUploadFile(link *models.UploadLink, ext string, size int64) error {
+	if link.AllowedExts != "" {
+		allowed := false
+		for _, e := range splitAllowedExts(link.AllowedExts) {
+			if strings.EqualFold(e, ext) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("不允许上传该类型文件，仅支持: %s", link.AllowedExts)
+		}
+	}
+	if size > link.MaxSize {
+		return fmt.Errorf("文件过大，超过上传链接 %d 字节限制", link.MaxSize)
+	}
+	// 文件计入链接所有者的存储空间；这里只做提前拒绝，上传任务处理时再原子预占
+	return utils.CheckQuota(l.svcCtx.DBEngine, link.UserIdentity, size)
+}
+
+// UploadLinkUpload 以链接所有者身份投递上传事件，并通知所有者。
+func (l *UploadLinkUploadLogic) UploadLinkUpload(link *models.UploadLink, req *types.UploadFileRequest, isExisted bool, repositoryIdentity string, localFilePath string, hash string) (resp *types.UploadLinkUploadResponse, err error) {
+	req.ParentId = link.ParentId
+	ownerCtx := context.WithValue(l.ctx, "user_identity", link.UserIdentity)
+	if _, err := NewUploadFileLogic(ownerCtx, l.svcCtx).UploadFile(req, isExisted, repositoryIdentity, localFilePath, hash); err != nil {
+		return nil, err
+	}
+
+	_, _ = l.svcCtx.DBEngine.Where("identity = ?", link.Identity).Incr("upload_count").Update(new(models.UploadLink))
+	recordFileEvent(l.ctx, l.svcCtx, common.EventDropUpload, link.UserIdentity, link.ParentId, repositoryIdentity,
+		fmt.Sprintf("link=%s name=%s size=%d", link.Identity, req.Name, req.Size))
+	l.notifyOwner(link, req.Name, req.Size)
+	return &types.UploadLinkUploadResponse{Message: "文件上传开始"}, nil
+}
+
+// notifyOwner 邮件通知链接所有者有新文件上传。
+func (l *UploadLinkUploadLogic) notifyOwner(link *models.UploadLink, name string, size int64) {
+	owner := new(models.UserBasic)
+	has, err := l.svcCtx.DBEngine.Where("identity = ?", link.UserIdentity).Get(owner)
+	if err != nil || !has || owner.Email == "" {
+		return
+	}
+	err = mq.SendMail(l.ctx, l.svcCtx, owner.Email, common.MailUploadReceived, utils.MailLang(owner.Language), map[string]any{
+		"Name": name,
+		"Size": size,
+	})
+	if err != nil {
+		l.Errorf("发送上传链接通知邮件失败 email=%s err=%v", owner.Email, err)
+	}
+}
@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UserActivityLogic 用户操作记录逻辑。
+type UserActivityLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUserActivityLogic 创建用户操作记录逻辑。
+func NewUserActivityLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserActivityLogic {
+	return &UserActivityLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserActivity 查询与当前用户相关的操作记录：本人空间内发生的事件及本人执行的操作；format 为 csv 或 json 时用于导出。
+func (l *UserActivityLogic) UserActivity(req *types.UserActivityRequest) (resp *types.AuditLogListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok || userIdentity == "" {
+		return nil, errors.New("用户身份验证失败")
+	}
+	if err := validAuditFormat(req.Format); err != nil {
+		return nil, err
+	}
+	filter := &auditFilter{
+		Participant: userIdentity,
+		EventTypes:  req.EventType,
+		Start:       req.Start,
+		End:         req.End,
+	}
+	page, size := auditPage(req.Page, req.Size, req.Format)
+	return queryAuditLogs(l.svcCtx, filter, page, size, req.Format == "")
+}
@@ -53,9 +53,15 @@ func (l *UserFileListLogic) UserFileList(req *types.UserFileListRequest) (resp *
 		return nil, errors.New("用户身份验证失败")
 	}
 
+	// 浏览他人目录时，需通过定向分享获得查看权限，并按目录所有者查询
+	ownerIdentity, err := l.resolveListOwner(req.Id, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+
 	// 查询文件列表（修复 JOIN 条件和添加 name 字段）
 	err = l.svcCtx.DBEngine.Table("user_repository").
-		Where("parent_id = ? AND user_identity = ?", req.Id, userIdentity).
+		Where("parent_id = ? AND user_identity = ?", req.Id, ownerIdentity).
 		Select("user_repository.id as id, user_repository.identity as identity, user_repository.name as name, "+
 			"user_repository.repository_identity as repository_identity, user_repository.ext as ext, "+
 			"repository_pool.size as size, user_repository.updated_at as updated_at").
@@ -72,7 +78,7 @@ func (l *UserFileListLogic) UserFileList(req *types.UserFileListRequest) (resp *
 	// 查询总数
 	// TODO （可优化： 把总数存入 Redis）
 	cnt, err = l.svcCtx.DBEngine.Table("user_repository").
-		Where("parent_id = ? AND user_identity = ?", req.Id, userIdentity).
+		Where("parent_id = ? AND user_identity = ?", req.Id, ownerIdentity).
 		Where("status != ? OR status IS NULL", common.StatusDeleted).
 		Count(new(models.UserRepository))
 	if err != nil {
@@ -84,3 +90,36 @@ func (l *UserFileListLogic) UserFileList(req *types.UserFileListRequest) (resp *
 
 	return
 }
+
+// resolveListOwner 解析目录所有者，非本人目录需校验群组成员身份或定向分享权限。
+func (l *UserFileListLogic) resolveListOwner(folderId int64, userIdentity string) (string, error) {
+	if folderId == 0 {
+		return userIdentity, nil
+	}
+	folder := new(models.UserRepository)
+	has, err := l.svcCtx.DBEngine.
+		Where("id = ? AND (status != ? OR status IS NULL)", folderId, common.StatusDeleted).
+		Get(folder)
+	if err != nil {
+		return "", err
+	}
+	if !has || folder.UserIdentity == userIdentity {
+		return userIdentity, nil
+	}
+	// 群组目录按成员身份授权，任意成员均可浏览
+	role, err := groupRole(l.svcCtx, folder.UserIdentity, userIdentity)
+	if err != nil {
+		return "", err
+	}
+	if groupRoleAllows(role, common.GroupRoleViewer) {
+		return folder.UserIdentity, nil
+	}
+	perm, err := grantedPermission(l.svcCtx, userIdentity, folder)
+	if err != nil {
+		return "", err
+	}
+	if !permissionAllows(perm, common.SharePermissionView) {
+		return "", errors.New("无权访问该目录")
+	}
+	return folder.UserIdentity, nil
+}
@@ -5,6 +5,7 @@ package logic
 
 import (
 	"context"
+	"fmt"
 
 	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
@@ -37,16 +38,34 @@ func (l *UserFileMoveLogic) UserFileMove(req *types.UserFileMoveRequest) (resp *
 	if !ok {
 		return nil, errors.New("用户身份验证失败")
 	}
+	node, err := spaceOfNode(l.svcCtx, userIdentity, req.Identity, common.GroupRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if isGroupRoot(node, userIdentity) {
+		return nil, errors.New("群组根目录不能移动")
+	}
 	parentData := new(models.UserRepository)
-	has, err := l.svcCtx.DBEngine.Where("id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.ParentId, userIdentity, common.StatusDeleted).Get(parentData)
+	has, err := l.svcCtx.DBEngine.Where("id = ? AND (status != ? OR status IS NULL)", req.ParentId, common.StatusDeleted).Get(parentData)
 	if err != nil {
 		return nil, err
 	}
-	if !has {
+	if !has || parentData.RepositoryIdentity != "" {
 		return nil, errors.New("目标文件夹不存在")
 	}
+	// 文件只能在所属空间内移动，个人空间与群组空间之间不能直接移动
+	if parentData.UserIdentity != node.UserIdentity {
+		role, err := spaceRole(l.svcCtx, userIdentity, parentData.UserIdentity)
+		if err != nil {
+			return nil, err
+		}
+		if role == "" {
+			return nil, errors.New("目标文件夹不存在")
+		}
+		return nil, errors.New("不支持跨空间移动")
+	}
 	// 查询该层级是否有同名文件
-	cnt, err := l.svcCtx.DBEngine.Table("user_repository").Where("name = ? AND parent_id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.Name, req.Identity, userIdentity, common.StatusDeleted).Count(new(models.UserRepository))
+	cnt, err := l.svcCtx.DBEngine.Table("user_repository").Where("name = ? AND parent_id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.Name, req.ParentId, node.UserIdentity, common.StatusDeleted).Count(new(models.UserRepository))
 	if err != nil {
 		return nil, err
 	}
@@ -54,9 +73,12 @@ func (l *UserFileMoveLogic) UserFileMove(req *types.UserFileMoveRequest) (resp *
 		return nil, errors.New("该目录下已存在同名文件")
 	}
 	// 更新
-	l.svcCtx.DBEngine.Table("user_repository").Where("user_identity = ? AND identity = ? AND (status != ? OR status IS NULL)", userIdentity, req.Identity, common.StatusDeleted).Update(&models.UserRepository{
+	if _, err := l.svcCtx.DBEngine.Table("user_repository").Where("id = ?", node.Id).Update(&models.UserRepository{
 		ParentId: req.ParentId,
-	})
+	}); err != nil {
+		return nil, err
+	}
+	recordFileEvent(l.ctx, l.svcCtx, common.EventMove, node.UserIdentity, req.ParentId, node.RepositoryIdentity, fmt.Sprintf("name=%s parent_id=%d->%d", node.Name, node.ParentId, req.ParentId))
 
 	return
 }
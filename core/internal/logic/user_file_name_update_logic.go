@@ -5,6 +5,7 @@ package logic
 
 import (
 	"context"
+	"fmt"
 
 	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
@@ -39,8 +40,15 @@ func (l *UserFileNameUpdateLogic) UserFileNameUpdate(req *types.UserFileNameUpda
 	if !ok {
 		return nil, errors.New("用户身份验证失败")
 	}
+	node, err := editableNode(l.svcCtx, userIdentity, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if isGroupRoot(node, userIdentity) {
+		return nil, errors.New("群组根目录不能重命名")
+	}
 	// 先查询该层级是否有同名文件
-	cnt, err := l.svcCtx.DBEngine.Table("user_repository").Where("name = ? AND parent_id = (SELECT parent_id FROM user_repository WHERE identity = ? AND user_identity = ? AND (status != ? OR status IS NULL)) AND user_identity = ? AND (status != ? OR status IS NULL)", req.Name, req.Identity, userIdentity, common.StatusDeleted, userIdentity, common.StatusDeleted).Count(new(models.UserRepository))
+	cnt, err := l.svcCtx.DBEngine.Table("user_repository").Where("name = ? AND parent_id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.Name, node.ParentId, node.UserIdentity, common.StatusDeleted).Count(new(models.UserRepository))
 	if err != nil {
 		return nil, err
 	}
@@ -48,10 +56,11 @@ func (l *UserFileNameUpdateLogic) UserFileNameUpdate(req *types.UserFileNameUpda
 		return nil, errors.New("该目录下已存在同名文件")
 	}
 	// 修改文件名
-	_, err = l.svcCtx.DBEngine.Table("user_repository").Where("identity = ? AND user_identity = ? AND (status != ? OR status IS NULL)", req.Identity, userIdentity, common.StatusDeleted).Update(data)
+	_, err = l.svcCtx.DBEngine.Table("user_repository").Where("id = ?", node.Id).Update(data)
 	if err != nil {
 		return nil, err
 	}
+	recordFileEvent(l.ctx, l.svcCtx, common.EventRename, node.UserIdentity, node.ParentId, node.RepositoryIdentity, fmt.Sprintf("name=%s->%s", node.Name, req.Name))
 
 	return &types.UserFileNameUpdateResponse{}, nil
 }
@@ -0,0 +1,167 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UserFileRestoreLogic 回收站文件恢复逻辑。
+type UserFileRestoreLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUserFileRestoreLogic 创建回收站文件恢复逻辑。
+func NewUserFileRestoreLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserFileRestoreLogic {
+	return &UserFileRestoreLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserFileRestore 从回收站恢复文件或文件夹（连同其下已删除的子项），恢复前校验上级目录、同名冲突与存储空间。
+func (l *UserFileRestoreLogic) UserFileRestore(req *types.UserFileRestoreRequest) (resp *types.UserFileRestoreResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	notFound := errors.New("回收站中不存在该文件")
+	item := new(models.UserRepository)
+	has, err := l.svcCtx.DBEngine.Unscoped().
+		Where("identity = ? AND status = ?", req.Identity, common.StatusDeleted).
+		Get(item)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, notFound
+	}
+	// 群组回收站中的文件需要编辑权限才能恢复，恢复后计入群组空间
+	if err := authorizeSpace(l.svcCtx, userIdentity, item.UserIdentity, common.GroupRoleEditor, notFound); err != nil {
+		return nil, err
+	}
+	owner := item.UserIdentity
+	if item.ParentId != 0 {
+		cnt, err := l.svcCtx.DBEngine.
+			Where("id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", item.ParentId, owner, common.StatusDeleted).
+			Count(new(models.UserRepository))
+		if err != nil {
+			return nil, err
+		}
+		if cnt == 0 {
+			return nil, errors.New("上级文件夹已删除，请先恢复上级文件夹")
+		}
+	}
+	cnt, err := l.svcCtx.DBEngine.
+		Where("name = ? AND parent_id = ? AND user_identity = ? AND (status != ? OR status IS NULL)", item.Name, item.ParentId, owner, common.StatusDeleted).
+		Count(new(models.UserRepository))
+	if err != nil {
+		return nil, err
+	}
+	if cnt > 0 {
+		return nil, errors.New("目标位置已存在同名文件")
+	}
+
+	nodes, err := l.deletedSubtree(item, owner)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(nodes))
+	repoIds := make([]string, 0, len(nodes))
+	args := []interface{}{owner}
+	for _, node := range nodes {
+		ids = append(ids, node.Identity)
+		args = append(args, node.Identity)
+		if node.RepositoryIdentity != "" {
+			repoIds = append(repoIds, node.RepositoryIdentity)
+		}
+	}
+	if len(repoIds) > 0 {
+		cnt, err := l.svcCtx.DBEngine.Unscoped().In("identity", repoIds).
+			In("status", common.StatusPurging, common.StatusPurged).
+			Count(new(models.RepositoryPool))
+		if err != nil {
+			return nil, err
+		}
+		if cnt > 0 {
+			return nil, errors.New("文件已被清理，无法恢复")
+		}
+	}
+	sizes, err := utils.UserFileSizes(l.svcCtx.DBEngine, "ur.user_identity = ? AND ur.identity IN ("+utils.InPlaceholders(len(ids))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	size := sizes[owner]
+	// 恢复的文件从回收站占用转回已用空间，写入前原子预占
+	if err := utils.ReserveQuota(l.svcCtx.DBEngine, owner, size, -size); err != nil {
+		return nil, err
+	}
+
+	affected, err := l.svcCtx.DBEngine.Table("user_repository").
+		In("identity", ids).
+		Where("user_identity = ? AND status = ?", owner, common.StatusDeleted).
+		Update(map[string]any{"status": common.StatusActive, "deleted_at": nil, "expire_at": nil})
+	if err != nil || affected == 0 {
+		// 写入失败或已被并发请求恢复，释放预占
+		utils.ReleaseQuota(l.svcCtx.DBEngine, owner, size, -size)
+		if err == nil {
+			err = errors.New("文件或文件夹不存在")
+		}
+		return nil, err
+	}
+	if len(repoIds) > 0 {
+		if _, err := l.svcCtx.DBEngine.Table("repository_pool").
+			In("identity", repoIds).
+			Where("status = ?", common.StatusDeleted).
+			Update(map[string]any{"status": common.StatusActive, "deleted_at": nil, "expire_at": nil}); err != nil {
+			return nil, err
+		}
+	}
+	mq.NotifyQuota(l.ctx, l.svcCtx, owner, size)
+	logs := make([]*models.FileEventLog, 0, len(nodes))
+	for _, node := range nodes {
+		event := fileEvent(l.ctx, common.EventRestore, owner, node.RepositoryIdentity, "name="+node.Name)
+		event.FolderId = node.ParentId
+		logs = append(logs, event)
+	}
+	recordFileEvents(l.ctx, l.svcCtx, logs...)
+	l.Infof("成功恢复 %d 个项目", affected)
+	return &types.UserFileRestoreResponse{Count: affected}, nil
+}
+
+// deletedSubtree 收集待恢复节点及其下处于删除状态的子项。
+func (l *UserFileRestoreLogic) deletedSubtree(root *models.UserRepository, owner string) ([]models.UserRepository, error) {
+	nodes := []models.UserRepository{*root}
+	level := []int64{}
+	if root.RepositoryIdentity == "" {
+		level = append(level, root.Id)
+	}
+	for depth := 0; len(level) > 0 && depth < maxShareAncestorDepth; depth++ {
+		var children []models.UserRepository
+		err := l.svcCtx.DBEngine.Unscoped().In("parent_id", level).
+			Where("user_identity = ? AND status = ?", owner, common.StatusDeleted).
+			Find(&children)
+		if err != nil {
+			return nil, err
+		}
+		level = level[:0]
+		for _, child := range children {
+			nodes = append(nodes, child)
+			if child.RepositoryIdentity == "" {
+				level = append(level, child.Id)
+			}
+		}
+	}
+	return nodes, nil
+}
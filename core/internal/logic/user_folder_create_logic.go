@@ -6,6 +6,7 @@ package logic
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"cloud_disk/core/common"
 	"cloud_disk/core/internal/svc"
@@ -39,8 +40,13 @@ func (l *UserFolderCreateLogic) UserFolderCreate(req *types.UserFolderCreateRequ
 	if !ok {
 		return nil, errors.New("用户身份验证失败")
 	}
+	// 在群组目录下创建需要编辑权限，文件夹归属群组空间
+	owner, err := spaceOfParent(l.svcCtx, userIdentity, req.ParentId, common.GroupRoleEditor)
+	if err != nil {
+		return nil, err
+	}
 	// 先查询该层级是否有同名文件
-	cnt, err := l.svcCtx.DBEngine.Table("user_repository").Where("name = ? AND parent_id = ? AND user_identity = ?", req.Name, req.ParentId, userIdentity).Count(new(models.UserRepository))
+	cnt, err := l.svcCtx.DBEngine.Table("user_repository").Where("name = ? AND parent_id = ? AND user_identity = ?", req.Name, req.ParentId, owner).Count(new(models.UserRepository))
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +56,7 @@ func (l *UserFolderCreateLogic) UserFolderCreate(req *types.UserFolderCreateRequ
 	// 创建文件夹
 	data := &models.UserRepository{
 		Identity:     utils.UUID(),
-		UserIdentity: userIdentity,
+		UserIdentity: owner,
 		ParentId:     req.ParentId,
 		Name:         req.Name,
 		Status:       common.StatusActive,
@@ -65,6 +71,7 @@ func (l *UserFolderCreateLogic) UserFolderCreate(req *types.UserFolderCreateRequ
 			return nil, err
 		}
 	}
+	recordFileEvent(l.ctx, l.svcCtx, common.EventFolderCreate, owner, req.ParentId, "", fmt.Sprintf("name=%s parent_id=%d", req.Name, req.ParentId))
 
 	return &types.UserFolderCreateResponse{Id: int64(data.Id), Identity: data.Identity}, nil
 }
@@ -39,6 +39,15 @@ func (l *UserFolderDeleteLogic) UserFolderDelete(req *types.UserFolderDeleteRequ
 	if !ok {
 		return nil, errors.New("用户身份验证失败")
 	}
+	// 群组空间中的文件需要编辑权限，删除后计入群组回收站
+	node, err := spaceOfNode(l.svcCtx, userIdentity, req.Identity, common.GroupRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if isGroupRoot(node, userIdentity) {
+		return nil, errors.New("群组根目录不能删除")
+	}
+	owner := node.UserIdentity
 
 	// 使用递归 CTE 一次性查询所有子项
 	sql := `
@@ -60,7 +69,7 @@ func (l *UserFolderDeleteLogic) UserFolderDelete(req *types.UserFolderDeleteRequ
     `
 
 	var idsToDelete []string
-	err = l.svcCtx.DBEngine.SQL(sql, req.Identity, userIdentity, userIdentity).Find(&idsToDelete)
+	err = l.svcCtx.DBEngine.SQL(sql, req.Identity, owner, owner).Find(&idsToDelete)
 	if err != nil {
 		return nil, err
 	}
@@ -69,13 +78,24 @@ func (l *UserFolderDeleteLogic) UserFolderDelete(req *types.UserFolderDeleteRequ
 		return nil, errors.New("文件或文件夹不存在")
 	}
 
+	// 统计将移入回收站的文件大小，用于调整存储用量
+	args := []interface{}{owner, common.StatusDeleted}
+	for _, id := range idsToDelete {
+		args = append(args, id)
+	}
+	sizes, err := utils.UserFileSizes(l.svcCtx.DBEngine,
+		"ur.user_identity = ? AND (ur.status != ? OR ur.status IS NULL) AND ur.identity IN ("+utils.InPlaceholders(len(idsToDelete))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	nowStr := now.Format(common.DataTimeFormat)
 	expireStr := now.Add(utils.RecycleTTL()).Format(common.DataTimeFormat)
 	affected, err := l.svcCtx.DBEngine.
 		Table("user_repository").
 		In("identity", idsToDelete).
-		Where("user_identity = ?", userIdentity).
+		Where("user_identity = ?", owner).
 		Update(map[string]any{
 			"status":     common.StatusDeleted,
 			"deleted_at": nowStr,
@@ -88,24 +108,24 @@ func (l *UserFolderDeleteLogic) UserFolderDelete(req *types.UserFolderDeleteRequ
 	}
 
 	if affected > 0 {
+		if size := sizes[owner]; size > 0 {
+			if err := utils.AddUsage(l.svcCtx.DBEngine, owner, -size, size); err != nil {
+				l.Errorf("update usage failed user=%s err=%v", owner, err)
+			}
+		}
 		var repos []models.UserRepository
 		_ = l.svcCtx.DBEngine.Table("user_repository").In("identity", idsToDelete).Find(&repos)
-		logs := make([]models.FileEventLog, 0, len(repos))
+		logs := make([]*models.FileEventLog, 0, len(repos))
 		repoSet := map[string]struct{}{}
 		for _, item := range repos {
 			if item.RepositoryIdentity != "" {
 				repoSet[item.RepositoryIdentity] = struct{}{}
 			}
-			logs = append(logs, models.FileEventLog{
-				Identity:           utils.UUID(),
-				RepositoryIdentity: item.RepositoryIdentity,
-				UserIdentity:       userIdentity,
-				EventType:          common.EventDelete,
-			})
-		}
-		if len(logs) > 0 {
-			_, _ = l.svcCtx.DBEngine.Insert(&logs)
+			event := fileEvent(l.ctx, common.EventDelete, owner, item.RepositoryIdentity, "name="+item.Name)
+			event.FolderId = item.ParentId
+			logs = append(logs, event)
 		}
+		recordFileEvents(l.ctx, l.svcCtx, logs...)
 		for repoID := range repoSet {
 			cnt, err := l.svcCtx.DBEngine.Table("user_repository").
 				Where("repository_identity = ? AND (status != ? OR status IS NULL)", repoID, common.StatusDeleted).
@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UserQuotaLogic 存储空间用量逻辑。
+type UserQuotaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUserQuotaLogic 创建存储空间用量逻辑。
+func NewUserQuotaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserQuotaLogic {
+	return &UserQuotaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserQuota 查询当前用户的存储配额与用量。
+func (l *UserQuotaLogic) UserQuota(req *types.UserQuotaRequest) (resp *types.UserQuotaResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	quota, err := utils.LoadQuota(l.svcCtx.DBEngine, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return &types.UserQuotaResponse{
+		QuotaBytes: quota.QuotaBytes,
+		UsedBytes:  quota.UsedBytes,
+		TrashBytes: quota.TrashBytes,
+		Unlimited:  quota.QuotaBytes <= 0,
+	}, nil
+}
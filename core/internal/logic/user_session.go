@@ -0,0 +1,95 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxUserAgentLength 会话记录中 User-Agent 的最大保存长度。
+const maxUserAgentLength = 512
+
+// createSession 为本次登录创建会话，记录设备、IP 与 User-Agent，返回会话标识。
+func createSession(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) (string, error) {
+	clientIP, _ := ctx.Value("client_ip").(string)
+	userAgent, _ := ctx.Value("user_agent").(string)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	ttl := utils.RefreshTokenTTL()
+	now := time.Now()
+	session := &models.UserSession{
+		Identity:     utils.UUID(),
+		UserIdentity: userIdentity,
+		Device:       utils.DeviceName(userAgent),
+		Ip:           clientIP,
+		UserAgent:    userAgent,
+		LastSeenAt:   now.Format(common.DataTimeFormat),
+		ExpireAt:     now.Add(ttl).Format(common.DataTimeFormat),
+	}
+	if _, err := svcCtx.DBEngine.Insert(session); err != nil {
+		return "", err
+	}
+	if err := svcCtx.RedisClient.Set(ctx, utils.SessionKey(session.Identity), userIdentity, ttl).Err(); err != nil {
+		return "", err
+	}
+	return session.Identity, nil
+}
+
+// renewSession 刷新令牌时续期会话，会话已注销或不属于该用户时返回错误。
+func renewSession(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, sid string) error {
+	owner, err := svcCtx.RedisClient.Get(ctx, utils.SessionKey(sid)).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && owner != userIdentity) {
+		return errors.New("会话已失效，请重新登录")
+	}
+	if err != nil {
+		return err
+	}
+	ttl := utils.RefreshTokenTTL()
+	if err := svcCtx.RedisClient.Expire(ctx, utils.SessionKey(sid), ttl).Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	update := &models.UserSession{
+		LastSeenAt: now.Format(common.DataTimeFormat),
+		ExpireAt:   now.Add(ttl).Format(common.DataTimeFormat),
+	}
+	cols := []string{"last_seen_at", "expire_at"}
+	if clientIP, _ := ctx.Value("client_ip").(string); clientIP != "" {
+		update.Ip = clientIP
+		cols = append(cols, "ip")
+	}
+	_, err = svcCtx.DBEngine.Where("identity = ?", sid).Cols(cols...).Update(update)
+	return err
+}
+
+// revokeSessions 注销指定会话：删除 Redis 中的会话键使其令牌立即失效，并软删除会话记录。
+func revokeSessions(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string, sids ...string) (int64, error) {
+	if len(sids) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, 0, len(sids))
+	for _, sid := range sids {
+		keys = append(keys, utils.SessionKey(sid))
+	}
+	if err := svcCtx.RedisClient.Del(ctx, keys...).Err(); err != nil {
+		return 0, err
+	}
+	return svcCtx.DBEngine.Where("user_identity = ?", userIdentity).In("identity", sids).Delete(new(models.UserSession))
+}
+
+// revokeUserSessions 注销用户的全部登录会话（刷新令牌随会话失效），用于禁用账号或修改、重置密码。
+func revokeUserSessions(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) (int64, error) {
+	sids := make([]string, 0)
+	if err := svcCtx.DBEngine.Table(new(models.UserSession)).Where("user_identity = ?", userIdentity).Cols("identity").Find(&sids); err != nil {
+		return 0, err
+	}
+	return revokeSessions(ctx, svcCtx, userIdentity, sids...)
+}
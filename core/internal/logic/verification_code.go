@@ -0,0 +1,222 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/utils"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// verificationCodeTTL 验证码有效期。
+	verificationCodeTTL = 5 * time.Minute
+	// verificationCooldown 同一邮箱两次发送的最小间隔。
+	verificationCooldown = time.Minute
+	// verificationSendWindow 发送次数统计的滑动窗口。
+	verificationSendWindow = time.Hour
+	// verificationEmailLimit 同一邮箱在窗口内允许的发送次数。
+	verificationEmailLimit = 5
+	// verificationIPLimit 同一 IP 在窗口内允许的发送次数。
+	verificationIPLimit = 20
+	// verificationMaxAttempts 单个验证码允许的校验次数，超过后作废。
+	verificationMaxAttempts = 5
+)
+
+// verificationPurposes 验证码用途，不同用途的验证码互不通用。
+var verificationPurposes = map[string]bool{
+	common.CodePurposeRegister:    true,
+	common.CodePurposeReset:       true,
+	common.CodePurposeChangeEmail: true,
+}
+
+// normalizeEmail 统一邮箱格式（去空白、转小写）。
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// verificationCodeKey 返回验证码在 Redis 中的键。
+func verificationCodeKey(purpose, email string) string {
+	return fmt.Sprintf("verification_code:%s:%s", purpose, email)
+}
+
+// verificationAttemptsKey 返回验证码校验次数的有序集合键。
+func verificationAttemptsKey(purpose, email string) string {
+	return fmt.Sprintf("verification_attempts:%s:%s", purpose, email)
+}
+
+// checkVerificationSendLimit 校验邮箱冷却时间以及邮箱、IP 在窗口内的发送次数，通过后占用本次发送额度。
+func checkVerificationSendLimit(ctx context.Context, svcCtx *svc.ServiceContext, email, ip string) error {
+	rdb := svcCtx.RedisClient
+	if ip != "" {
+		n, err := windowCount(ctx, rdb, "verification_send:ip:"+ip, verificationSendWindow)
+		if err != nil {
+			return err
+		}
+		if n >= verificationIPLimit {
+			return errors.New("验证码发送次数过多，请稍后再试")
+		}
+	}
+	n, err := windowCount(ctx, rdb, "verification_send:email:"+email, verificationSendWindow)
+	if err != nil {
+		return err
+	}
+	if n >= verificationEmailLimit {
+		return errors.New("该邮箱验证码发送次数过多，请 1 小时后再试")
+	}
+	cooldownKey := "verification_cooldown:" + email
+	until := time.Now().Add(verificationCooldown).UnixMilli()
+	ok, err := rdb.SetNX(ctx, cooldownKey, until, verificationCooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		wait := verificationCooldown
+		if val, err := rdb.Get(ctx, cooldownKey).Result(); err == nil {
+			if ms, err := strconv.ParseInt(val, 10, 64); err == nil {
+				wait = time.Until(time.UnixMilli(ms))
+			}
+		}
+		return fmt.Errorf("验证码发送过于频繁，请 %d 秒后再试", int(wait.Seconds())+1)
+	}
+	if ip != "" {
+		if err := windowAdd(ctx, rdb, "verification_send:ip:"+ip, verificationSendWindow); err != nil {
+			return err
+		}
+	}
+	return windowAdd(ctx, rdb, "verification_send:email:"+email, verificationSendWindow)
+}
+
+// releaseVerificationCooldown 发送失败时解除冷却，允许用户立即重试。
+func releaseVerificationCooldown(ctx context.Context, svcCtx *svc.ServiceContext, email string) {
+	_ = svcCtx.RedisClient.Del(ctx, "verification_cooldown:"+email).Err()
+}
+
+// issueVerificationCode 生成并保存指定用途的验证码，旧验证码与校验次数一并重置。
+func issueVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	if err := svcCtx.RedisClient.Set(ctx, verificationCodeKey(purpose, email), code, verificationCodeTTL).Err(); err != nil {
+		return "", err
+	}
+	_ = svcCtx.RedisClient.Del(ctx, verificationAttemptsKey(purpose, email)).Err()
+	return code, nil
+}
+
+// consumeVerificationCode 校验并销毁验证码；错误次数达到上限后验证码作废。
+func consumeVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email, code string) error {
+	return consumeCappedCode(ctx, svcCtx.RedisClient, verificationCodeKey(purpose, email), verificationAttemptsKey(purpose, email), code, verificationCodeTTL)
+}
+
+// checkVerificationCode 只校验指定用途的验证码（计入错误次数）而不销毁，需同时校验多个验证码时配合 burnVerificationCode 使用。
+func checkVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email, code string) error {
+	return checkCappedCode(ctx, svcCtx.RedisClient, verificationCodeKey(purpose, email), verificationAttemptsKey(purpose, email), code, verificationCodeTTL)
+}
+
+// burnVerificationCode 销毁已通过校验的验证码，验证码已被他人使用时返回错误。
+func burnVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email string) error {
+	return burnCappedCode(ctx, svcCtx.RedisClient, verificationCodeKey(purpose, email), verificationAttemptsKey(purpose, email))
+}
+
+// consumeCappedCode 校验并销毁 key 中保存的一次性验证码，attemptsKey 记录窗口内的校验次数，超过 verificationMaxAttempts 后验证码作废。
+func consumeCappedCode(ctx context.Context, rdb svc.RedisClient, key, attemptsKey, code string, window time.Duration) error {
+	if err := checkCappedCode(ctx, rdb, key, attemptsKey, code, window); err != nil {
+		return err
+	}
+	return burnCappedCode(ctx, rdb, key, attemptsKey)
+}
+
+// checkCappedCode 校验 key 中保存的验证码并计入校验次数，超过 verificationMaxAttempts 后验证码作废。
+func checkCappedCode(ctx context.Context, rdb svc.RedisClient, key, attemptsKey, code string, window time.Duration) error {
+	cached, err := rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && cached == "") {
+		return errors.New("验证码已过期或无效")
+	}
+	if err != nil {
+		return err
+	}
+	if err := windowAdd(ctx, rdb, attemptsKey, window); err != nil {
+		return err
+	}
+	attempts, err := rdb.ZCard(ctx, attemptsKey).Result()
+	if err != nil {
+		return err
+	}
+	if attempts > verificationMaxAttempts {
+		_ = rdb.Del(ctx, key, attemptsKey).Err()
+		return errors.New("验证码错误次数过多，请重新获取")
+	}
+	if subtle.ConstantTimeCompare([]byte(cached), []byte(strings.TrimSpace(code))) != 1 {
+		return errors.New("验证码错误")
+	}
+	return nil
+}
+
+// burnCappedCode 删除验证码，删除成功才视为本次使用者，保证验证码只能使用一次。
+func burnCappedCode(ctx context.Context, rdb svc.RedisClient, key, attemptsKey string) error {
+	deleted, err := rdb.Del(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("验证码已过期或无效")
+	}
+	_ = rdb.Del(ctx, attemptsKey).Err()
+	return nil
+}
+
+// requestLanguage 返回请求的 Accept-Language 头，用于选择邮件语言。
+func requestLanguage(ctx context.Context) string {
+	lang, _ := ctx.Value("accept_language").(string)
+	return lang
+}
+
+// sendVerificationEmail 按请求语言发送验证码邮件，重置密码使用单独的模板；邮件功能关闭时仅记录日志。
+func sendVerificationEmail(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email, code string) error {
+	if !utils.EmailEnabled() {
+		logx.Infof("邮箱发送已禁用，验证码: %s", code)
+		return nil
+	}
+	template := common.MailVerification
+	if purpose == common.CodePurposeReset {
+		template = common.MailPasswordReset
+	}
+	return mq.SendMail(ctx, svcCtx, email, template, utils.MailLang(requestLanguage(ctx)), map[string]any{
+		"Code":    code,
+		"Purpose": purpose,
+		"Minutes": int(verificationCodeTTL.Minutes()),
+	})
+}
+
+// windowCount 返回滑动窗口内的记录数。
+func windowCount(ctx context.Context, rdb svc.RedisClient, key string, window time.Duration) (int64, error) {
+	min := strconv.FormatInt(time.Now().Add(-window).UnixMilli(), 10)
+	if err := rdb.ZRemRangeByScore(ctx, key, "-inf", "("+min).Err(); err != nil {
+		return 0, err
+	}
+	return rdb.ZCard(ctx, key).Result()
+}
+
+// windowAdd 向滑动窗口追加一条记录。
+func windowAdd(ctx context.Context, rdb svc.RedisClient, key string, window time.Duration) error {
+	now := time.Now()
+	if err := rdb.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: utils.UUID()}).Err(); err != nil {
+		return err
+	}
+	return rdb.Expire(ctx, key, window).Err()
+}
@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+)
+
+// webhookURLMaxLen 回调地址最大长度，与 webhook.url 列宽一致。
+const webhookURLMaxLen = 1024
+
+// webhookSecretMinLen、webhookSecretMaxLen 自定义签名密钥的长度范围（字符数）。
+const (
+	webhookSecretMinLen = 16
+	webhookSecretMaxLen = 128
+)
+
+// maxWebhooksPerUser 每个用户可创建的 webhook 数量上限（含全站 webhook）。
+const maxWebhooksPerUser = 20
+
+// validWebhookURL 校验回调地址，仅支持 http 与 https 的绝对地址，且主机须解析为公网地址（投递时拨号前会再次校验）。
+func validWebhookURL(ctx context.Context, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("回调地址不能为空")
+	}
+	if len(raw) > webhookURLMaxLen {
+		return "", errors.New("回调地址过长")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.New("回调地址须为 http 或 https 地址")
+	}
+	if err := utils.CheckWebhookHost(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, utils.ErrWebhookPrivateAddress) {
+			return "", errors.New("回调地址不能指向回环、内网或链路本地地址")
+		}
+		return "", errors.New("回调地址无法解析")
+	}
+	return raw, nil
+}
+
+// normalizeWebhookEvents 校验并去重订阅的事件类型，返回逗号分隔的字符串；为空表示订阅全部事件。
+func normalizeWebhookEvents(events []string) (string, error) {
+	supported := map[string]bool{}
+	for _, e := range common.WebhookEvents {
+		supported[e] = true
+	}
+	out := make([]string, 0, len(events))
+	seen := map[string]bool{}
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		if !supported[e] {
+			return "", errors.New("不支持的事件类型: " + e)
+		}
+		seen[e] = true
+		out = append(out, e)
+	}
+	return strings.Join(out, ","), nil
+}
+
+// splitWebhookEvents 将存储的事件类型字符串拆分为列表。
+func splitWebhookEvents(events string) []string {
+	if events == "" {
+		return []string{}
+	}
+	return strings.Split(events, ",")
+}
+
+// validWebhookSecret 校验自定义签名密钥长度。
+func validWebhookSecret(secret string) error {
+	if n := utf8.RuneCountInString(secret); n < webhookSecretMinLen || n > webhookSecretMaxLen {
+		return errors.New("签名密钥长度须为 16 到 128 个字符")
+	}
+	return nil
+}
+
+// requireWebhook 查询 webhook 并校验当前用户可以管理：全站 webhook 需要 webhooks:manage 权限，其他仅创建者可管理；无权访问视为不存在。
+func requireWebhook(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, identity string) (*models.Webhook, error) {
+	notFound := errors.New("webhook 不存在")
+	if identity == "" {
+		return nil, errors.New("webhook 标识不能为空")
+	}
+	hook := new(models.Webhook)
+	has, err := svcCtx.DBEngine.Where("identity = ?", identity).Get(hook)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, notFound
+	}
+	if hook.SpaceIdentity == "" {
+		if _, err := requirePermission(ctx, svcCtx, common.PermWebhooksManage); err != nil {
+			return nil, notFound
+		}
+		return hook, nil
+	}
+	if hook.UserIdentity != userIdentity {
+		return nil, notFound
+	}
+	return hook, nil
+}
+
+// nodeFolder 查询文件或文件夹所在的父文件夹，查询失败或不存在时返回 0（根目录）。
+func nodeFolder(svcCtx *svc.ServiceContext, identity string) int64 {
+	if identity == "" {
+		return 0
+	}
+	node := new(models.UserRepository)
+	if has, err := svcCtx.DBEngine.Unscoped().Where("identity = ?", identity).Get(node); err != nil || !has {
+		return 0
+	}
+	return node.ParentId
+}
+
+// webhookDeliveryItem 将投递记录转换为接口返回结构。
+func webhookDeliveryItem(delivery *models.WebhookDelivery) *types.WebhookDeliveryItem {
+	return &types.WebhookDeliveryItem{
+		Identity:      delivery.Identity,
+		EventIdentity: delivery.EventIdentity,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		ResponseCode:  delivery.ResponseCode,
+		Error:         delivery.Error,
+		DeliveredAt:   delivery.DeliveredAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+}
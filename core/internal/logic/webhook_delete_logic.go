@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// WebhookDeleteLogic 删除 webhook逻辑。
+type WebhookDeleteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewWebhookDeleteLogic 创建删除 webhook逻辑。
+func NewWebhookDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookDeleteLogic {
+	return &WebhookDeleteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WebhookDelete 删除 webhook，不再产生新的投递；排队中的投递在发送时标记为失败。
+func (l *WebhookDeleteLogic) WebhookDelete(req *types.WebhookDeleteRequest) (resp *types.WebhookDeleteResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	hook, err := requireWebhook(l.ctx, l.svcCtx, userIdentity, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.svcCtx.DBEngine.Where("identity = ?", hook.Identity).Delete(new(models.Webhook)); err != nil {
+		return nil, err
+	}
+	return &types.WebhookDeleteResponse{}, nil
+}
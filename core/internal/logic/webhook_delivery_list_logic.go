@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// WebhookDeliveryListLogic webhook 投递记录逻辑。
+type WebhookDeliveryListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewWebhookDeliveryListLogic 创建webhook 投递记录逻辑。
+func NewWebhookDeliveryListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookDeliveryListLogic {
+	return &WebhookDeliveryListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WebhookDeliveryList 按时间倒序获取 webhook 的投递记录，可按状态筛选。
+func (l *WebhookDeliveryListLogic) WebhookDeliveryList(req *types.WebhookDeliveryListRequest) (resp *types.WebhookDeliveryListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	hook, err := requireWebhook(l.ctx, l.svcCtx, userIdentity, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.PageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	query := l.svcCtx.DBEngine.Where("webhook_identity = ?", hook.Identity)
+	if req.Status != "" {
+		query = query.And("status = ?", req.Status)
+	}
+	var deliveries []models.WebhookDelivery
+	cnt, err := query.Desc("id").Limit(size, (page-1)*size).FindAndCount(&deliveries)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*types.WebhookDeliveryItem, 0, len(deliveries))
+	for i := range deliveries {
+		list = append(list, webhookDeliveryItem(&deliveries[i]))
+	}
+	return &types.WebhookDeliveryListResponse{List: list, Count: cnt}, nil
+}
@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// WebhookListLogic webhook 列表逻辑。
+type WebhookListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewWebhookListLogic 创建webhook 列表逻辑。
+func NewWebhookListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookListLogic {
+	return &WebhookListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WebhookList 获取当前用户创建的 webhook；global 为 true 时获取全部全站 webhook，需要 webhooks:manage 权限。签名密钥不返回。
+func (l *WebhookListLogic) WebhookList(req *types.WebhookListRequest) (resp *types.WebhookListResponse, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.PageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	query := l.svcCtx.DBEngine.Where("user_identity = ? AND space_identity != ''", userIdentity)
+	if req.Global {
+		if _, err := requirePermission(l.ctx, l.svcCtx, common.PermWebhooksManage); err != nil {
+			return nil, err
+		}
+		query = l.svcCtx.DBEngine.Where("space_identity = ''")
+	}
+	var hooks []models.Webhook
+	cnt, err := query.Desc("id").Limit(size, (page-1)*size).FindAndCount(&hooks)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*types.WebhookItem, 0, len(hooks))
+	for _, hook := range hooks {
+		list = append(list, &types.WebhookItem{
+			Identity:      hook.Identity,
+			Url:           hook.Url,
+			EventTypes:    splitWebhookEvents(hook.EventTypes),
+			SpaceIdentity: hook.SpaceIdentity,
+			ParentId:      hook.ParentId,
+			Global:        hook.SpaceIdentity == "",
+			CreatedAt:     hook.CreatedAt,
+		})
+	}
+	return &types.WebhookListResponse{List: list, Count: cnt}, nil
+}
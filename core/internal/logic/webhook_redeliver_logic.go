@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/mq"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// WebhookRedeliverLogic webhook 重新投递逻辑。
+type WebhookRedeliverLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewWebhookRedeliverLogic 创建webhook 重新投递逻辑。
+func NewWebhookRedeliverLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookRedeliverLogic {
+	return &WebhookRedeliverLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WebhookRedeliver 以原请求体重新投递一次，生成新的投递记录并同步返回投递结果；手动重新投递失败不自动重试。
+func (l *WebhookRedeliverLogic) WebhookRedeliver(req *types.WebhookRedeliverRequest) (resp *types.WebhookDeliveryItem, err error) {
+	userIdentity, ok := l.ctx.Value("user_identity").(string)
+	if !ok {
+		return nil, errors.New("用户身份验证失败")
+	}
+	notFound := errors.New("投递记录不存在")
+	if req.Identity == "" {
+		return nil, notFound
+	}
+	origin := new(models.WebhookDelivery)
+	has, err := l.svcCtx.DBEngine.Where("identity = ?", req.Identity).Get(origin)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, notFound
+	}
+	if _, err := requireWebhook(l.ctx, l.svcCtx, userIdentity, origin.WebhookIdentity); err != nil {
+		return nil, notFound
+	}
+	delivery := &models.WebhookDelivery{
+		Identity:        utils.UUID(),
+		WebhookIdentity: origin.WebhookIdentity,
+		EventIdentity:   origin.EventIdentity,
+		EventType:       origin.EventType,
+		Payload:         origin.Payload,
+		Status:          common.WebhookStatusPending,
+	}
+	if _, err := l.svcCtx.DBEngine.Insert(delivery); err != nil {
+		return nil, err
+	}
+	result, err := mq.AttemptWebhookDelivery(l.ctx, l.svcCtx, delivery.Identity, 1)
+	if err != nil {
+		return nil, err
+	}
+	return webhookDeliveryItem(result), nil
+}
@@ -8,14 +8,24 @@ import (
 
 	"cloud_disk/core/utils"
 
+	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
+// RevocationChecker 判断令牌（jti）是否已被吊销。
+type RevocationChecker func(ctx context.Context, jti string) (bool, error)
+
+// SessionChecker 判断登录会话是否仍然有效。
+type SessionChecker func(ctx context.Context, userIdentity, sid string) (bool, error)
+
 // FileAuthMiddleware 文件上传专用的认证中间件。
 // 只从 Header 和 Query 参数中获取 token，不读取表单。
 type FileAuthMiddleware struct {
 	accessSecret string
 	accessExpire int64
+	isRevoked    RevocationChecker
+	isActive     SessionChecker
+	resolvePAT   PATResolver
 }
 
 // NewFileAuthMiddleware 创建文件上传认证中间件。
@@ -26,6 +36,24 @@ func NewFileAuthMiddleware(accessSecret string, accessExpire int64) *FileAuthMid
 	}
 }
 
+// WithRevocationCheck 设置令牌吊销检查，设置后不带 jti 的旧令牌将被拒绝。
+func (m *FileAuthMiddleware) WithRevocationCheck(checker RevocationChecker) *FileAuthMiddleware {
+	m.isRevoked = checker
+	return m
+}
+
+// WithSessionCheck 设置登录会话检查，设置后不带会话标识的旧令牌将被拒绝。
+func (m *FileAuthMiddleware) WithSessionCheck(checker SessionChecker) *FileAuthMiddleware {
+	m.isActive = checker
+	return m
+}
+
+// WithPersonalAccessTokens 设置个人访问令牌解析，设置后接受 cdp_ 前缀的令牌并按路由校验权限范围。
+func (m *FileAuthMiddleware) WithPersonalAccessTokens(resolver PATResolver) *FileAuthMiddleware {
+	m.resolvePAT = resolver
+	return m
+}
+
 // Handle 实现认证处理。
 func (m *FileAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -53,6 +81,12 @@ func (m *FileAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// 个人访问令牌走单独的校验流程
+		if m.resolvePAT != nil && utils.IsPersonalAccessToken(token) {
+			m.handlePersonalAccessToken(w, r, token, next)
+			return
+		}
+
 		// 5. 验证 token
 		claims, err := utils.ParseToken(token, m.accessSecret, m.accessExpire)
 		if err != nil {
@@ -60,10 +94,53 @@ func (m *FileAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// 6. 将用户信息存入 context
+		// 6. 检查 token 是否已被吊销
+		if m.isRevoked != nil {
+			if claims.ID == "" {
+				httpx.ErrorCtx(r.Context(), w, errors.New("token 无效或已过期"))
+				return
+			}
+			revoked, err := m.isRevoked(r.Context(), claims.ID)
+			if err != nil {
+				logx.WithContext(r.Context()).Errorf("token revocation check failed: %v", err)
+				httpx.ErrorCtx(r.Context(), w, errors.New("token 校验失败，请稍后重试"))
+				return
+			}
+			if revoked {
+				httpx.ErrorCtx(r.Context(), w, errors.New("token 已失效，请重新登录"))
+				return
+			}
+		}
+
+		// 7. 检查登录会话是否已被注销
+		if m.isActive != nil {
+			if claims.SessionId == "" {
+				httpx.ErrorCtx(r.Context(), w, errors.New("token 无效或已过期"))
+				return
+			}
+			active, err := m.isActive(r.Context(), claims.Identity, claims.SessionId)
+			if err != nil {
+				logx.WithContext(r.Context()).Errorf("session check failed: %v", err)
+				httpx.ErrorCtx(r.Context(), w, errors.New("token 校验失败，请稍后重试"))
+				return
+			}
+			if !active {
+				httpx.ErrorCtx(r.Context(), w, errors.New("会话已失效，请重新登录"))
+				return
+			}
+		}
+
+		// 8. 将用户信息存入 context
 		ctx := context.WithValue(r.Context(), "user_id", claims.Id)
 		ctx = context.WithValue(ctx, "user_identity", claims.Identity)
 		ctx = context.WithValue(ctx, "user_name", claims.Name)
+		ctx = context.WithValue(ctx, "token_id", claims.ID)
+		ctx = context.WithValue(ctx, "session_id", claims.SessionId)
+		ctx = context.WithValue(ctx, "client_ip", utils.ClientIP(r))
+		ctx = context.WithValue(ctx, "accept_language", r.Header.Get("Accept-Language"))
+		if claims.ExpiresAt != nil {
+			ctx = context.WithValue(ctx, "token_expire", claims.ExpiresAt.Time)
+		}
 		r = r.WithContext(ctx)
 
 		// 打印日志（可选）
@@ -73,3 +150,30 @@ func (m *FileAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 		next(w, r)
 	}
 }
+
+// handlePersonalAccessToken 校验个人访问令牌及其权限范围。
+func (m *FileAuthMiddleware) handlePersonalAccessToken(w http.ResponseWriter, r *http.Request, token string, next http.HandlerFunc) {
+	pat, err := m.resolvePAT(r.Context(), token)
+	if err != nil {
+		logx.WithContext(r.Context()).Errorf("personal access token resolve failed: %v", err)
+		httpx.ErrorCtx(r.Context(), w, errors.New("token 校验失败，请稍后重试"))
+		return
+	}
+	if pat == nil {
+		httpx.ErrorCtx(r.Context(), w, errors.New("token 无效或已过期"))
+		return
+	}
+	scope, ok := requiredScope(r.URL.Path)
+	if !ok || !scopeAllows(pat.Scopes, scope) {
+		forbidden(w, "访问令牌权限不足")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "user_id", pat.UserId)
+	ctx = context.WithValue(ctx, "user_identity", pat.UserIdentity)
+	ctx = context.WithValue(ctx, "user_name", pat.UserName)
+	ctx = context.WithValue(ctx, "token_scopes", pat.Scopes)
+	ctx = context.WithValue(ctx, "client_ip", utils.ClientIP(r))
+	ctx = context.WithValue(ctx, "accept_language", r.Header.Get("Accept-Language"))
+	next(w, r.WithContext(ctx))
+}
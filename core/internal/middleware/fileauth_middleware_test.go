@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"cloud_disk/core/utils"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // TestFileAuthMiddlewareMissingToken 验证缺失 token 的处理。
@@ -113,3 +115,145 @@ func TestFileAuthMiddlewareQueryTokenOK(t *testing.T) {
 		t.Fatalf("status mismatch: %d", rec.Code)
 	}
 }
+
+// TestFileAuthMiddlewareRevokedToken 验证已吊销 token 被拒绝。
+func TestFileAuthMiddlewareRevokedToken(t *testing.T) {
+	secret := "secret"
+	token, claims, err := utils.GenAccessToken(utils.JwtPayLoad{Id: 1, Identity: "u-1", Name: "n"}, secret, time.Minute)
+	if err != nil {
+		t.Fatalf("token gen failed: %v", err)
+	}
+	revoked := map[string]bool{claims.ID: true}
+	m := NewFileAuthMiddleware(secret, 1).WithRevocationCheck(func(ctx context.Context, jti string) (bool, error) {
+		return revoked[jti], nil
+	})
+	next := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	m.Handle(next)(rec, req)
+	if rec.Code == http.StatusNoContent {
+		t.Fatal("revoked token should be rejected")
+	}
+
+	delete(revoked, claims.ID)
+	rec = httptest.NewRecorder()
+	m.Handle(next)(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status mismatch: %d", rec.Code)
+	}
+}
+
+// TestFileAuthMiddlewarePersonalAccessToken 验证个人访问令牌按路由校验权限范围。
+func TestFileAuthMiddlewarePersonalAccessToken(t *testing.T) {
+	m := NewFileAuthMiddleware("secret", 1).WithPersonalAccessTokens(func(ctx context.Context, token string) (*PersonalAccessToken, error) {
+		if token != "cdp_read" {
+			return nil, nil
+		}
+		return &PersonalAccessToken{UserId: 1, UserIdentity: "u-1", UserName: "n", Scopes: []string{"files:read"}}, nil
+	})
+	var identity string
+	next := func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = r.Context().Value("user_identity").(string)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	cases := []struct {
+		path  string
+		token string
+		code  int
+	}{
+		{path: "/api/file/user/list", token: "cdp_read", code: http.StatusNoContent},
+		{path: "/api/file/upload", token: "cdp_read", code: http.StatusForbidden},
+		{path: "/api/users/logout", token: "cdp_read", code: http.StatusForbidden},
+		{path: "/api/file/user/list", token: "cdp_unknown", code: http.StatusBadRequest},
+		// 路由按规范化路径分发，借 ".." 穿越到其他分组的请求必须被拒绝
+		{path: "/api/file/user/list/../../../users/tokens/create", token: "cdp_read", code: http.StatusForbidden},
+		{path: "/api/file/url/../upload", token: "cdp_read", code: http.StatusForbidden},
+		{path: "/api/share/get/../../admin/users/role", token: "cdp_read", code: http.StatusForbidden},
+		{path: "/api/file//user/list", token: "cdp_read", code: http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, c.path, nil)
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		rec := httptest.NewRecorder()
+		m.Handle(next)(rec, req)
+		if rec.Code != c.code {
+			t.Fatalf("%s %s status mismatch: %d", c.path, c.token, rec.Code)
+		}
+	}
+	if identity != "u-1" {
+		t.Fatalf("identity mismatch: %q", identity)
+	}
+}
+
+// TestFileAuthMiddlewareSessionCheck 验证已注销会话与缺少会话标识的 token 被拒绝。
+func TestFileAuthMiddlewareSessionCheck(t *testing.T) {
+	secret := "secret"
+	active := map[string]bool{"s-1": true}
+	m := NewFileAuthMiddleware(secret, 1).WithSessionCheck(func(ctx context.Context, userIdentity, sid string) (bool, error) {
+		return userIdentity == "u-1" && active[sid], nil
+	})
+	var sid string
+	next := func(w http.ResponseWriter, r *http.Request) {
+		sid, _ = r.Context().Value("session_id").(string)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	serve := func(payload utils.JwtPayLoad) int {
+		token, _, err := utils.GenAccessToken(payload, secret, time.Minute)
+		if err != nil {
+			t.Fatalf("token gen failed: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		m.Handle(next)(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(utils.JwtPayLoad{Id: 1, Identity: "u-1", SessionId: "s-1"}); code != http.StatusNoContent || sid != "s-1" {
+		t.Fatalf("active session rejected: %d %q", code, sid)
+	}
+	if code := serve(utils.JwtPayLoad{Id: 1, Identity: "u-1"}); code == http.StatusNoContent {
+		t.Fatal("token without session should be rejected")
+	}
+	delete(active, "s-1")
+	if code := serve(utils.JwtPayLoad{Id: 1, Identity: "u-1", SessionId: "s-1"}); code == http.StatusNoContent {
+		t.Fatal("revoked session should be rejected")
+	}
+}
+
+// TestRBACMiddleware 验证管理路由按权限放行，未声明的路由、非规范路径与无权限用户返回 403。
+func TestRBACMiddleware(t *testing.T) {
+	grants := map[string]bool{"u-1|users:read": true, "u-2|audit:read": true}
+	m := NewRBACMiddleware(func(ctx context.Context, userIdentity, permission string) (bool, error) {
+		return grants[userIdentity+"|"+permission], nil
+	})
+	cases := []struct {
+		user, path string
+		want       int
+	}{
+		{"u-1", "/api/admin/users", http.StatusNoContent},
+		{"u-1", "/api/admin/users/disable", http.StatusForbidden},
+		{"u-1", "/api/admin/unknown", http.StatusForbidden},
+		{"u-2", "/api/admin/users", http.StatusForbidden},
+		{"u-2", "/api/admin/audit", http.StatusNoContent},
+		{"u-1", "/api/admin/audit", http.StatusForbidden},
+		{"u-2", "/api/admin/audit/../users/disable", http.StatusForbidden},
+		{"u-2", "/api/admin//audit", http.StatusForbidden},
+		{"", "/api/admin/users", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		if c.user != "" {
+			req = req.WithContext(context.WithValue(req.Context(), "user_identity", c.user))
+		}
+		rec := httptest.NewRecorder()
+		m.Handle(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})(rec, req)
+		if rec.Code != c.want {
+			t.Fatalf("%s %s: expected %d, got %d", c.user, c.path, c.want, rec.Code)
+		}
+	}
+}
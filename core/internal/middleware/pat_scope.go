@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"cloud_disk/core/common"
+)
+
+// PersonalAccessToken 个人访问令牌解析结果。
+type PersonalAccessToken struct {
+	UserId       int
+	UserIdentity string
+	UserName     string
+	Scopes       []string
+}
+
+// PATResolver 解析个人访问令牌，令牌不存在、已吊销或已过期时返回 nil。
+type PATResolver func(ctx context.Context, token string) (*PersonalAccessToken, error)
+
+// patScopeRule 路由前缀与所需权限范围。
+type patScopeRule struct {
+	prefix string
+	scope  string
+}
+
+// patScopeRules 按路由分组声明个人访问令牌所需权限，按顺序匹配，越具体的前缀越靠前。
+// 未匹配的路由（账号、管理等）不允许使用个人访问令牌访问。
+var patScopeRules = []patScopeRule{
+	{prefix: "/api/file/drop/", scope: common.ScopeShareManage},
+	{prefix: "/api/file/user/list", scope: common.ScopeFilesRead},
+	{prefix: "/api/file/url", scope: common.ScopeFilesRead},
+	{prefix: "/api/file/", scope: common.ScopeFilesWrite},
+	{prefix: "/api/share/download", scope: common.ScopeFilesRead},
+	{prefix: "/api/share/get", scope: common.ScopeFilesRead},
+	{prefix: "/api/share/shared-with-me", scope: common.ScopeFilesRead},
+	{prefix: "/api/share/save", scope: common.ScopeFilesWrite},
+	{prefix: "/api/share/", scope: common.ScopeShareManage},
+}
+
+// requiredScope 返回访问路径所需的权限范围，未声明时返回 false。
+// 路由按规范化后的路径匹配，含 ".."、"." 或重复斜杠的路径一律拒绝，避免借路径穿越绕过前缀规则。
+func requiredScope(p string) (string, bool) {
+	if path.Clean(p) != p {
+		return "", false
+	}
+	for _, rule := range patScopeRules {
+		if strings.HasPrefix(p, rule.prefix) {
+			return rule.scope, true
+		}
+	}
+	return "", false
+}
+
+// scopeAllows 判断令牌权限是否满足要求，files:write 隐含 files:read。
+func scopeAllows(scopes []string, need string) bool {
+	for _, s := range scopes {
+		if s == need || (need == common.ScopeFilesRead && s == common.ScopeFilesWrite) {
+			return true
+		}
+	}
+	return false
+}
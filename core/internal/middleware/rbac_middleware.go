@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"cloud_disk/core/common"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// PermissionChecker 判断用户是否拥有指定管理权限。
+type PermissionChecker func(ctx context.Context, userIdentity, permission string) (bool, error)
+
+// permissionRule 路由前缀与所需管理权限。
+type permissionRule struct {
+	prefix     string
+	permission string
+}
+
+// permissionRules 声明管理接口所需权限，按顺序匹配，越具体的前缀越靠前。
+// 未声明的管理路由一律拒绝访问。
+var permissionRules = []permissionRule{
+	{prefix: "/api/admin/2fa/", permission: common.PermPoliciesManage},
+	{prefix: "/api/admin/audit", permission: common.PermAuditRead},
+	{prefix: "/api/admin/roles", permission: common.PermRolesManage},
+	{prefix: "/api/admin/users/", permission: common.PermUsersWrite},
+	{prefix: "/api/admin/users", permission: common.PermUsersRead},
+}
+
+// RBACMiddleware 基于角色权限的访问控制中间件，需挂在认证中间件之后。
+type RBACMiddleware struct {
+	hasPermission PermissionChecker
+}
+
+// NewRBACMiddleware 创建访问控制中间件。
+func NewRBACMiddleware(checker PermissionChecker) *RBACMiddleware {
+	return &RBACMiddleware{hasPermission: checker}
+}
+
+// Handle 实现访问控制处理。
+func (m *RBACMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIdentity, _ := r.Context().Value("user_identity").(string)
+		if userIdentity == "" {
+			httpx.ErrorCtx(r.Context(), w, errors.New("未授权访问，请提供有效的 token"))
+			return
+		}
+		permission, ok := requiredPermission(r.URL.Path)
+		if !ok {
+			forbidden(w, "权限不足")
+			return
+		}
+		allowed, err := m.hasPermission(r.Context(), userIdentity, permission)
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("permission check failed: %v", err)
+			httpx.ErrorCtx(r.Context(), w, errors.New("权限校验失败，请稍后重试"))
+			return
+		}
+		if !allowed {
+			forbidden(w, "权限不足")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requiredPermission 返回访问路径所需的管理权限，未声明或路径未规范化（含 .、.. 或重复斜杠）时返回 false。
+func requiredPermission(p string) (string, bool) {
+	if path.Clean(p) != p {
+		return "", false
+	}
+	for _, rule := range permissionRules {
+		if strings.HasPrefix(p, rule.prefix) {
+			return rule.permission, true
+		}
+	}
+	return "", false
+}
+
+// forbidden 返回 403 响应。
+func forbidden(w http.ResponseWriter, msg string) {
+	httpx.WriteJson(w, http.StatusForbidden, common.Body{
+		Code: uint32(http.StatusForbidden),
+		Msg:  msg,
+		Data: nil,
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"cloud_disk/core/utils"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path"
 	"time"
@@ -71,7 +73,12 @@ func (c *Consumer) Start() {
 			// 重试逻辑
 			var processErr error
 			for i := 0; i < retryCount; i++ {
+				if i > 0 {
+					utils.ObserveMQRetry(utils.MQQueueUpload)
+				}
+				start := time.Now()
 				processErr = c.processFile(d.Body)
+				utils.ObserveMQConsume(utils.MQQueueUpload, start, processErr)
 				if processErr == nil {
 					// 处理成功，确认消息
 					if ackErr := d.Ack(false); ackErr != nil {
@@ -96,6 +103,12 @@ func (c *Consumer) Start() {
 				if nackErr := d.Nack(false, false); nackErr != nil {
 					logx.Errorf("拒绝消息失败: %v", nackErr)
 				}
+				utils.ObserveMQNack(utils.MQQueueUpload)
+				utils.ObserveUpload(utils.UploadResultFailed, 0)
+				var task types.UploadEvent
+				if json.Unmarshal(d.Body, &task) == nil {
+					notifyUpload(c.ctx, c.svcCtx, &task, task.Size, processErr)
+				}
 			}
 		}
 	}()
@@ -114,6 +127,14 @@ func (c *Consumer) processFile(body []byte) (err error) {
 		return err
 	}
 	ur := new(models.UserRepository)
+	var usage int64    // 本次计入用户存储空间的字节数
+	var reserved int64 // 已预占的存储空间，任务未完成时释放，避免重试或失败后重复占用
+	committed := false
+	defer func() {
+		if reserved > 0 && !committed {
+			utils.ReleaseQuota(c.svcCtx.DBEngine, task.UserIdentity, reserved, 0)
+		}
+	}()
 	// 特判：文件存在且上传的文件在当前父目录下已存在且用户 id 一致
 	if task.IsExisted {
 		had, queryErr := c.svcCtx.DBEngine.Table("user_repository").Where("repository_identity=? AND user_identity=?", task.RepositoryIdentity, task.UserIdentity).Get(ur)
@@ -123,9 +144,28 @@ func (c *Consumer) processFile(body []byte) (err error) {
 		// 直接返回：文件已存在
 		if had {
 			logx.Infof("文件秒传：用户 %s 已拥有此文件（repository_identity: %s）", task.UserIdentity, task.RepositoryIdentity)
+			utils.ObserveUpload(utils.UploadResultInstant, 0)
+			notifyUpload(c.ctx, c.svcCtx, &task, task.Size, nil)
 			return nil
 		}
+		rp := new(models.RepositoryPool)
+		if _, queryErr := c.svcCtx.DBEngine.Where("identity = ?", task.RepositoryIdentity).Get(rp); queryErr != nil {
+			return queryErr
+		}
+		usage = rp.Size
+		if exceeded, quotaErr := c.quotaExceeded(task, usage); exceeded || quotaErr != nil {
+			return quotaErr
+		}
+		reserved = usage
 	} else {
+		// 入队后空间可能已被其他上传占用，处理前按原始大小预占（压缩只会让文件变小，入库时按实际大小修正）
+		if exceeded, quotaErr := c.quotaExceeded(task, task.Size); exceeded || quotaErr != nil {
+			if exceeded {
+				_ = os.Remove(task.FilePath)
+			}
+			return quotaErr
+		}
+		reserved = task.Size
 		// 开始处理
 		// 文件不存在，进行上传
 		// 将临时文件指针重置到开头以便上传
@@ -166,7 +206,9 @@ func (c *Consumer) processFile(body []byte) (err error) {
 			// 注意：不在这里 defer，避免在秒传时也执行清理
 
 			// 使用 ffmpeg 压缩视频
+			compressStart := time.Now()
 			_, compressErr := utils.CompressVideoWithFFmpeg(tempFile.Name(), compressedFile.Name(), 23, "128k")
+			utils.ObserveCompress("video", compressStart, compressErr)
 			if compressErr != nil {
 				compressedFile.Close()
 				os.Remove(compressedFilePath)
@@ -205,11 +247,13 @@ func (c *Consumer) processFile(body []byte) (err error) {
 			compressedFile.Close() // 先关闭，因为 CompressImage 会重新打开
 
 			// 使用图片压缩（最大 1920x1080，质量 85）
+			compressStart := time.Now()
 			compressErr := utils.CompressImage(tempFile.Name(), tempCompressedPath, &utils.ImageCompressOptions{
 				MaxWidth:  1920,
 				MaxHeight: 1080,
 				Quality:   85,
 			})
+			utils.ObserveCompress("image", compressStart, compressErr)
 			if compressErr != nil {
 				os.Remove(tempCompressedPath)
 				return err
@@ -292,16 +336,58 @@ func (c *Consumer) processFile(body []byte) (err error) {
 		if err != nil {
 			return err
 		}
+		usage = actualSize
 	}
 	// 最终都要逻辑添加到用户文件表
 	_, err = c.InsertInToUserRepository(task.UserIdentity, task.RepositoryIdentity, task.Ext, task.Name, task.ParentId)
 	if err != nil {
 		return err
 	}
+	committed = true
+	if quotaErr := utils.AddUsage(c.svcCtx.DBEngine, task.UserIdentity, usage-reserved, 0); quotaErr != nil {
+		logx.Errorf("更新存储用量失败 user=%s err=%v", task.UserIdentity, quotaErr)
+	} else {
+		NotifyQuota(c.ctx, c.svcCtx, task.UserIdentity, usage)
+	}
+	// 匿名上传链接的文件在入队时已记录 drop_upload 事件
+	if task.ActorIdentity != "" {
+		event := &models.FileEventLog{
+			RepositoryIdentity: task.RepositoryIdentity,
+			UserIdentity:       task.UserIdentity,
+			EventType:          common.EventUpload,
+			ActorIdentity:      task.ActorIdentity,
+			Ip:                 task.ClientIp,
+			Detail:             fmt.Sprintf("name=%s size=%d parent_id=%d", task.Name, usage, task.ParentId),
+			FolderId:           task.ParentId,
+		}
+		if auditErr := utils.RecordFileEvent(c.svcCtx.DBEngine, event); auditErr != nil {
+			logx.Errorf("记录上传事件失败 user=%s err=%v", task.UserIdentity, auditErr)
+		} else {
+			PublishWebhooks(c.ctx, c.svcCtx, event)
+		}
+	}
+	result := utils.UploadResultStored
+	if task.IsExisted {
+		result = utils.UploadResultInstant
+	}
+	utils.ObserveUpload(result, usage)
+	notifyUpload(c.ctx, c.svcCtx, &task, usage, nil)
 	return nil
 
 }
 
+// quotaExceeded 原子预占用户存储空间；空间不足时记录日志并丢弃任务（不再重试）。
+func (c *Consumer) quotaExceeded(task types.UploadEvent, size int64) (bool, error) {
+	err := utils.ReserveQuota(c.svcCtx.DBEngine, task.UserIdentity, size, 0)
+	if errors.Is(err, utils.ErrQuotaExceeded) {
+		logx.Errorf("存储空间不足，放弃上传任务：用户 %s 文件 %s（%d 字节）", task.UserIdentity, task.Name, size)
+		utils.ObserveUpload(utils.UploadResultRejected, 0)
+		notifyUpload(c.ctx, c.svcCtx, &task, size, err)
+		return true, nil
+	}
+	return false, err
+}
+
 func (c *Consumer) InsertInToUserRepository(userIdentity, repositoryIdentity, ext, name string, parentId int64) (userRepositoryIdentity string, err error) {
 	ur := &models.UserRepository{
 		Identity:           utils.UUID(),
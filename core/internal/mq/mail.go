@@ -0,0 +1,155 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/utils"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SendMail 按语言渲染邮件模板并发布到邮件队列，由 MailConsumer 发送并在失败时重试。
+// 邮件功能关闭时直接返回；未配置 RabbitMQ 时同步发送，返回发送结果。
+func SendMail(ctx context.Context, svcCtx *svc.ServiceContext, to, template, lang string, data any) error {
+	if !utils.EmailEnabled() {
+		logx.WithContext(ctx).Infof("邮箱发送已禁用，跳过邮件 template=%s to=%s", template, to)
+		return nil
+	}
+	subject, body, err := utils.RenderMail(template, lang, data)
+	if err != nil {
+		return err
+	}
+	task := &types.MailTask{Identity: utils.UUID(), To: to, Template: template, Subject: subject, HTML: body}
+	if ttl, ok := common.MailExpiry[template]; ok {
+		task.ExpireAt = time.Now().Add(ttl).Unix()
+	}
+	if svcCtx.RabbitMQConn == nil {
+		return utils.SendNotifyEmail(task.To, task.Subject, task.HTML)
+	}
+	return enqueueMail(ctx, svcCtx, task)
+}
+
+// mailRetryQueue 返回第 attempt 次重试使用的延迟队列名。
+func mailRetryQueue(attempt int) string {
+	return common.MailRetryQueuePrefix + strconv.Itoa(attempt)
+}
+
+// enqueueMail 发布邮件任务；已失败过的任务发布到对应的延迟队列，过期后死信回邮件队列。
+func enqueueMail(ctx context.Context, svcCtx *svc.ServiceContext, task *types.MailTask) error {
+	if svcCtx.RabbitMQConn == nil {
+		return errors.New("RabbitMQ 未初始化")
+	}
+	ch, err := svcCtx.RabbitMQConn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	body, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	exchange, key := common.ExchangeName, common.MailRoutingKey
+	if task.Attempts > 0 {
+		// 默认交换机按队列名路由
+		exchange, key = "", mailRetryQueue(task.Attempts)
+	}
+	return ch.PublishWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+	})
+}
+
+// MailConsumer 邮件队列消费者，发送失败时按 MailRetryDelays 发布到延迟队列重试。
+type MailConsumer struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewMailConsumer 创建邮件队列消费者。
+func NewMailConsumer(ctx context.Context, svcCtx *svc.ServiceContext) *MailConsumer {
+	return &MailConsumer{ctx: ctx, svcCtx: svcCtx}
+}
+
+// mailConsumerWorkers 并发发送邮件的协程数。
+const mailConsumerWorkers = 2
+
+// Start 使用独立通道消费邮件队列，与其他消费者互不影响。
+func (c *MailConsumer) Start() {
+	ch, err := c.svcCtx.RabbitMQConn.Channel()
+	if err != nil {
+		logx.Errorf("创建邮件通道失败: %v", err)
+		return
+	}
+	if err := ch.Qos(mailConsumerWorkers, 0, false); err != nil {
+		logx.Errorf("设置邮件 QoS 失败: %v", err)
+		return
+	}
+	msgs, err := ch.Consume(common.MailQueueName, "", false, false, false, false, nil)
+	if err != nil {
+		logx.Errorf("注册邮件消费者失败: %v", err)
+		return
+	}
+	logx.Info("Mail consumer started, waiting for messages...")
+	for i := 0; i < mailConsumerWorkers; i++ {
+		go func() {
+			for d := range msgs {
+				c.handle(d)
+			}
+		}()
+	}
+}
+
+// handle 发送一封邮件；失败且未超过重试次数时发布到下一级延迟队列，否则放弃并记录日志。
+func (c *MailConsumer) handle(d amqp.Delivery) {
+	var task types.MailTask
+	if err := json.Unmarshal(d.Body, &task); err != nil {
+		logx.Errorf("解析邮件任务失败: %v", err)
+		_ = d.Nack(false, false)
+		utils.ObserveMQNack(utils.MQQueueMail)
+		return
+	}
+	if retry := DeliverMail(&task); retry {
+		utils.ObserveMQRetry(utils.MQQueueMail)
+		if err := enqueueMail(c.ctx, c.svcCtx, &task); err != nil {
+			logx.Errorf("邮件重试入队失败 identity=%s to=%s err=%v", task.Identity, task.To, err)
+		}
+	}
+	if err := d.Ack(false); err != nil {
+		logx.Errorf("确认邮件任务失败: %v", err)
+	}
+}
+
+// DeliverMail 发送一次邮件任务；失败时累加失败次数，返回是否还需要重试。
+// 已过期的任务不再发送，下一次重试会晚于过期时间时直接放弃，避免用户收到已失效的验证码。
+func DeliverMail(task *types.MailTask) (retry bool) {
+	if task.ExpireAt > 0 && time.Now().Unix() >= task.ExpireAt {
+		logx.Errorf("邮件已过期，放弃发送 identity=%s template=%s to=%s attempts=%d", task.Identity, task.Template, task.To, task.Attempts)
+		return false
+	}
+	start := time.Now()
+	err := utils.SendNotifyEmail(task.To, task.Subject, task.HTML)
+	utils.ObserveMQConsume(utils.MQQueueMail, start, err)
+	if err == nil {
+		logx.Infof("邮件发送成功 identity=%s template=%s to=%s", task.Identity, task.Template, task.To)
+		return false
+	}
+	task.Attempts++
+	if task.Attempts > len(common.MailRetryDelays) ||
+		(task.ExpireAt > 0 && time.Now().Add(common.MailRetryDelays[task.Attempts-1]).Unix() >= task.ExpireAt) {
+		logx.Errorf("邮件发送失败，已放弃 identity=%s template=%s to=%s attempts=%d err=%v",
+			task.Identity, task.Template, task.To, task.Attempts, err)
+		return false
+	}
+	logx.Errorf("邮件发送失败，等待重试 identity=%s template=%s to=%s attempts=%d err=%v",
+		task.Identity, task.Template, task.To, task.Attempts, err)
+	return true
+}
@@ -0,0 +1,118 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Notify 向用户推送实时通知，推送失败只记录日志。
+func Notify(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, eventType string, data any) {
+	if err := svcCtx.Notifier.Publish(ctx, userIdentity, eventType, data); err != nil {
+		logx.WithContext(ctx).Errorf("publish notification failed user=%s type=%s err=%v", userIdentity, eventType, err)
+	}
+}
+
+// NotifyQuota 在用户用量新增 added 字节后检查告警阈值，首次越过时推送存储用量告警并发送告警邮件。
+func NotifyQuota(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string, added int64) {
+	quota, crossed, err := utils.QuotaWarningCrossed(svcCtx.DBEngine, userIdentity, added)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("check quota warning failed user=%s err=%v", userIdentity, err)
+		return
+	}
+	if !crossed {
+		return
+	}
+	notice := &types.QuotaNotice{
+		QuotaBytes: quota.QuotaBytes,
+		UsedBytes:  quota.UsedBytes,
+		Percent:    int(quota.UsedBytes * 100 / quota.QuotaBytes),
+	}
+	Notify(ctx, svcCtx, userIdentity, common.NotifyQuotaWarning, notice)
+
+	user := new(models.UserBasic)
+	has, err := svcCtx.DBEngine.Where("identity = ?", userIdentity).Get(user)
+	if err != nil || !has || user.Email == "" {
+		return
+	}
+	if err := SendMail(ctx, svcCtx, user.Email, common.MailQuotaWarning, utils.MailLang(user.Language), notice); err != nil {
+		logx.WithContext(ctx).Errorf("send quota warning mail failed user=%s err=%v", userIdentity, err)
+	}
+}
+
+// notifyUpload 推送异步上传结果，通知发起上传的用户；匿名上传链接的文件通知空间所有者。
+func notifyUpload(ctx context.Context, svcCtx *svc.ServiceContext, task *types.UploadEvent, size int64, cause error) {
+	recipient := task.ActorIdentity
+	if recipient == "" {
+		recipient = task.UserIdentity
+	}
+	notice := &types.UploadNotice{
+		RepositoryIdentity: task.RepositoryIdentity,
+		ParentId:           task.ParentId,
+		Name:               task.Name,
+		Size:               size,
+	}
+	eventType := common.NotifyUploadDone
+	if cause != nil {
+		eventType = common.NotifyUploadFailed
+		notice.Error = cause.Error()
+	}
+	Notify(ctx, svcCtx, recipient, eventType, notice)
+	if cause != nil {
+		AddNotification(ctx, svcCtx, recipient, common.InboxUploadFailed, "上传失败",
+			fmt.Sprintf("文件「%s」上传失败：%s", task.Name, cause.Error()), notice)
+	}
+}
+
+// AddNotification 写入一条站内通知，使未读数缓存失效并实时推送；写入失败只记录日志。
+func AddNotification(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, notificationType, title, content string, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("encode notification failed user=%s type=%s err=%v", userIdentity, notificationType, err)
+		return
+	}
+	n := &models.Notification{
+		Identity:     utils.UUID(),
+		UserIdentity: userIdentity,
+		Type:         notificationType,
+		Title:        utils.TruncateUTF8(title, 255),
+		Content:      utils.TruncateUTF8(content, 1024),
+		Data:         string(body),
+	}
+	if _, err := svcCtx.DBEngine.Insert(n); err != nil {
+		logx.WithContext(ctx).Errorf("insert notification failed user=%s type=%s err=%v", userIdentity, notificationType, err)
+		return
+	}
+	InvalidateUnread(ctx, svcCtx, userIdentity)
+	Notify(ctx, svcCtx, userIdentity, common.NotifyInbox, NotificationItem(n))
+}
+
+// InvalidateUnread 写入新的缓存版本使用户未读通知数缓存失效，下次查询时重新统计。
+// 仅删除缓存会与并发查询竞争：查询方可能在删除后写回变更前统计的旧值，带版本的缓存写回后也会被识别为过期。
+func InvalidateUnread(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) {
+	if err := svcCtx.RedisClient.Set(ctx, utils.NotificationUnreadVersionKey(userIdentity), utils.UUID(), utils.NotificationUnreadTTL).Err(); err != nil {
+		logx.WithContext(ctx).Errorf("invalidate unread count failed user=%s err=%v", userIdentity, err)
+	}
+}
+
+// NotificationItem 将站内通知转换为接口返回结构。
+func NotificationItem(n *models.Notification) *types.NotificationItem {
+	return &types.NotificationItem{
+		Identity:  n.Identity,
+		Type:      n.Type,
+		Title:     n.Title,
+		Content:   n.Content,
+		Data:      n.Data,
+		Read:      n.IsRead,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,379 @@
+package mq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/internal/svc"
+	"cloud_disk/core/internal/types"
+	"cloud_disk/core/models"
+	"cloud_disk/core/utils"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// webhookTimeout 单次投递的超时时间。
+const webhookTimeout = 10 * time.Second
+
+// webhookErrorMaxLen 投递记录中错误信息的最大字节数，与 webhook_delivery.error 列宽一致。
+const webhookErrorMaxLen = 512
+
+// maxWebhookFolderDepth 匹配文件夹订阅时向上查找祖先目录的最大层数，防止脏数据导致死循环。
+const maxWebhookFolderDepth = 64
+
+// webhookClient 投递使用的 HTTP 客户端，不跟随重定向，非 2xx 响应均视为失败；
+// 不使用环境代理，并在拨号时校验目标 IP，拒绝投递到内网地址。
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		Proxy:               nil,
+		DialContext:         utils.WebhookDialContext(webhookTimeout),
+		TLSHandshakeTimeout: webhookTimeout,
+		// 每次投递重新拨号，保证每个请求都经过目标 IP 校验
+		DisableKeepAlives: true,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// WebhookMaxAttempts 自动投递的最大尝试次数（首次投递加全部重试）。
+func WebhookMaxAttempts() int {
+	return len(common.WebhookRetryDelays) + 1
+}
+
+// PublishWebhooks 为文件事件匹配 webhook 订阅，写入投递记录并发布投递任务。
+// 事件须已写入审计日志（带有标识与时间）；失败只记录日志，不影响业务流程。
+func PublishWebhooks(ctx context.Context, svcCtx *svc.ServiceContext, events ...*models.FileEventLog) {
+	for _, event := range events {
+		if !webhookEventSupported(event.EventType) {
+			continue
+		}
+		hooks, err := matchWebhooks(svcCtx, event)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("match webhooks failed event=%s err=%v", event.Identity, err)
+			continue
+		}
+		if len(hooks) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(types.WebhookPayload{
+			Event:              event.EventType,
+			EventIdentity:      event.Identity,
+			SpaceIdentity:      event.UserIdentity,
+			ActorIdentity:      event.ActorIdentity,
+			RepositoryIdentity: event.RepositoryIdentity,
+			FolderId:           event.FolderId,
+			Ip:                 event.Ip,
+			Detail:             event.Detail,
+			CreatedAt:          event.CreatedAt,
+		})
+		if err != nil {
+			logx.WithContext(ctx).Errorf("marshal webhook payload failed event=%s err=%v", event.Identity, err)
+			continue
+		}
+		for _, hook := range hooks {
+			delivery := &models.WebhookDelivery{
+				Identity:        utils.UUID(),
+				WebhookIdentity: hook.Identity,
+				EventIdentity:   event.Identity,
+				EventType:       event.EventType,
+				Payload:         string(payload),
+				Status:          common.WebhookStatusPending,
+			}
+			if _, err := svcCtx.DBEngine.Insert(delivery); err != nil {
+				logx.WithContext(ctx).Errorf("create webhook delivery failed webhook=%s err=%v", hook.Identity, err)
+				continue
+			}
+			if err := enqueueWebhookDelivery(ctx, svcCtx, delivery.Identity, 0); err != nil {
+				logx.WithContext(ctx).Errorf("enqueue webhook delivery failed identity=%s err=%v", delivery.Identity, err)
+				failWebhookDelivery(svcCtx, delivery.Identity, "投递入队失败: "+err.Error())
+			}
+		}
+	}
+}
+
+// webhookEventSupported 判断事件类型是否可被 webhook 订阅。
+func webhookEventSupported(eventType string) bool {
+	for _, e := range common.WebhookEvents {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWebhooks 查询订阅了该事件的 webhook：全站订阅，以及事件所在空间内、文件夹范围覆盖事件所在文件夹的订阅。
+// 群组空间的订阅仅在创建者仍为群组所有者时生效。
+func matchWebhooks(svcCtx *svc.ServiceContext, event *models.FileEventLog) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	err := svcCtx.DBEngine.Where("space_identity = ? OR space_identity = ''", event.UserIdentity).Asc("id").Find(&hooks)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]models.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		if !webhookSubscribes(hook.EventTypes, event.EventType) {
+			continue
+		}
+		if hook.SpaceIdentity != "" && hook.ParentId != 0 {
+			within, err := folderWithin(svcCtx, event.UserIdentity, event.FolderId, hook.ParentId)
+			if err != nil {
+				return nil, err
+			}
+			if !within {
+				continue
+			}
+		}
+		if hook.SpaceIdentity != "" && hook.SpaceIdentity != hook.UserIdentity {
+			cnt, err := svcCtx.DBEngine.
+				Where("group_identity = ? AND user_identity = ? AND role = ?", hook.SpaceIdentity, hook.UserIdentity, common.GroupRoleOwner).
+				Count(new(models.GroupMember))
+			if err != nil {
+				return nil, err
+			}
+			if cnt == 0 {
+				continue
+			}
+		}
+		matched = append(matched, hook)
+	}
+	return matched, nil
+}
+
+// webhookSubscribes 判断订阅的事件类型列表是否包含该事件，列表为空表示订阅全部事件。
+func webhookSubscribes(eventTypes, eventType string) bool {
+	if eventTypes == "" {
+		return true
+	}
+	for _, e := range strings.Split(eventTypes, ",") {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// folderWithin 判断文件夹 folderId 是否为 ancestorId 本身或其子孙目录（含已删除的目录）。
+func folderWithin(svcCtx *svc.ServiceContext, owner string, folderId, ancestorId int64) (bool, error) {
+	for depth := 0; folderId != 0 && depth < maxWebhookFolderDepth; depth++ {
+		if folderId == ancestorId {
+			return true, nil
+		}
+		folder := new(models.UserRepository)
+		has, err := svcCtx.DBEngine.Unscoped().Where("id = ? AND user_identity = ?", folderId, owner).Get(folder)
+		if err != nil || !has {
+			return false, err
+		}
+		folderId = folder.ParentId
+	}
+	return false, nil
+}
+
+// webhookRetryQueue 返回第 attempt 次重试使用的延迟队列名。
+func webhookRetryQueue(attempt int) string {
+	return common.WebhookRetryQueuePrefix + strconv.Itoa(attempt)
+}
+
+// enqueueWebhookDelivery 发布投递任务；failures 为已失败次数，大于 0 时发布到对应的延迟队列，过期后死信回投递队列。
+func enqueueWebhookDelivery(ctx context.Context, svcCtx *svc.ServiceContext, identity string, failures int) error {
+	if svcCtx.RabbitMQConn == nil {
+		return errors.New("RabbitMQ 未初始化")
+	}
+	ch, err := svcCtx.RabbitMQConn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	body, err := json.Marshal(types.WebhookTask{DeliveryIdentity: identity})
+	if err != nil {
+		return err
+	}
+	exchange, key := common.ExchangeName, common.WebhookRoutingKey
+	if failures > 0 {
+		// 默认交换机按队列名路由
+		exchange, key = "", webhookRetryQueue(failures)
+	}
+	return ch.PublishWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+	})
+}
+
+// failWebhookDelivery 将投递记录标记为失败。
+func failWebhookDelivery(svcCtx *svc.ServiceContext, identity, reason string) {
+	_, err := svcCtx.DBEngine.Where("identity = ?", identity).Cols("status", "error").
+		Update(&models.WebhookDelivery{Status: common.WebhookStatusFailed, Error: utils.TruncateUTF8(reason, webhookErrorMaxLen)})
+	if err != nil {
+		logx.Errorf("update webhook delivery failed identity=%s err=%v", identity, err)
+	}
+}
+
+// AttemptWebhookDelivery 对等待中的投递记录执行一次投递并更新结果：成功标记为 success；
+// 失败次数未达到 maxAttempts 时保持 pending 等待重试，否则标记为 failed。投递记录不存在时返回 nil。
+func AttemptWebhookDelivery(ctx context.Context, svcCtx *svc.ServiceContext, identity string, maxAttempts int) (*models.WebhookDelivery, error) {
+	delivery := new(models.WebhookDelivery)
+	has, err := svcCtx.DBEngine.Where("identity = ?", identity).Get(delivery)
+	if err != nil || !has {
+		return nil, err
+	}
+	// 已完成的投递不再发送，避免队列消息重复投递导致接收方重复处理
+	if delivery.Status != common.WebhookStatusPending {
+		return delivery, nil
+	}
+	hook := new(models.Webhook)
+	has, err = svcCtx.DBEngine.Where("identity = ?", delivery.WebhookIdentity).Get(hook)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		delivery.Status = common.WebhookStatusFailed
+		delivery.Error = "webhook 已删除"
+	} else {
+		code, sendErr := sendWebhook(ctx, hook, delivery)
+		delivery.Attempts++
+		delivery.ResponseCode = code
+		delivery.DeliveredAt = time.Now().Format(common.DataTimeFormat)
+		delivery.Error = ""
+		switch {
+		case sendErr == nil:
+			delivery.Status = common.WebhookStatusSuccess
+		case delivery.Attempts < maxAttempts:
+			delivery.Error = utils.TruncateUTF8(sendErr.Error(), webhookErrorMaxLen)
+		default:
+			delivery.Status = common.WebhookStatusFailed
+			delivery.Error = utils.TruncateUTF8(sendErr.Error(), webhookErrorMaxLen)
+		}
+	}
+	_, err = svcCtx.DBEngine.Where("identity = ?", delivery.Identity).
+		Cols("status", "attempts", "response_code", "error", "delivered_at").
+		Update(delivery)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// sendWebhook 发送签名后的投递请求，返回接收方状态码；非 2xx 响应视为失败。
+func sendWebhook(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CloudDisk-Webhook")
+	req.Header.Set(utils.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(utils.WebhookDeliveryHeader, delivery.Identity)
+	req.Header.Set(utils.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(utils.WebhookSignatureHeader, utils.WebhookSignature(hook.Secret, timestamp, body))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		// 原始错误可能包含内网地址、端口等探测信息，仅记录日志，投递记录中只保存概括性原因
+		logx.WithContext(ctx).Errorf("webhook send failed delivery=%s err=%v", delivery.Identity, err)
+		return 0, webhookSendError(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("接收方返回状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookSendError 将传输层错误归类为不泄露网络细节的提示。
+func webhookSendError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, utils.ErrWebhookPrivateAddress):
+		return errors.New("回调地址不是公网地址，已拒绝投递")
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errors.New("请求接收方超时")
+	default:
+		return errors.New("无法连接接收方")
+	}
+}
+
+// WebhookConsumer webhook 投递队列消费者，投递失败时按 WebhookRetryDelays 发布到延迟队列重试。
+type WebhookConsumer struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewWebhookConsumer 创建 webhook 投递队列消费者。
+func NewWebhookConsumer(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookConsumer {
+	return &WebhookConsumer{ctx: ctx, svcCtx: svcCtx}
+}
+
+// webhookConsumerWorkers 并发投递的协程数，避免单个慢接收方阻塞整个队列。
+const webhookConsumerWorkers = 4
+
+// Start 使用独立通道消费投递队列，与上传任务消费者互不影响。
+func (c *WebhookConsumer) Start() {
+	ch, err := c.svcCtx.RabbitMQConn.Channel()
+	if err != nil {
+		logx.Errorf("创建 webhook 通道失败: %v", err)
+		return
+	}
+	if err := ch.Qos(webhookConsumerWorkers, 0, false); err != nil {
+		logx.Errorf("设置 webhook QoS 失败: %v", err)
+		return
+	}
+	msgs, err := ch.Consume(common.WebhookQueueName, "", false, false, false, false, nil)
+	if err != nil {
+		logx.Errorf("注册 webhook 消费者失败: %v", err)
+		return
+	}
+	logx.Info("Webhook consumer started, waiting for deliveries...")
+	for i := 0; i < webhookConsumerWorkers; i++ {
+		go func() {
+			for d := range msgs {
+				c.handle(d)
+			}
+		}()
+	}
+}
+
+// handle 处理一条投递任务；投递失败且未超过最大次数时发布到下一级延迟队列。
+func (c *WebhookConsumer) handle(d amqp.Delivery) {
+	var task types.WebhookTask
+	if err := json.Unmarshal(d.Body, &task); err != nil {
+		logx.Errorf("解析 webhook 任务失败: %v", err)
+		_ = d.Nack(false, false)
+		utils.ObserveMQNack(utils.MQQueueWebhook)
+		return
+	}
+	start := time.Now()
+	delivery, err := AttemptWebhookDelivery(c.ctx, c.svcCtx, task.DeliveryIdentity, WebhookMaxAttempts())
+	utils.ObserveMQConsume(utils.MQQueueWebhook, start, err)
+	if err != nil {
+		logx.Errorf("webhook 投递失败 identity=%s err=%v", task.DeliveryIdentity, err)
+		failWebhookDelivery(c.svcCtx, task.DeliveryIdentity, err.Error())
+		_ = d.Nack(false, false)
+		utils.ObserveMQNack(utils.MQQueueWebhook)
+		return
+	}
+	if delivery != nil && delivery.Status == common.WebhookStatusPending {
+		utils.ObserveMQRetry(utils.MQQueueWebhook)
+		if err := enqueueWebhookDelivery(c.ctx, c.svcCtx, delivery.Identity, delivery.Attempts); err != nil {
+			logx.Errorf("webhook 重试入队失败 identity=%s err=%v", delivery.Identity, err)
+			failWebhookDelivery(c.svcCtx, delivery.Identity, "重试入队失败: "+err.Error())
+		}
+	}
+	if err := d.Ack(false); err != nil {
+		logx.Errorf("确认 webhook 任务失败: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// subscriptionBuffer 每个连接缓冲的事件数，客户端消费过慢时丢弃新事件，避免阻塞发布方。
+const subscriptionBuffer = 32
+
+// maxSubscriptionsPerUser 单个实例上每个用户同时保持的推送连接上限。
+const maxSubscriptionsPerUser = 8
+
+// ErrTooManySubscriptions 用户推送连接数超过上限。
+var ErrTooManySubscriptions = errors.New("实时通知连接数过多")
+
+// Event 推送给用户的实时事件，Data 为事件内容的 JSON。
+type Event struct {
+	Id           string          `json:"id"`
+	UserIdentity string          `json:"user_identity"`
+	Type         string          `json:"type"`
+	Data         json.RawMessage `json:"data"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// Broker 跨实例广播事件的通道。
+type Broker interface {
+	// Publish 将事件广播到所有实例（包括本实例）。
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe 阻塞接收广播的事件，直到 ctx 结束。
+	Subscribe(ctx context.Context, handle func(payload []byte))
+}
+
+// Hub 实时通知中心：本实例的推送连接按用户订阅，事件经 Broker 广播到所有实例后分发给本地连接。
+// 未配置 Broker 时仅在本实例内分发。Hub 为 nil 时发布与订阅均为空操作。
+type Hub struct {
+	broker Broker
+	mu     sync.RWMutex
+	subs   map[string]map[*Subscription]struct{}
+}
+
+// Subscription 一个推送连接的事件订阅，使用完毕须调用 Close。
+type Subscription struct {
+	C    <-chan Event
+	ch   chan Event
+	user string
+	hub  *Hub
+	once sync.Once
+}
+
+// NewHub 创建实时通知中心，broker 为 nil 时仅在本实例内分发。
+func NewHub(broker Broker) *Hub {
+	return &Hub{broker: broker, subs: map[string]map[*Subscription]struct{}{}}
+}
+
+// Start 开始接收其他实例广播的事件。
+func (h *Hub) Start(ctx context.Context) {
+	if h == nil || h.broker == nil {
+		return
+	}
+	go h.broker.Subscribe(ctx, h.dispatch)
+}
+
+// Publish 向用户推送事件，data 序列化为 JSON；广播失败时退化为仅推送本实例的连接。
+func (h *Hub) Publish(ctx context.Context, userIdentity, eventType string, data any) error {
+	if h == nil || userIdentity == "" {
+		return nil
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event := Event{
+		Id:           utils.UUID(),
+		UserIdentity: userIdentity,
+		Type:         eventType,
+		Data:         body,
+		CreatedAt:    time.Now().Format(common.DataTimeFormat),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if h.broker != nil {
+		if err := h.broker.Publish(ctx, payload); err == nil {
+			return nil
+		} else {
+			logx.WithContext(ctx).Errorf("broadcast notification failed user=%s type=%s err=%v", userIdentity, eventType, err)
+		}
+	}
+	h.dispatch(payload)
+	return nil
+}
+
+// dispatch 将广播的事件分发给本实例上该用户的连接，连接缓冲已满时丢弃。
+func (h *Hub) dispatch(payload []byte) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		logx.Errorf("decode notification failed: %v", err)
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs[event.UserIdentity] {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe 为用户创建推送订阅，超过单用户连接上限时返回 ErrTooManySubscriptions。
+func (h *Hub) Subscribe(userIdentity string) (*Subscription, error) {
+	ch := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, user: userIdentity, hub: h}
+	if h == nil {
+		return sub, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subs[userIdentity]) >= maxSubscriptionsPerUser {
+		return nil, ErrTooManySubscriptions
+	}
+	if h.subs[userIdentity] == nil {
+		h.subs[userIdentity] = map[*Subscription]struct{}{}
+	}
+	h.subs[userIdentity][sub] = struct{}{}
+	return sub, nil
+}
+
+// Subscribers 返回本实例上该用户的推送连接数。
+func (h *Hub) Subscribers(userIdentity string) int {
+	if h == nil {
+		return 0
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs[userIdentity])
+}
+
+// Close 取消订阅，可重复调用。
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		if s.hub == nil {
+			return
+		}
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+		delete(s.hub.subs[s.user], s)
+		if len(s.hub.subs[s.user]) == 0 {
+			delete(s.hub.subs, s.user)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// redisChannel 实时通知广播使用的 Redis 频道。
+const redisChannel = "notify:events"
+
+// redisBroker 基于 Redis 发布订阅的跨实例广播，连接断开后由客户端自动重新订阅。
+type redisBroker struct {
+	client *redis.Client
+}
+
+// NewRedisBroker 创建基于 Redis 发布订阅的广播通道。
+func NewRedisBroker(client *redis.Client) Broker {
+	return &redisBroker{client: client}
+}
+
+// Publish 发布事件到广播频道。
+func (b *redisBroker) Publish(ctx context.Context, payload []byte) error {
+	return b.client.Publish(ctx, redisChannel, payload).Err()
+}
+
+// Subscribe 订阅广播频道并逐条处理，直到 ctx 结束。
+func (b *redisBroker) Subscribe(ctx context.Context, handle func(payload []byte)) {
+	pubsub := b.client.Subscribe(ctx, redisChannel)
+	defer pubsub.Close()
+	logx.Infof("notification broker subscribed channel=%s", redisChannel)
+	msgs := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			handle([]byte(msg.Payload))
+		}
+	}
+}
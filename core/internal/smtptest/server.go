@@ -0,0 +1,248 @@
+// Package smtptest 提供用于测试的本地 SMTP 服务，接收并保存邮件而不投递。
+package smtptest
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 服务收到的一封邮件，Data 为原始邮件内容。
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// Server 本地 SMTP 测试服务，不支持 TLS，接受任意认证信息。
+type Server struct {
+	Host string
+	Port string
+
+	ln       net.Listener
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	messages []Message
+	failNext int
+	received chan struct{}
+}
+
+// NewServer 在本机随机端口启动 SMTP 测试服务，使用完毕须调用 Close。
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	s := &Server{Host: host, Port: port, ln: ln, received: make(chan struct{}, 1)}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Close 停止服务并等待进行中的连接结束。
+func (s *Server) Close() {
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+// FailNext 使接下来的 n 封邮件在 DATA 阶段返回临时错误，用于验证重试。
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+// Messages 返回已收到的全部邮件。
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Wait 等待收到至少 n 封邮件，超时返回 false。
+func (s *Server) Wait(n int, timeout time.Duration) ([]Message, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		if msgs := s.Messages(); len(msgs) >= n {
+			return msgs, true
+		}
+		select {
+		case <-s.received:
+		case <-deadline.C:
+			return s.Messages(), false
+		}
+	}
+}
+
+// serve 接受连接，每个连接一个协程处理。
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle 处理一个 SMTP 会话，只实现发送邮件所需的最小命令集。
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		_, _ = io.WriteString(conn, strings.Join(lines, "\r\n")+"\r\n")
+	}
+	reply("220 smtptest ready")
+	var msg Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(verb, "EHLO"):
+			reply("250-smtptest", "250-AUTH PLAIN LOGIN", "250 8BITMIME")
+		case strings.HasPrefix(verb, "HELO"), strings.HasPrefix(verb, "NOOP"):
+			reply("250 OK")
+		case strings.HasPrefix(verb, "AUTH"):
+			reply("235 Authentication succeeded")
+		case strings.HasPrefix(verb, "MAIL FROM:"):
+			msg = Message{From: trimAddr(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(verb, "RCPT TO:"):
+			msg.To = append(msg.To, trimAddr(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case verb == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(r)
+			if err != nil {
+				return
+			}
+			msg.Data = data
+			if s.accept(msg) {
+				reply("250 OK")
+			} else {
+				reply("451 Temporary failure")
+			}
+			msg = Message{}
+		case verb == "RSET":
+			msg = Message{}
+			reply("250 OK")
+		case verb == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// accept 保存邮件；需要模拟失败时丢弃并返回 false。
+func (s *Server) accept(msg Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failNext > 0 {
+		s.failNext--
+		return false
+	}
+	s.messages = append(s.messages, msg)
+	select {
+	case s.received <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// readData 读取 DATA 内容直到单独一行的 "."，并还原行首的点转义。
+func readData(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimRight(line, "\r\n") == "." {
+			return b.String(), nil
+		}
+		b.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+// trimAddr 去掉地址两侧的尖括号与参数。
+func trimAddr(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "<>")
+}
+
+// Subject 返回解码后的邮件主题。
+func (m Message) Subject() string {
+	parsed, err := mail.ReadMessage(strings.NewReader(m.Data))
+	if err != nil {
+		return ""
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		return parsed.Header.Get("Subject")
+	}
+	return subject
+}
+
+// HTML 返回解码后的 HTML 正文，邮件不含 HTML 正文时返回空字符串。
+func (m Message) HTML() string {
+	parsed, err := mail.ReadMessage(strings.NewReader(m.Data))
+	if err != nil {
+		return ""
+	}
+	return htmlPart(parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Body)
+}
+
+// htmlPart 在（可能嵌套的）multipart 内容中查找并解码 text/html 部分。
+func htmlPart(contentType, encoding string, body io.Reader) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err != nil {
+				return ""
+			}
+			if html := htmlPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part); html != "" {
+				return html
+			}
+		}
+	}
+	if mediaType != "text/html" {
+		return ""
+	}
+	if strings.EqualFold(encoding, "quoted-printable") {
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
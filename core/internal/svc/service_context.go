@@ -4,14 +4,19 @@
 package svc
 
 import (
+	"cloud_disk/core/common"
 	"cloud_disk/core/global"
 	"cloud_disk/core/internal/config"
 	"cloud_disk/core/internal/filter"
 	"cloud_disk/core/internal/middleware"
+	"cloud_disk/core/internal/notify"
+	"cloud_disk/core/models"
 	"cloud_disk/core/utils"
 	"context"
+	"errors"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -30,7 +35,9 @@ type ServiceContext struct {
 	RabbitMQConn       *amqp091.Connection
 	RabbitMQChannel    *amqp091.Channel
 	FileAuthMiddleware rest.Middleware
+	RBACMiddleware     rest.Middleware
 	MyBloomFilter      *filter.MyBloomFilter
+	Notifier           *notify.Hub
 }
 
 // RedisClient Redis 客户端最小接口。
@@ -40,13 +47,18 @@ type RedisClient interface {
 	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
 	Ping(ctx context.Context) *redis.StatusCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+	ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd
+	ZRemRangeByScore(ctx context.Context, key, min, max string) *redis.IntCmd
+	ZCard(ctx context.Context, key string) *redis.IntCmd
 }
 
 // serviceDeps 依赖注入集合。
 type serviceDeps struct {
 	initDB             func(string) *xorm.Engine
 	initRedis          func(string, string, int) RedisClient
-	newFileAuth        func(string, int64) rest.Middleware
+	newFileAuth        func(string, int64, RedisClient, *xorm.Engine) rest.Middleware
 	ensureSchema       func(*xorm.Engine) error
 	ensureTablesHealth func(*xorm.Engine) error
 	ensureDefaultAdmin func(*xorm.Engine) error
@@ -58,8 +70,12 @@ var deps = serviceDeps{
 	initDB:       global.Init,
 	initRedis:    func(addr, password string, db int) RedisClient { return global.InitRedis(addr, password, db) },
 	initRabbitMQ: global.InitRabbitMQ,
-	newFileAuth: func(secret string, expire int64) rest.Middleware {
-		return middleware.NewFileAuthMiddleware(secret, expire).Handle
+	newFileAuth: func(secret string, expire int64, rdb RedisClient, eng *xorm.Engine) rest.Middleware {
+		return middleware.NewFileAuthMiddleware(secret, expire).
+			WithRevocationCheck(tokenRevoked(rdb)).
+			WithSessionCheck(sessionActive(rdb, eng)).
+			WithPersonalAccessTokens(personalAccessTokens(eng)).
+			Handle
 	},
 	ensureSchema:       utils.EnsureSchema,
 	ensureTablesHealth: utils.TablesHealthy,
@@ -68,8 +84,14 @@ var deps = serviceDeps{
 
 // NewServiceContext 创建服务上下文。
 func NewServiceContext(c config.Config) *ServiceContext {
+	if err := utils.SetTrustedProxies(c.TrustedProxies); err != nil {
+		logx.Errorf("trusted proxies config invalid: %v", err)
+	}
+	utils.SetWebhookAllowPrivate(c.WebhookAllowPrivateNetworks)
 	eng := deps.initDB(c.MySQL.DataSource)
-	_ = deps.ensureSchema(eng)
+	if err := deps.ensureSchema(eng); err != nil {
+		logx.Errorf("ensure schema failed: %v", err)
+	}
 	if err := deps.ensureTablesHealth(eng); err != nil {
 		logx.Errorf("tables health check failed: %v", err)
 	}
@@ -85,14 +107,19 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	stopBloomTask := startBloomFilterPersistTask(bloomFilter)
 	// 注册优雅关闭处理
 	registerGracefulShutdown(stopBloomTask, bloomFilter)
+	rdb := deps.initRedis(c.Redis.Addr, c.Redis.Password, c.Redis.DB)
+	notifier := newNotifier(rdb)
+	notifier.Start(context.Background())
 	return &ServiceContext{
 		Config:             c,
 		DBEngine:           eng,
-		RedisClient:        deps.initRedis(c.Redis.Addr, c.Redis.Password, c.Redis.DB),
+		RedisClient:        rdb,
 		RabbitMQConn:       rmqConn,
 		RabbitMQChannel:    rmqCh,
-		FileAuthMiddleware: deps.newFileAuth(c.Auth.AccessSecret, c.Auth.AccessExpire),
+		FileAuthMiddleware: deps.newFileAuth(c.Auth.AccessSecret, c.Auth.AccessExpire, rdb, eng),
+		RBACMiddleware:     middleware.NewRBACMiddleware(userPermitted(eng)).Handle,
 		MyBloomFilter:      bloomFilter,
+		Notifier:           notifier,
 	}
 }
 
@@ -105,6 +132,103 @@ func NewServiceContextWithDeps(c config.Config, db *xorm.Engine, redis RedisClie
 		RabbitMQConn:       global.RmqConn,
 		RabbitMQChannel:    global.RmqCh,
 		FileAuthMiddleware: fileAuth,
+		RBACMiddleware:     middleware.NewRBACMiddleware(userPermitted(db)).Handle,
+		Notifier:           newNotifier(redis),
+	}
+}
+
+// newNotifier 创建实时通知中心：使用 Redis 客户端时通过发布订阅跨实例广播，否则仅在本实例内分发。
+func newNotifier(rdb RedisClient) *notify.Hub {
+	if client, ok := rdb.(*redis.Client); ok && client != nil {
+		return notify.NewHub(notify.NewRedisBroker(client))
+	}
+	return notify.NewHub(nil)
+}
+
+// tokenRevoked 基于 Redis 吊销列表的令牌检查。
+func tokenRevoked(rdb RedisClient) middleware.RevocationChecker {
+	return func(ctx context.Context, jti string) (bool, error) {
+		err := rdb.Get(ctx, utils.RevokedTokenKey(jti)).Err()
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// sessionSeenInterval 会话最近活跃时间的最小更新间隔。
+const sessionSeenInterval = time.Minute
+
+// sessionActive 基于 Redis 的登录会话检查，顺带按间隔记录最近活跃时间。
+func sessionActive(rdb RedisClient, eng *xorm.Engine) middleware.SessionChecker {
+	return func(ctx context.Context, userIdentity, sid string) (bool, error) {
+		owner, err := rdb.Get(ctx, utils.SessionKey(sid)).Result()
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if owner != userIdentity {
+			return false, nil
+		}
+		if ok, err := rdb.SetNX(ctx, utils.SessionSeenKey(sid), "1", sessionSeenInterval).Result(); err == nil && ok {
+			if _, err := eng.Where("identity = ?", sid).Cols("last_seen_at").
+				Update(&models.UserSession{LastSeenAt: time.Now().Format(common.DataTimeFormat)}); err != nil {
+				logx.Errorf("session touch failed identity=%s err=%v", sid, err)
+			}
+		}
+		return true, nil
+	}
+}
+
+// personalAccessTokens 基于数据库的个人访问令牌解析，顺带记录最近使用时间。
+func personalAccessTokens(eng *xorm.Engine) middleware.PATResolver {
+	return func(ctx context.Context, token string) (*middleware.PersonalAccessToken, error) {
+		pat := new(models.PersonalAccessToken)
+		has, err := eng.Context(ctx).Where("token_hash = ?", utils.HashToken(token)).Get(pat)
+		if err != nil || !has {
+			return nil, err
+		}
+		now := time.Now()
+		if pat.ExpireAt != "" {
+			expireAt, err := time.ParseInLocation(common.DataTimeFormat, pat.ExpireAt, time.Local)
+			if err != nil || expireAt.Before(now) {
+				return nil, nil
+			}
+		}
+		user := new(models.UserBasic)
+		has, err = eng.Context(ctx).Where("identity = ?", pat.UserIdentity).Get(user)
+		if err != nil || !has || user.Disabled {
+			return nil, err
+		}
+		if lastUsed, err := time.ParseInLocation(common.DataTimeFormat, pat.LastUsedAt, time.Local); err != nil || now.Sub(lastUsed) > time.Minute {
+			if _, err := eng.Where("identity = ?", pat.Identity).Cols("last_used_at").
+				Update(&models.PersonalAccessToken{LastUsedAt: now.Format(common.DataTimeFormat)}); err != nil {
+				logx.Errorf("personal access token touch failed identity=%s err=%v", pat.Identity, err)
+			}
+		}
+		return &middleware.PersonalAccessToken{
+			UserId:       user.Id,
+			UserIdentity: user.Identity,
+			UserName:     user.Name,
+			Scopes:       strings.Split(pat.Scopes, ","),
+		}, nil
+	}
+}
+
+// userPermitted 基于数据库的管理权限检查，已禁用的账号不具备任何权限。
+func userPermitted(eng *xorm.Engine) middleware.PermissionChecker {
+	return func(ctx context.Context, userIdentity, permission string) (bool, error) {
+		user := new(models.UserBasic)
+		has, err := eng.Context(ctx).Where("identity = ?", userIdentity).Get(user)
+		if err != nil || !has || user.Disabled {
+			return false, err
+		}
+		return utils.RoleHasPermission(eng, user.Role, permission)
 	}
 }
 
@@ -72,8 +72,8 @@ func TestNewServiceContextUsesDeps(t *testing.T) {
 			calledInitRedis = true
 			return fakeRedis
 		},
-		newFileAuth: func(secret string, expire int64) rest.Middleware {
-			if secret != "secret" || expire != 3600 {
+		newFileAuth: func(secret string, expire int64, rdb RedisClient, eng *xorm.Engine) rest.Middleware {
+			if secret != "secret" || expire != 3600 || rdb != fakeRedis || eng != fakeDB {
 				t.Fatalf("auth args mismatch: %s %d", secret, expire)
 			}
 			calledNewFileAuth = true
@@ -142,3 +142,18 @@ func (f *fakeRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd
 func (f *fakeRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
 	return redis.NewStatusResult("PONG", nil)
 }
+func (f *fakeRedisClient) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	return redis.NewScanCmdResult(nil, 0, nil)
+}
+func (f *fakeRedisClient) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	return redis.NewBoolResult(true, nil)
+}
+func (f *fakeRedisClient) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	return redis.NewIntResult(int64(len(members)), nil)
+}
+func (f *fakeRedisClient) ZRemRangeByScore(ctx context.Context, key, min, max string) *redis.IntCmd {
+	return redis.NewIntResult(0, nil)
+}
+func (f *fakeRedisClient) ZCard(ctx context.Context, key string) *redis.IntCmd {
+	return redis.NewIntResult(0, nil)
+}
@@ -0,0 +1,12 @@
+package types
+
+// MailTask 邮件发送队列消息，入队前已按收件人语言渲染好主题与正文；Attempts 为已失败次数，ExpireAt 为过期时间（Unix 秒，0 表示不过期）。
+type MailTask struct {
+	Identity string `json:"identity"`
+	To       string `json:"to"`
+	Template string `json:"template"`
+	Subject  string `json:"subject"`
+	HTML     string `json:"html"`
+	Attempts int    `json:"attempts"`
+	ExpireAt int64  `json:"expire_at,omitempty"`
+}
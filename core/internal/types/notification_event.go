@@ -0,0 +1,53 @@
+package types
+
+// UploadNotice 异步上传完成或失败的实时通知内容。
+type UploadNotice struct {
+	RepositoryIdentity string `json:"repository_identity"`
+	ParentId           int64  `json:"parent_id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	Error              string `json:"error,omitempty"`
+}
+
+// ShareAccessNotice 分享被访问的实时通知内容，Action 为 view 或 download。
+type ShareAccessNotice struct {
+	ShareIdentity string `json:"share_identity"`
+	Name          string `json:"name"`
+	Action        string `json:"action"`
+	Visitor       string `json:"visitor"`
+}
+
+// QuotaNotice 存储用量告警的实时通知内容。
+type QuotaNotice struct {
+	QuotaBytes int64 `json:"quota_bytes"`
+	UsedBytes  int64 `json:"used_bytes"`
+	Percent    int   `json:"percent"`
+}
+
+// JobNotice 后台任务进度的实时通知内容。
+type JobNotice struct {
+	JobIdentity string `json:"job_identity"`
+	Status      string `json:"status"`
+	Total       int    `json:"total"`
+	Done        int    `json:"done"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ShareReceivedNotice 收到定向分享的站内通知附带数据。
+type ShareReceivedNotice struct {
+	ShareIdentity string `json:"share_identity"`
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Folder        bool   `json:"folder"`
+	Permission    string `json:"permission"`
+	OwnerIdentity string `json:"owner_identity"`
+}
+
+// TrashPurgeNotice 回收站即将清理的站内通知附带数据。
+type TrashPurgeNotice struct {
+	Date          string   `json:"date"`
+	Count         int      `json:"count"`
+	Names         []string `json:"names"`
+	SpaceIdentity string   `json:"space_identity,omitempty"`
+	GroupName     string   `json:"group_name,omitempty"`
+}
@@ -3,6 +3,162 @@
 
 package types
 
+type AccessTokenDeleteRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AccessTokenDeleteResponse struct {
+}
+
+type AccessTokenItem struct {
+	Identity   string   `json:"identity"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpireAt   string   `json:"expire_at"`
+	LastUsedAt string   `json:"last_used_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type AccessTokenListRequest struct {
+}
+
+type AccessTokenListResponse struct {
+	List []*AccessTokenItem `json:"list"`
+}
+
+type AdminAuditRequest struct {
+	UserIdentity       string `form:"user_identity,optional"`
+	ActorIdentity      string `form:"actor_identity,optional"`
+	EventType          string `form:"event_type,optional"`
+	RepositoryIdentity string `form:"repository_identity,optional"`
+	Ip                 string `form:"ip,optional"`
+	Start              string `form:"start,optional"`
+	End                string `form:"end,optional"`
+	Page               int    `form:"page,optional"`
+	Size               int    `form:"size,optional"`
+	Format             string `form:"format,optional"`
+}
+
+type AdminAuditVerifyRequest struct {
+}
+
+type AdminAuditVerifyResponse struct {
+	Valid          bool   `json:"valid"`
+	Checked        int64  `json:"checked"`
+	Legacy         int64  `json:"legacy"`
+	HeadHash       string `json:"head_hash"`
+	BrokenId       int64  `json:"broken_id"`
+	BrokenIdentity string `json:"broken_identity"`
+	Reason         string `json:"reason"`
+}
+
+type AdminPasswordResetRequest struct {
+	Identity    string `json:"identity"`
+	NewPassword string `json:"new_password,optional"`
+}
+
+type AdminPasswordResetResponse struct {
+	Password string `json:"password"`
+}
+
+type AdminRoleItem struct {
+	Role             string   `json:"role"`
+	Permissions      []string `json:"permissions"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+	UserCount        int64    `json:"user_count"`
+}
+
+type AdminRoleListRequest struct {
+}
+
+type AdminRoleListResponse struct {
+	List        []*AdminRoleItem `json:"list"`
+	Permissions []string         `json:"permissions"`
+}
+
+type AdminRolePermissionsRequest struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions,optional"`
+}
+
+type AdminRolePermissionsResponse struct {
+	Message string `json:"message"`
+}
+
+type AdminUserItem struct {
+	Identity  string `json:"identity"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Disabled  bool   `json:"disabled"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AdminUserListRequest struct {
+	Keyword string `form:"keyword,optional"`
+	Role    string `form:"role,optional"`
+	Page    int    `form:"page,optional"`
+	Size    int    `form:"size,optional"`
+}
+
+type AdminUserListResponse struct {
+	List  []*AdminUserItem `json:"list"`
+	Count int64            `json:"count"`
+}
+
+type AdminUserQuotaRequest struct {
+	Identity   string `json:"identity"`
+	QuotaBytes int64  `json:"quota_bytes"`
+}
+
+type AdminUserQuotaResponse struct {
+	Message string `json:"message"`
+}
+
+type AdminUserRoleRequest struct {
+	Identity string `json:"identity"`
+	Role     string `json:"role,optional"`
+}
+
+type AdminUserRoleResponse struct {
+	Message string `json:"message"`
+}
+
+type AdminUserStatusRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AdminUserStatusResponse struct {
+	Message string `json:"message"`
+}
+
+type AuditLogItem struct {
+	Identity           string `json:"identity"`
+	EventType          string `json:"event_type"`
+	UserIdentity       string `json:"user_identity"`
+	ActorIdentity      string `json:"actor_identity"`
+	RepositoryIdentity string `json:"repository_identity"`
+	Ip                 string `json:"ip"`
+	Detail             string `json:"detail"`
+	CreatedAt          string `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	List  []*AuditLogItem `json:"list"`
+	Count int64           `json:"count"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+	OldCode  string `json:"old_code,optional"`
+	NewCode  string `json:"new_code"`
+}
+
+type ChangeEmailResponse struct {
+	Email string `json:"email"`
+}
+
 type ChangePasswordRequest struct {
 	Identity    string `json:"identity"`
 	OldPassword string `json:"old_password"`
@@ -13,13 +169,69 @@ type ChangePasswordResponse struct {
 	Message string `json:"message"`
 }
 
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,optional"`
+}
+
+type CreateAccessTokenResponse struct {
+	Identity string   `json:"identity"`
+	Token    string   `json:"token"`
+	Name     string   `json:"name"`
+	Scopes   []string `json:"scopes"`
+	ExpireAt string   `json:"expire_at"`
+}
+
+type CreateShareGrantRequest struct {
+	Identity    string   `json:"identity"`
+	Users       []string `json:"users,optional"`
+	Emails      []string `json:"emails,optional"`
+	Permission  string   `json:"permission,optional"`
+	ExpiredTime int      `json:"expired_time,optional"`
+}
+
+type CreateShareGrantResponse struct {
+	Identity string `json:"identity"`
+	Code     string `json:"code"`
+}
+
 type CreateShareRecordRequest struct {
-	Identity    string `json:"identity"`
-	ExpiredTime int    `json:"expired_time"`
+	Identity               string `json:"identity,optional"`
+	UserRepositoryIdentity string `json:"user_repository_identity,optional"`
+	ExpiredTime            int    `json:"expired_time"`
 }
 
 type CreateShareRecordResponse struct {
 	Identity string `json:"identity"`
+	Code     string `json:"code"`
+	URL      string `json:"url"`
+}
+
+type CreateUploadLinkRequest struct {
+	ParentId    int64    `json:"parent_id,optional"`
+	ExpiredTime int      `json:"expired_time,optional"`
+	MaxSize     int64    `json:"max_size,optional"`
+	AllowedExts []string `json:"allowed_exts,optional"`
+	Password    string   `json:"password,optional"`
+}
+
+type CreateUploadLinkResponse struct {
+	Identity string `json:"identity"`
+	ExpireAt string `json:"expire_at"`
+}
+
+type CreateWebhookRequest struct {
+	Url        string   `json:"url"`
+	EventTypes []string `json:"event_types,optional"`
+	ParentId   int64    `json:"parent_id,optional"`
+	Secret     string   `json:"secret,optional"`
+	Global     bool     `json:"global,optional"`
+}
+
+type CreateWebhookResponse struct {
+	Identity string `json:"identity"`
+	Secret   string `json:"secret"`
 }
 
 type DownloadURLRequest struct {
@@ -43,14 +255,190 @@ type GetShareRecordResponse struct {
 	Size               int64  `json:"size"`
 }
 
+type GroupCreateRequest struct {
+	Name string `json:"name"`
+}
+
+type GroupCreateResponse struct {
+	Identity     string `json:"identity"`
+	RootId       int64  `json:"root_id"`
+	RootIdentity string `json:"root_identity"`
+}
+
+type GroupDeleteRequest struct {
+	Identity string `json:"identity"`
+}
+
+type GroupDeleteResponse struct {
+	Message string `json:"message"`
+}
+
+type GroupItem struct {
+	Identity      string `json:"identity"`
+	Name          string `json:"name"`
+	Role          string `json:"role"`
+	OwnerIdentity string `json:"owner_identity"`
+	RootId        int64  `json:"root_id"`
+	RootIdentity  string `json:"root_identity"`
+}
+
+type GroupListRequest struct {
+}
+
+type GroupListResponse struct {
+	List []*GroupItem `json:"list"`
+}
+
+type GroupMemberAddRequest struct {
+	Identity string `json:"identity"`
+	UserName string `json:"user_name"`
+	Role     string `json:"role,optional"`
+}
+
+type GroupMemberItem struct {
+	UserIdentity string `json:"user_identity"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
+}
+
+type GroupMemberListRequest struct {
+	Identity string `form:"identity"`
+}
+
+type GroupMemberListResponse struct {
+	List []*GroupMemberItem `json:"list"`
+}
+
+type GroupMemberRemoveRequest struct {
+	Identity     string `json:"identity"`
+	UserIdentity string `json:"user_identity"`
+}
+
+type GroupMemberResponse struct {
+	Message string `json:"message"`
+}
+
+type GroupMemberRoleRequest struct {
+	Identity     string `json:"identity"`
+	UserIdentity string `json:"user_identity"`
+	Role         string `json:"role"`
+}
+
 type LoginRequest struct {
 	Name     string `json:"name,optional"`     // 用户名或邮箱
 	Password string `json:"password,optional"` // 密码
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-	Name  string `json:"name"`
+	Token                  string `json:"token"`
+	RefreshToken           string `json:"refresh_token"`
+	ExpiresIn              int64  `json:"expires_in"`
+	Name                   string `json:"name"`
+	TwoFactorRequired      bool   `json:"two_factor_required"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required"`
+	ChallengeToken         string `json:"challenge_token"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type LoginTwoFactorResponse struct {
+	Token         string   `json:"token"`
+	RefreshToken  string   `json:"refresh_token"`
+	ExpiresIn     int64    `json:"expires_in"`
+	Name          string   `json:"name"`
+	RecoveryCodes []string `json:"recovery_codes,optional"`
+}
+
+type LoginTwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,optional"`
+}
+
+type LogoutResponse struct {
+}
+
+type NotificationItem struct {
+	Identity  string `json:"identity"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Data      string `json:"data"`
+	Read      bool   `json:"read"`
+	ReadAt    string `json:"read_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type NotificationListRequest struct {
+	Unread bool `form:"unread,optional"`
+	Page   int  `form:"page,optional"`
+	Size   int  `form:"size,optional"`
+}
+
+type NotificationListResponse struct {
+	List   []*NotificationItem `json:"list"`
+	Count  int64               `json:"count"`
+	Unread int64               `json:"unread"`
+}
+
+type NotificationReadAllRequest struct {
+}
+
+type NotificationReadRequest struct {
+	Identities []string `json:"identities"`
+}
+
+type NotificationReadResponse struct {
+	Count  int64 `json:"count"`
+	Unread int64 `json:"unread"`
+}
+
+type NotificationStreamRequest struct {
+}
+
+type NotificationUnreadRequest struct {
+}
+
+type NotificationUnreadResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+type OIDCAuthorizeRequest struct {
+}
+
+type OIDCAuthorizeResponse struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type OIDCCallbackResponse struct {
+	Token                  string `json:"token"`
+	RefreshToken           string `json:"refresh_token"`
+	ExpiresIn              int64  `json:"expires_in"`
+	Name                   string `json:"name"`
+	TwoFactorRequired      bool   `json:"two_factor_required"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required"`
+	ChallengeToken         string `json:"challenge_token"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
 
 type RegisterRequest struct {
@@ -61,8 +449,10 @@ type RegisterRequest struct {
 }
 
 type RegisterResponse struct {
-	Token string `json:"token"`
-	Name  string `json:"name"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Name         string `json:"name"`
 }
 
 type ResetPasswordRequest struct {
@@ -75,6 +465,19 @@ type ResetPasswordResponse struct {
 	Message string `json:"message"`
 }
 
+type SaveJobRequest struct {
+	JobId string `form:"job_id"`
+}
+
+type SaveJobResponse struct {
+	JobId    string `json:"job_id"`
+	Status   string `json:"status"`
+	Total    int    `json:"total"`
+	Done     int    `json:"done"`
+	Identity string `json:"identity"`
+	Error    string `json:"error"`
+}
+
 type SaveResourceRequest struct {
 	RepositoryIdentity string `json:"repository_identity"`
 	ParentId           int64  `json:"parent_id"`
@@ -85,14 +488,57 @@ type SaveResourceResponse struct {
 	Identity string `json:"identity"`
 }
 
+type SaveShareFolderRequest struct {
+	ShareIdentity string `json:"share_identity"`
+	ParentId      int64  `json:"parent_id,optional"`
+}
+
+type SaveShareFolderResponse struct {
+	Identity string `json:"identity"`
+	JobId    string `json:"job_id"`
+	Status   string `json:"status"`
+}
+
 type SendVerificationCodeRequest struct {
-	Email string `json:"email,optional"` // 邮箱地址
+	Email   string `json:"email,optional"`   // 邮箱地址
+	Purpose string `json:"purpose,optional"` // 用途：register（默认）、reset、change_email
 }
 
 type SendVerificationCodeResponse struct {
 	Message string `json:"message"` // 返回消息
 }
 
+type SessionItem struct {
+	Identity   string `json:"identity"`
+	Device     string `json:"device"`
+	Ip         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	LastSeenAt string `json:"last_seen_at"`
+	CreatedAt  string `json:"created_at"`
+	Current    bool   `json:"current"`
+}
+
+type SessionListRequest struct {
+}
+
+type SessionListResponse struct {
+	List []*SessionItem `json:"list"`
+}
+
+type SessionRevokeOthersRequest struct {
+}
+
+type SessionRevokeOthersResponse struct {
+	Count int64 `json:"count"`
+}
+
+type SessionRevokeRequest struct {
+	Identity string `json:"identity"`
+}
+
+type SessionRevokeResponse struct {
+}
+
 type ShareDownloadURLRequest struct {
 	ShareIdentity string `json:"share_identity"`
 	Expires       int    `json:"expires"`
@@ -103,6 +549,78 @@ type ShareDownloadURLResponse struct {
 	Expires int    `json:"expires"`
 }
 
+type ShareQRCodeRequest struct {
+	Identity string `form:"identity"`
+	Size     int    `form:"size,optional"`
+}
+
+type SharedWithMeItem struct {
+	ShareIdentity      string `json:"share_identity"`
+	Id                 int64  `json:"id"`
+	Identity           string `json:"identity"`
+	Name               string `json:"name"`
+	Ext                string `json:"ext"`
+	Size               int64  `json:"size"`
+	RepositoryIdentity string `json:"repository_identity"`
+	IsDir              bool   `json:"is_dir"`
+	Permission         string `json:"permission"`
+	OwnerName          string `json:"owner_name"`
+	CreatedAt          string `json:"created_at"`
+}
+
+type SharedWithMeRequest struct {
+	Page int `form:"page,optional"`
+	Size int `form:"size,optional"`
+}
+
+type SharedWithMeResponse struct {
+	List  []*SharedWithMeItem `json:"list"`
+	Count int64               `json:"count"`
+}
+
+type TwoFactorDisableRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorDisableResponse struct {
+	Message string `json:"message"`
+}
+
+type TwoFactorEnableRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorPolicyRequest struct {
+	Role     string `json:"role"`
+	Required bool   `json:"required"`
+}
+
+type TwoFactorPolicyResponse struct {
+	Message string `json:"message"`
+}
+
+type TwoFactorSetupRequest struct {
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+	QrCode     string `json:"qr_code"`
+}
+
+type UnlockAccountRequest struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+type UnlockAccountResponse struct {
+	Message string `json:"message"`
+}
+
 type UploadFileRequest struct {
 	Hash     string `json:"hash,optional"`
 	Name     string `json:"name,optional"`
@@ -116,6 +634,65 @@ type UploadFileResponse struct {
 	Message string `json:"message,optional"`
 }
 
+type UploadLinkDeleteRequest struct {
+	Identity string `json:"identity"`
+}
+
+type UploadLinkDeleteResponse struct {
+}
+
+type UploadLinkInfoRequest struct {
+	Identity string `form:"identity"`
+}
+
+type UploadLinkInfoResponse struct {
+	OwnerName    string   `json:"owner_name"`
+	FolderName   string   `json:"folder_name"`
+	ExpireAt     string   `json:"expire_at"`
+	MaxSize      int64    `json:"max_size"`
+	AllowedExts  []string `json:"allowed_exts"`
+	NeedPassword bool     `json:"need_password"`
+}
+
+type UploadLinkItem struct {
+	Identity     string   `json:"identity"`
+	ParentId     int64    `json:"parent_id"`
+	ExpireAt     string   `json:"expire_at"`
+	MaxSize      int64    `json:"max_size"`
+	AllowedExts  []string `json:"allowed_exts"`
+	NeedPassword bool     `json:"need_password"`
+	UploadCount  int      `json:"upload_count"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+type UploadLinkListRequest struct {
+	Page int `json:"page,optional"`
+	Size int `json:"size,optional"`
+}
+
+type UploadLinkListResponse struct {
+	List  []*UploadLinkItem `json:"list"`
+	Count int64             `json:"count"`
+}
+
+type UploadLinkUploadRequest struct {
+	Identity string `form:"identity"`
+	Password string `form:"password,optional"`
+}
+
+type UploadLinkUploadResponse struct {
+	Message string `json:"message"`
+}
+
+type UserActivityRequest struct {
+	EventType string `form:"event_type,optional"`
+	Start     string `form:"start,optional"`
+	End       string `form:"end,optional"`
+	Page      int    `form:"page,optional"`
+	Size      int    `form:"size,optional"`
+	Format    string `form:"format,optional"`
+}
+
 type UserDetailRequest struct {
 	Identity string `json:"identity"`
 }
@@ -163,6 +740,14 @@ type UserFileNameUpdateRequest struct {
 type UserFileNameUpdateResponse struct {
 }
 
+type UserFileRestoreRequest struct {
+	Identity string `json:"identity"`
+}
+
+type UserFileRestoreResponse struct {
+	Count int64 `json:"count"`
+}
+
 type UserFolderCreateRequest struct {
 	ParentId int64  `json:"parent_id"`
 	Name     string `json:"name"`
@@ -179,3 +764,70 @@ type UserFolderDeleteRequest struct {
 
 type UserFolderDeleteResponse struct {
 }
+
+type UserQuotaRequest struct {
+}
+
+type UserQuotaResponse struct {
+	QuotaBytes int64 `json:"quota_bytes"`
+	UsedBytes  int64 `json:"used_bytes"`
+	TrashBytes int64 `json:"trash_bytes"`
+	Unlimited  bool  `json:"unlimited"`
+}
+
+type WebhookDeleteRequest struct {
+	Identity string `json:"identity"`
+}
+
+type WebhookDeleteResponse struct {
+}
+
+type WebhookDeliveryItem struct {
+	Identity      string `json:"identity"`
+	EventIdentity string `json:"event_identity"`
+	EventType     string `json:"event_type"`
+	Payload       string `json:"payload"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	ResponseCode  int    `json:"response_code"`
+	Error         string `json:"error"`
+	DeliveredAt   string `json:"delivered_at"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type WebhookDeliveryListRequest struct {
+	Identity string `form:"identity"`
+	Status   string `form:"status,optional"`
+	Page     int    `form:"page,optional"`
+	Size     int    `form:"size,optional"`
+}
+
+type WebhookDeliveryListResponse struct {
+	List  []*WebhookDeliveryItem `json:"list"`
+	Count int64                  `json:"count"`
+}
+
+type WebhookItem struct {
+	Identity      string   `json:"identity"`
+	Url           string   `json:"url"`
+	EventTypes    []string `json:"event_types"`
+	SpaceIdentity string   `json:"space_identity"`
+	ParentId      int64    `json:"parent_id"`
+	Global        bool     `json:"global"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+type WebhookListRequest struct {
+	Global bool `form:"global,optional"`
+	Page   int  `form:"page,optional"`
+	Size   int  `form:"size,optional"`
+}
+
+type WebhookListResponse struct {
+	List  []*WebhookItem `json:"list"`
+	Count int64          `json:"count"`
+}
+
+type WebhookRedeliverRequest struct {
+	Identity string `json:"identity"`
+}
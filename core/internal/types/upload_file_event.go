@@ -10,4 +10,6 @@ type UploadEvent struct {
 	IsExisted          bool   `json:"is_existed"`
 	RepositoryIdentity string `json:"repository_identity"`
 	Hash               string `json:"hash"`
+	ActorIdentity      string `json:"actor_identity"`
+	ClientIp           string `json:"client_ip"`
 }
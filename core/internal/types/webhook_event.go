@@ -0,0 +1,19 @@
+package types
+
+// WebhookPayload webhook 请求体，描述一次文件或分享事件。
+type WebhookPayload struct {
+	Event              string `json:"event"`
+	EventIdentity      string `json:"event_identity"`
+	SpaceIdentity      string `json:"space_identity"`
+	ActorIdentity      string `json:"actor_identity"`
+	RepositoryIdentity string `json:"repository_identity"`
+	FolderId           int64  `json:"folder_id"`
+	Ip                 string `json:"ip"`
+	Detail             string `json:"detail"`
+	CreatedAt          string `json:"created_at"`
+}
+
+// WebhookTask webhook 投递队列消息，仅携带投递记录标识，投递内容从数据库读取。
+type WebhookTask struct {
+	DeliveryIdentity string `json:"delivery_identity"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// AuditChainHead 对应 audit_chain_head 表（审计日志哈希链头），记录链上最后一条日志的哈希，写入日志时锁定该行以保证链的顺序。
+type AuditChainHead struct {
+	Id        int
+	Name      string `xorm:"varchar(32) unique"`
+	LastHash  string `xorm:"varchar(64)"`
+	UpdatedAt string `xorm:"updated"`
+}
+
+// TableName 指定数据表名。
+func (table AuditChainHead) TableName() string {
+	return "audit_chain_head"
+}
@@ -1,13 +1,21 @@
 package models
 
-// FileEventLog 对应 file_event_log 表（文件事件日志表）。
+// FileEventLog 对应 file_event_log 表（文件事件日志表），UserIdentity 为文件所属空间，ActorIdentity 为操作人。
+// 每行的 Hash 由行内容与上一行的 Hash 计算得出，构成可校验的哈希链。
 type FileEventLog struct {
-	Id                 int
+	Id                 int64 `xorm:"pk autoincr"`
 	Identity           string
 	RepositoryIdentity string
 	UserIdentity       string
 	EventType          string
-	CreatedAt          string `xorm:"created"`
+	ActorIdentity      string
+	Detail             string `xorm:"varchar(512)"`
+	Ip                 string `xorm:"varchar(64)"`
+	PrevHash           string `xorm:"varchar(64)"`
+	Hash               string `xorm:"varchar(64) index"`
+	CreatedAt          string `xorm:"created index"`
+	// FolderId 事件发生所在的文件夹，不落库，仅用于 webhook 按文件夹匹配订阅。
+	FolderId int64 `xorm:"-"`
 }
 
 // TableName 指定数据表名。
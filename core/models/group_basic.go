@@ -0,0 +1,19 @@
+package models
+
+// GroupBasic 对应 group_basic 表（群组表），群组文件以群组标识作为 user_repository 的所属者。
+type GroupBasic struct {
+	Id            int
+	Identity      string `xorm:"varchar(36) unique"`
+	Name          string `xorm:"varchar(64)"`
+	OwnerIdentity string
+	RootId        int64
+	RootIdentity  string
+	CreatedAt     string `xorm:"created"`
+	UpdatedAt     string `xorm:"updated"`
+	DeletedAt     string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table GroupBasic) TableName() string {
+	return "group_basic"
+}
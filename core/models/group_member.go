@@ -0,0 +1,16 @@
+package models
+
+// GroupMember 对应 group_member 表（群组成员表），每行记录一名成员及其角色。
+type GroupMember struct {
+	Id            int
+	GroupIdentity string `xorm:"varchar(36) unique(group_member)"`
+	UserIdentity  string `xorm:"varchar(36) unique(group_member)"`
+	Role          string `xorm:"varchar(16)"`
+	CreatedAt     string `xorm:"created"`
+	UpdatedAt     string `xorm:"updated"`
+}
+
+// TableName 指定数据表名。
+func (table GroupMember) TableName() string {
+	return "group_member"
+}
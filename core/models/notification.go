@@ -0,0 +1,20 @@
+package models
+
+// Notification 对应 notification 表（站内通知表），Data 为通知附带的 JSON 数据，ReadAt 为空表示未读。
+type Notification struct {
+	Id           int64  `xorm:"pk autoincr"`
+	Identity     string `xorm:"varchar(36) unique"`
+	UserIdentity string `xorm:"index"`
+	Type         string `xorm:"varchar(32)"`
+	Title        string `xorm:"varchar(255)"`
+	Content      string `xorm:"varchar(1024)"`
+	Data         string `xorm:"text"`
+	IsRead       bool   `xorm:"index"`
+	ReadAt       string
+	CreatedAt    string `xorm:"created"`
+}
+
+// TableName 指定数据表名。
+func (table Notification) TableName() string {
+	return "notification"
+}
@@ -0,0 +1,22 @@
+package models
+
+// PersonalAccessToken 对应 personal_access_token 表（个人访问令牌表，仅保存令牌哈希）。
+type PersonalAccessToken struct {
+	Id           int
+	Identity     string
+	UserIdentity string `xorm:"index"`
+	Name         string
+	TokenHash    string `xorm:"varchar(64) unique"`
+	Prefix       string
+	Scopes       string
+	ExpireAt     string
+	LastUsedAt   string
+	CreatedAt    string `xorm:"created"`
+	UpdatedAt    string `xorm:"updated"`
+	DeletedAt    string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table PersonalAccessToken) TableName() string {
+	return "personal_access_token"
+}
@@ -0,0 +1,14 @@
+package models
+
+// RolePermission 对应 role_permission 表（角色权限表），每行授予角色一项管理权限。
+type RolePermission struct {
+	Id         int
+	Role       string `xorm:"varchar(32) unique(role_permission)"`
+	Permission string `xorm:"varchar(64) unique(role_permission)"`
+	CreatedAt  string `xorm:"created"`
+}
+
+// TableName 指定数据表名。
+func (table RolePermission) TableName() string {
+	return "role_permission"
+}
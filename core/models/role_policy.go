@@ -0,0 +1,15 @@
+package models
+
+// RolePolicy 对应 role_policy 表（角色安全策略表）。
+type RolePolicy struct {
+	Id               int
+	Role             string `xorm:"varchar(32) unique"`
+	RequireTwoFactor bool
+	CreatedAt        string `xorm:"created"`
+	UpdatedAt        string `xorm:"updated"`
+}
+
+// TableName 指定数据表名。
+func (table RolePolicy) TableName() string {
+	return "role_policy"
+}
@@ -0,0 +1,19 @@
+package models
+
+// ShareGrant 对应 share_grant 表（定向分享授权表）。
+type ShareGrant struct {
+	Id              int
+	Identity        string
+	ShareIdentity   string
+	GranteeIdentity string
+	GranteeEmail    string
+	Permission      string
+	CreatedAt       string `xorm:"created"`
+	UpdatedAt       string `xorm:"updated"`
+	DeletedAt       string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table ShareGrant) TableName() string {
+	return "share_grant"
+}
@@ -0,0 +1,22 @@
+package models
+
+// UploadLink 对应 upload_link 表（匿名上传链接表）。
+type UploadLink struct {
+	Id           int
+	Identity     string
+	UserIdentity string
+	ParentId     int64
+	ExpireAt     string
+	MaxSize      int64
+	AllowedExts  string
+	Password     string
+	UploadCount  int
+	CreatedAt    string `xorm:"created"`
+	UpdatedAt    string `xorm:"updated"`
+	DeletedAt    string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table UploadLink) TableName() string {
+	return "upload_link"
+}
@@ -5,9 +5,11 @@ type UserBasic struct {
 	Id        int
 	Identity  string
 	Name      string
-	Password  string
-	Email     string
+	Password  string `xorm:"varchar(255)"`
+	Email     string `xorm:"varchar(255) unique null"`
 	Role      string
+	Disabled  bool
+	Language  string `xorm:"varchar(8)"`
 	CreatedAt string `xorm:"created"`
 	UpdatedAt string `xorm:"updated"`
 	DeletedAt string `xorm:"deleted"`
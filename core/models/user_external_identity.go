@@ -0,0 +1,19 @@
+package models
+
+// UserExternalIdentity 对应 user_external_identity 表（外部身份提供方账号与本地用户的绑定）。
+type UserExternalIdentity struct {
+	Id           int
+	Identity     string
+	UserIdentity string `xorm:"index"`
+	Issuer       string `xorm:"varchar(255) unique(issuer_subject)"`
+	Subject      string `xorm:"varchar(255) unique(issuer_subject)"`
+	Email        string
+	CreatedAt    string `xorm:"created"`
+	UpdatedAt    string `xorm:"updated"`
+	DeletedAt    string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table UserExternalIdentity) TableName() string {
+	return "user_external_identity"
+}
@@ -0,0 +1,18 @@
+package models
+
+// UserQuota 对应 user_quota 表（用户存储配额表）。
+// QuotaBytes 小于等于 0 表示不限制；UsedBytes 为正常文件占用，TrashBytes 为回收站中文件占用。
+type UserQuota struct {
+	Id           int
+	UserIdentity string `xorm:"unique"`
+	QuotaBytes   int64
+	UsedBytes    int64
+	TrashBytes   int64
+	CreatedAt    string `xorm:"created"`
+	UpdatedAt    string `xorm:"updated"`
+}
+
+// TableName 指定数据表名。
+func (table UserQuota) TableName() string {
+	return "user_quota"
+}
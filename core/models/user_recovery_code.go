@@ -0,0 +1,15 @@
+package models
+
+// UserRecoveryCode 对应 user_recovery_code 表（两步验证一次性恢复码表）。
+type UserRecoveryCode struct {
+	Id           int
+	UserIdentity string `xorm:"index"`
+	CodeHash     string
+	UsedAt       string
+	CreatedAt    string `xorm:"created"`
+}
+
+// TableName 指定数据表名。
+func (table UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
@@ -0,0 +1,21 @@
+package models
+
+// UserSession 对应 user_session 表（登录会话表，记录设备与最近活跃时间）。
+type UserSession struct {
+	Id           int
+	Identity     string `xorm:"varchar(36) unique"`
+	UserIdentity string `xorm:"index"`
+	Device       string
+	Ip           string
+	UserAgent    string `xorm:"varchar(512)"`
+	LastSeenAt   string
+	ExpireAt     string
+	CreatedAt    string `xorm:"created"`
+	UpdatedAt    string `xorm:"updated"`
+	DeletedAt    string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table UserSession) TableName() string {
+	return "user_session"
+}
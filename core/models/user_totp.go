@@ -0,0 +1,16 @@
+package models
+
+// UserTotp 对应 user_totp 表（用户 TOTP 两步验证表）。
+type UserTotp struct {
+	Id           int
+	UserIdentity string `xorm:"varchar(36) unique"`
+	Secret       string
+	Enabled      bool
+	CreatedAt    string `xorm:"created"`
+	UpdatedAt    string `xorm:"updated"`
+}
+
+// TableName 指定数据表名。
+func (table UserTotp) TableName() string {
+	return "user_totp"
+}
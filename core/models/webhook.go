@@ -0,0 +1,21 @@
+package models
+
+// Webhook 对应 webhook 表（webhook 订阅表）。SpaceIdentity 为订阅的存储空间（用户或群组），为空表示管理员创建的全站订阅。
+type Webhook struct {
+	Id            int
+	Identity      string `xorm:"varchar(36) unique"`
+	UserIdentity  string `xorm:"index"`
+	SpaceIdentity string `xorm:"index"`
+	ParentId      int64
+	Url           string `xorm:"varchar(1024)"`
+	EventTypes    string `xorm:"varchar(255)"`
+	Secret        string `xorm:"varchar(128)"`
+	CreatedAt     string `xorm:"created"`
+	UpdatedAt     string `xorm:"updated"`
+	DeletedAt     string `xorm:"deleted"`
+}
+
+// TableName 指定数据表名。
+func (table Webhook) TableName() string {
+	return "webhook"
+}
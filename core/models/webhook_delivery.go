@@ -0,0 +1,23 @@
+package models
+
+// WebhookDelivery 对应 webhook_delivery 表（webhook 投递记录表），Payload 为发送的请求体，重新投递时原样发送。
+type WebhookDelivery struct {
+	Id              int64  `xorm:"pk autoincr"`
+	Identity        string `xorm:"varchar(36) unique"`
+	WebhookIdentity string `xorm:"index"`
+	EventIdentity   string
+	EventType       string
+	Payload         string `xorm:"text"`
+	Status          string `xorm:"varchar(16)"`
+	Attempts        int
+	ResponseCode    int
+	Error           string `xorm:"varchar(512)"`
+	DeliveredAt     string
+	CreatedAt       string `xorm:"created"`
+	UpdatedAt       string `xorm:"updated"`
+}
+
+// TableName 指定数据表名。
+func (table WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
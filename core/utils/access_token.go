@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// PersonalAccessTokenPrefix 个人访问令牌前缀，便于识别与密钥扫描。
+const PersonalAccessTokenPrefix = "cdp_"
+
+// personalAccessTokenLength 个人访问令牌随机部分长度。
+const personalAccessTokenLength = 40
+
+// GeneratePersonalAccessToken 生成新的个人访问令牌明文。
+func GeneratePersonalAccessToken() (string, error) {
+	code, err := Base62Code(personalAccessTokenLength)
+	if err != nil {
+		return "", err
+	}
+	return PersonalAccessTokenPrefix + code, nil
+}
+
+// IsPersonalAccessToken 判断令牌是否为个人访问令牌。
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// HashToken 计算令牌的 SHA-256 哈希，用于只保存哈希的令牌存储。
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cloud_disk/core/common"
+	"cloud_disk/core/models"
+
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+// auditDetailMaxLen 审计详情字段的最大字节数，与 file_event_log.detail 列宽一致。
+const auditDetailMaxLen = 512
+
+// auditChainName 文件事件日志哈希链在链头表中的名称。
+const auditChainName = "file_event_log"
+
+// auditVerifyBatch 校验哈希链时每批读取的日志条数。
+const auditVerifyBatch = 1000
+
+// auditChainMu 串行化本进程内的日志写入；多实例之间依赖链头行锁（MySQL）或数据库写锁（SQLite）。
+var auditChainMu sync.Mutex
+
+// RecordFileEvent 写入文件事件日志，详情超长时按字符截断。所有事件日志均经此写入，
+// 写入时在事务中锁定链头，依次为每条日志计算哈希并接到链尾。
+func RecordFileEvent(engine *xorm.Engine, events ...*models.FileEventLog) error {
+	if len(events) == 0 {
+		return nil
+	}
+	auditChainMu.Lock()
+	defer auditChainMu.Unlock()
+
+	session := engine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	head, err := lockAuditChainHead(engine, session)
+	if err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	now := time.Now().Format(common.DataTimeFormat)
+	prev := head.LastHash
+	rows := make([]models.FileEventLog, 0, len(events))
+	for _, event := range events {
+		if event.Identity == "" {
+			event.Identity = UUID()
+		}
+		event.Detail = TruncateUTF8(event.Detail, auditDetailMaxLen)
+		event.CreatedAt = now
+		event.PrevHash = prev
+		event.Hash = FileEventHash(event)
+		prev = event.Hash
+		rows = append(rows, *event)
+	}
+	// 创建时间参与哈希计算，须使用上面写入的值而非由 ORM 自动填充
+	if _, err := session.NoAutoTime().Insert(&rows); err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	if _, err := session.Table(new(models.AuditChainHead)).Where("name = ?", auditChainName).
+		Update(map[string]any{"last_hash": prev}); err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+// lockAuditChainHead 在事务中读取并锁定链头，链头不存在时创建。
+func lockAuditChainHead(engine *xorm.Engine, session *xorm.Session) (*models.AuditChainHead, error) {
+	head := new(models.AuditChainHead)
+	query := session.Where("name = ?", auditChainName)
+	if engine.Dialect().URI().DBType == schemas.MYSQL {
+		query = query.ForUpdate()
+	}
+	has, err := query.Get(head)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		head.Name = auditChainName
+		if _, err := session.Insert(head); err != nil {
+			return nil, err
+		}
+	}
+	return head, nil
+}
+
+// FileEventHash 计算日志哈希：对上一条哈希与日志各字段按固定顺序 JSON 编码后取 SHA-256。
+// 除自增 Id 与 Hash 本身外，每个落库字段都须参与计算；新增落库字段时要同步追加到末尾，已有字段的顺序不可调整，否则历史日志将校验失败。
+func FileEventHash(event *models.FileEventLog) string {
+	content, _ := json.Marshal([]string{
+		event.PrevHash,
+		event.Identity,
+		event.EventType,
+		event.UserIdentity,
+		event.ActorIdentity,
+		event.RepositoryIdentity,
+		event.Ip,
+		event.Detail,
+		event.CreatedAt,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditChainReport 哈希链校验结果；Valid 为 false 时 BrokenId 指向第一处断链的日志。
+type AuditChainReport struct {
+	Valid          bool
+	Checked        int64
+	Legacy         int64
+	HeadHash       string
+	BrokenId       int64
+	BrokenIdentity string
+	Reason         string
+}
+
+// VerifyFileEventChain 按写入顺序遍历文件事件日志并逐条校验哈希链，返回第一处断链。
+// 启用哈希链之前写入的日志（没有哈希）计入 Legacy 不参与校验；链头哈希先于遍历读取，遍历期间新写入的日志同样会被校验。
+func VerifyFileEventChain(engine *xorm.Engine) (*AuditChainReport, error) {
+	head := new(models.AuditChainHead)
+	if _, err := engine.Where("name = ?", auditChainName).Get(head); err != nil {
+		return nil, err
+	}
+	report := &AuditChainReport{HeadHash: head.LastHash}
+	broken := func(log *models.FileEventLog, reason string) (*AuditChainReport, error) {
+		report.BrokenId = log.Id
+		report.BrokenIdentity = log.Identity
+		report.Reason = reason
+		return report, nil
+	}
+
+	started := false
+	headSeen := head.LastHash == ""
+	prev := ""
+	var lastId int64
+	for {
+		var logs []models.FileEventLog
+		if err := engine.Where("id > ?", lastId).Asc("id").Limit(auditVerifyBatch).Find(&logs); err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			log := &logs[i]
+			lastId = log.Id
+			if !started && log.Hash == "" {
+				report.Legacy++
+				continue
+			}
+			started = true
+			if log.Hash == "" {
+				return broken(log, "缺少哈希（日志被篡改）")
+			}
+			if log.PrevHash != prev {
+				return broken(log, "上一条哈希不匹配（之前的日志被删除或插入）")
+			}
+			if FileEventHash(log) != log.Hash {
+				return broken(log, "内容哈希不匹配（日志被篡改）")
+			}
+			prev = log.Hash
+			report.Checked++
+			if log.Hash == head.LastHash {
+				headSeen = true
+			}
+		}
+		if len(logs) < auditVerifyBatch {
+			break
+		}
+	}
+	if !headSeen {
+		report.BrokenId = lastId
+		report.Reason = "链头指向的日志不存在（末尾日志被删除）"
+		return report, nil
+	}
+	report.Valid = true
+	return report, nil
+}
+
+// TruncateUTF8 将字符串截断到不超过 max 字节，且不截断多字节字符。
+func TruncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
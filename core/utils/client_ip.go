@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	trustedProxiesMu sync.RWMutex
+	// trustedProxies 可信反向代理网段，仅来自这些地址的请求才采用 X-Forwarded-For。
+	trustedProxies []*net.IPNet
+)
+
+// SetTrustedProxies 设置可信反向代理，支持 CIDR 或单个 IP；为空时忽略所有转发头。
+func SetTrustedProxies(list []string) error {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", item)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			item = fmt.Sprintf("%s/%d", ip.String(), bits)
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	trustedProxiesMu.Lock()
+	trustedProxies = nets
+	trustedProxiesMu.Unlock()
+	return nil
+}
+
+// isTrustedProxy 判断地址是否属于可信反向代理。
+func isTrustedProxy(ip net.IP) bool {
+	trustedProxiesMu.RLock()
+	defer trustedProxiesMu.RUnlock()
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 返回请求的客户端 IP（不含端口）。
+// 直连地址不是可信代理时忽略 X-Forwarded-For，避免客户端伪造来源绕过按 IP 的限流；
+// 否则从右向左跳过可信代理，取第一个不可信的地址。
+func ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	ip := net.ParseIP(remote)
+	if ip == nil || !isTrustedProxy(ip) {
+		return remote
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		if !isTrustedProxy(hop) {
+			return hop.String()
+		}
+		remote = hop.String()
+	}
+	return remote
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"cloud_disk/core/models"
 	"fmt"
+	"strings"
 
 	"xorm.io/xorm"
 	"xorm.io/xorm/schemas"
@@ -10,6 +11,12 @@ import (
 
 // EnsureSchema 同步数据库表结构。
 func EnsureSchema(engine *xorm.Engine) error {
+	if err := migrateEmptyToNull(engine, new(models.UserBasic).TableName(), "email"); err != nil {
+		return err
+	}
+	if err := checkDuplicateEmails(engine); err != nil {
+		return err
+	}
 	if err := engine.Sync2(new(models.UserBasic)); err != nil {
 		return fmt.Errorf("sync user_basic: %w", err)
 	}
@@ -19,15 +26,69 @@ func EnsureSchema(engine *xorm.Engine) error {
 	if err := engine.Sync2(new(models.UserRepository)); err != nil {
 		return fmt.Errorf("sync user_repository: %w", err)
 	}
+	if err := migrateEmptyToNull(engine, new(models.ShareBasic).TableName(), "code"); err != nil {
+		return err
+	}
 	if err := engine.Sync2(new(models.ShareBasic)); err != nil {
 		return fmt.Errorf("sync share_basic: %w", err)
 	}
 	if err := engine.Sync2(new(models.FileEventLog)); err != nil {
 		return fmt.Errorf("sync file_event_log: %w", err)
 	}
+	if err := engine.Sync2(new(models.ShareGrant)); err != nil {
+		return fmt.Errorf("sync share_grant: %w", err)
+	}
+	if err := engine.Sync2(new(models.UploadLink)); err != nil {
+		return fmt.Errorf("sync upload_link: %w", err)
+	}
+	if err := engine.Sync2(new(models.UserTotp)); err != nil {
+		return fmt.Errorf("sync user_totp: %w", err)
+	}
+	if err := engine.Sync2(new(models.UserRecoveryCode)); err != nil {
+		return fmt.Errorf("sync user_recovery_code: %w", err)
+	}
+	if err := engine.Sync2(new(models.RolePolicy)); err != nil {
+		return fmt.Errorf("sync role_policy: %w", err)
+	}
+	if err := engine.Sync2(new(models.PersonalAccessToken)); err != nil {
+		return fmt.Errorf("sync personal_access_token: %w", err)
+	}
+	if err := engine.Sync2(new(models.UserSession)); err != nil {
+		return fmt.Errorf("sync user_session: %w", err)
+	}
+	if err := engine.Sync2(new(models.UserExternalIdentity)); err != nil {
+		return fmt.Errorf("sync user_external_identity: %w", err)
+	}
+	if err := engine.Sync2(new(models.RolePermission)); err != nil {
+		return fmt.Errorf("sync role_permission: %w", err)
+	}
+	if err := engine.Sync2(new(models.UserQuota)); err != nil {
+		return fmt.Errorf("sync user_quota: %w", err)
+	}
+	if err := engine.Sync2(new(models.GroupBasic)); err != nil {
+		return fmt.Errorf("sync group_basic: %w", err)
+	}
+	if err := engine.Sync2(new(models.GroupMember)); err != nil {
+		return fmt.Errorf("sync group_member: %w", err)
+	}
+	if err := engine.Sync2(new(models.AuditChainHead)); err != nil {
+		return fmt.Errorf("sync audit_chain_head: %w", err)
+	}
+	if err := engine.Sync2(new(models.Webhook)); err != nil {
+		return fmt.Errorf("sync webhook: %w", err)
+	}
+	if err := engine.Sync2(new(models.WebhookDelivery)); err != nil {
+		return fmt.Errorf("sync webhook_delivery: %w", err)
+	}
+	if err := engine.Sync2(new(models.Notification)); err != nil {
+		return fmt.Errorf("sync notification: %w", err)
+	}
 	if err := ensureAutoIncrement(engine); err != nil {
 		return err
 	}
+	if err := ensurePasswordColumn(engine); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -39,6 +100,22 @@ func TablesHealthy(engine *xorm.Engine) error {
 		new(models.UserRepository).TableName(),
 		new(models.ShareBasic).TableName(),
 		new(models.FileEventLog).TableName(),
+		new(models.ShareGrant).TableName(),
+		new(models.UploadLink).TableName(),
+		new(models.UserTotp).TableName(),
+		new(models.UserRecoveryCode).TableName(),
+		new(models.RolePolicy).TableName(),
+		new(models.PersonalAccessToken).TableName(),
+		new(models.UserSession).TableName(),
+		new(models.UserExternalIdentity).TableName(),
+		new(models.RolePermission).TableName(),
+		new(models.UserQuota).TableName(),
+		new(models.GroupBasic).TableName(),
+		new(models.GroupMember).TableName(),
+		new(models.AuditChainHead).TableName(),
+		new(models.Webhook).TableName(),
+		new(models.WebhookDelivery).TableName(),
+		new(models.Notification).TableName(),
 	}
 	for _, n := range names {
 		ok, err := engine.IsTableExist(n)
@@ -58,10 +135,26 @@ func TablesHealthy(engine *xorm.Engine) error {
 		metaMap[m.Name] = m
 	}
 	requiredCols := map[string][]string{
-		new(models.RepositoryPool).TableName(): {"identity", "hash", "object_key", "status", "expire_at"},
-		new(models.UserRepository).TableName(): {"identity", "user_identity", "repository_identity", "status", "expire_at", "parent_id"},
-		new(models.ShareBasic).TableName():     {"identity", "repository_identity", "expired_time"},
-		new(models.FileEventLog).TableName():   {"identity", "repository_identity", "user_identity", "event_type"},
+		new(models.RepositoryPool).TableName():       {"identity", "hash", "object_key", "status", "expire_at"},
+		new(models.UserRepository).TableName():       {"identity", "user_identity", "repository_identity", "status", "expire_at", "parent_id"},
+		new(models.ShareBasic).TableName():           {"identity", "code", "user_identity", "repository_identity", "user_repository_identity", "scope", "expired_time"},
+		new(models.FileEventLog).TableName():         {"identity", "repository_identity", "user_identity", "event_type", "actor_identity", "detail", "ip", "prev_hash", "hash"},
+		new(models.ShareGrant).TableName():           {"identity", "share_identity", "grantee_identity", "grantee_email", "permission"},
+		new(models.UploadLink).TableName():           {"identity", "user_identity", "parent_id", "expire_at", "max_size", "allowed_exts"},
+		new(models.UserTotp).TableName():             {"user_identity", "secret", "enabled"},
+		new(models.UserRecoveryCode).TableName():     {"user_identity", "code_hash", "used_at"},
+		new(models.PersonalAccessToken).TableName():  {"identity", "user_identity", "token_hash", "scopes", "expire_at"},
+		new(models.RolePolicy).TableName():           {"role", "require_two_factor"},
+		new(models.UserSession).TableName():          {"identity", "user_identity", "ip", "user_agent", "last_seen_at", "expire_at"},
+		new(models.UserExternalIdentity).TableName(): {"user_identity", "issuer", "subject"},
+		new(models.RolePermission).TableName():       {"role", "permission"},
+		new(models.UserQuota).TableName():            {"user_identity", "quota_bytes", "used_bytes", "trash_bytes"},
+		new(models.GroupBasic).TableName():           {"identity", "name", "owner_identity", "root_id"},
+		new(models.GroupMember).TableName():          {"group_identity", "user_identity", "role"},
+		new(models.AuditChainHead).TableName():       {"name", "last_hash"},
+		new(models.Webhook).TableName():              {"identity", "user_identity", "space_identity", "parent_id", "url", "event_types", "secret"},
+		new(models.WebhookDelivery).TableName():      {"identity", "webhook_identity", "event_type", "payload", "status", "attempts"},
+		new(models.Notification).TableName():         {"identity", "user_identity", "type", "title", "content", "data", "is_read", "read_at"},
 	}
 	for table, cols := range requiredCols {
 		meta, ok := metaMap[table]
@@ -83,6 +176,80 @@ func TablesHealthy(engine *xorm.Engine) error {
 	return nil
 }
 
+// migrateEmptyToNull 将旧数据中的空字符串置为 NULL，以便为可选字段（如分享短码、用户邮箱）建立唯一索引。
+func migrateEmptyToNull(engine *xorm.Engine, table, column string) error {
+	metas, err := engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		if meta.Name != table || meta.GetColumn(column) == nil {
+			continue
+		}
+		if _, err := engine.Exec(fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s = ''", meta.Name, column, column)); err != nil {
+			return fmt.Errorf("migrate %s %s: %w", table, column, err)
+		}
+	}
+	return nil
+}
+
+// maxReportedDuplicates 重复邮箱报错时最多列出的邮箱数。
+const maxReportedDuplicates = 20
+
+// checkDuplicateEmails 在建立邮箱唯一索引前检查重复邮箱（不区分大小写与首尾空格，与 MySQL 默认排序规则一致），
+// 存在重复时返回列出冲突邮箱的错误；重复邮箱归属不同账号，需人工合并或清空后再启动。
+func checkDuplicateEmails(engine *xorm.Engine) error {
+	metas, err := engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	table := new(models.UserBasic).TableName()
+	found := false
+	for _, meta := range metas {
+		if meta.Name == table && meta.GetColumn("email") != nil {
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	type duplicate struct {
+		Email string `xorm:"email"`
+		Cnt   int64  `xorm:"cnt"`
+	}
+	var rows []duplicate
+	err = engine.SQL(fmt.Sprintf(
+		"SELECT LOWER(TRIM(email)) AS email, COUNT(*) AS cnt FROM %s WHERE email IS NOT NULL AND email != '' GROUP BY LOWER(TRIM(email)) HAVING COUNT(*) > 1 ORDER BY email",
+		table)).Find(&rows)
+	if err != nil {
+		return fmt.Errorf("check duplicate emails: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(rows))
+	for i, r := range rows {
+		if i == maxReportedDuplicates {
+			list = append(list, fmt.Sprintf("... (%d more)", len(rows)-i))
+			break
+		}
+		list = append(list, fmt.Sprintf("%s (%d)", r.Email, r.Cnt))
+	}
+	return fmt.Errorf("user_basic has %d duplicate emails, merge or clear them before the unique email index can be created: %s",
+		len(rows), strings.Join(list, ", "))
+}
+
+// IsDuplicateKeyError 判断错误是否为唯一索引冲突（兼容 MySQL、PostgreSQL 与 SQLite）。
+func IsDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Duplicate entry") ||
+		strings.Contains(msg, "duplicate key value") ||
+		strings.Contains(msg, "UNIQUE constraint failed")
+}
+
 func ensureAutoIncrement(engine *xorm.Engine) error {
 	metas, err := engine.DBMetas()
 	if err != nil {
@@ -92,7 +259,39 @@ func ensureAutoIncrement(engine *xorm.Engine) error {
 	for _, m := range metas {
 		metaMap[m.Name] = m
 	}
-	return ensureTableAutoIncrement(engine, metaMap, new(models.UserRepository).TableName(), "id")
+	if err := ensureTableAutoIncrement(engine, metaMap, new(models.UserRepository).TableName(), "id"); err != nil {
+		return err
+	}
+	// 审计日志按自增编号排序，旧库中的 id 列需补齐自增主键
+	return ensureTableAutoIncrement(engine, metaMap, new(models.FileEventLog).TableName(), "id")
+}
+
+// passwordColumnLength 密码哈希字段所需长度（argon2id 哈希约 100 字符）。
+const passwordColumnLength = 255
+
+// ensurePasswordColumn 将旧库中按 MD5 长度建立的密码字段扩容。
+func ensurePasswordColumn(engine *xorm.Engine) error {
+	if engine.Dialect().URI().DBType != schemas.MYSQL {
+		return nil
+	}
+	metas, err := engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		if meta.Name != new(models.UserBasic).TableName() {
+			continue
+		}
+		col := meta.GetColumn("password")
+		if col == nil || col.Length >= passwordColumnLength {
+			return nil
+		}
+		_, err = engine.Exec(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN password VARCHAR(%d)", meta.Name, passwordColumnLength))
+		if err != nil {
+			return fmt.Errorf("table %s widen password failed: %w", meta.Name, err)
+		}
+	}
+	return nil
 }
 
 func ensureTableAutoIncrement(engine *xorm.Engine, metaMap map[string]*schemas.Table, tableName, column string) error {
@@ -178,6 +377,22 @@ func ensureIndexes(metaMap map[string]*schemas.Table) error {
 	if !indexHasColumns(repo, []string{"hash", "status"}) {
 		return fmt.Errorf("table %s missing index on hash,status", repo.Name)
 	}
+
+	user := metaMap[new(models.UserBasic).TableName()]
+	if user == nil {
+		return fmt.Errorf("table %s meta missing", new(models.UserBasic).TableName())
+	}
+	if !uniqueIndexHasColumns(user, []string{"email"}) {
+		return fmt.Errorf("table %s missing unique index on email", user.Name)
+	}
+
+	share := metaMap[new(models.ShareBasic).TableName()]
+	if share == nil {
+		return fmt.Errorf("table %s meta missing", new(models.ShareBasic).TableName())
+	}
+	if !uniqueIndexHasColumns(share, []string{"code"}) {
+		return fmt.Errorf("table %s missing unique index on code", share.Name)
+	}
 	return nil
 }
 
@@ -209,6 +424,19 @@ func indexHasColumns(table *schemas.Table, cols []string) bool {
 	return false
 }
 
+// uniqueIndexHasColumns 判断是否存在匹配字段集合的唯一索引。
+func uniqueIndexHasColumns(table *schemas.Table, cols []string) bool {
+	if table == nil {
+		return false
+	}
+	for _, idx := range table.Indexes {
+		if idx.Type == schemas.UniqueType && equalColumns(idx.Cols, cols) {
+			return true
+		}
+	}
+	return false
+}
+
 // equalColumns 判断两个字段集合是否等价。
 func equalColumns(left, right []string) bool {
 	if len(left) != len(right) {
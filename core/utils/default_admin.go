@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"cloud_disk/core/common"
 	"cloud_disk/core/models"
 	"crypto/rand"
 	"fmt"
@@ -34,12 +35,16 @@ func EnsureDefaultAdmin(engine *xorm.Engine) error {
 		return nil
 	}
 
+	hashed, err := HashPassword(pass)
+	if err != nil {
+		return err
+	}
 	user := &models.UserBasic{
 		Name:     name,
 		Email:    email,
-		Password: Md5(pass),
+		Password: hashed,
 		Identity: UUID(),
-		Role:     "admin",
+		Role:     common.RoleAdmin,
 	}
 	_, err = engine.InsertOne(user)
 	if err != nil {
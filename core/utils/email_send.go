@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"cloud_disk/core/common"
+
 	"github.com/joho/godotenv"
 	"github.com/jordan-wright/email"
 )
@@ -20,6 +22,7 @@ var (
 	emailPort     string
 	emailUser     string
 	emailPassword string
+	emailStartTLS bool
 	emailSender   func(*email.Email, string, string, string, string) error
 	emailDialer   func(ctx context.Context, network, addr string) (net.Conn, error)
 )
@@ -37,9 +40,18 @@ func init() {
 	emailPort = os.Getenv("EMAIL_PORT")
 	emailUser = os.Getenv("EMAIL_USER")
 	emailPassword = os.Getenv("EMAIL_PASSWORD")
+	switch strings.ToLower(os.Getenv("EMAIL_STARTTLS")) {
+	case "false", "0", "off", "no":
+		emailStartTLS = false
+	default:
+		emailStartTLS = true
+	}
 	if emailSender == nil {
 		emailSender = func(e *email.Email, host, port, user, pass string) error {
 			addr := fmt.Sprintf("%s:%s", host, port)
+			if !emailStartTLS {
+				return e.Send(addr, smtp.PlainAuth("", user, pass, host))
+			}
 			return e.SendWithStartTLS(addr, smtp.PlainAuth("", user, pass, host), &tls.Config{
 				ServerName:         host,
 				InsecureSkipVerify: true,
@@ -68,6 +80,16 @@ func SetEmailConfig(enabled bool, host, port, user, pass string) {
 	setEmailConfig(enabled, host, port, user, pass)
 }
 
+// SetEmailStartTLS 设置是否使用 STARTTLS 连接邮件服务器；本地 SMTP 测试服务可关闭。
+func SetEmailStartTLS(enabled bool) {
+	emailStartTLS = enabled
+}
+
+// EmailStartTLS 返回是否使用 STARTTLS 连接邮件服务器。
+func EmailStartTLS() bool {
+	return emailStartTLS
+}
+
 // setEmailSender 设置邮件发送函数。
 func setEmailSender(sender func(*email.Email, string, string, string, string) error) {
 	emailSender = sender
@@ -123,8 +145,19 @@ func EmailDialer() func(ctx context.Context, network, addr string) (net.Conn, er
 	return emailDialer
 }
 
-// SendEmail 发送验证码邮件。
+// SendEmail 以默认语言的注册验证码模板同步发送验证码邮件。
 func SendEmail(emailAddress, code string) error {
+	subject, body, err := RenderMail(common.MailVerification, DefaultMailLang(), map[string]any{
+		"Code": code, "Purpose": common.CodePurposeRegister, "Minutes": 5,
+	})
+	if err != nil {
+		return err
+	}
+	return SendNotifyEmail(emailAddress, subject, body)
+}
+
+// SendNotifyEmail 发送通知类 HTML 邮件。
+func SendNotifyEmail(emailAddress, subject, html string) error {
 	if !emailEnabled {
 		return nil
 	}
@@ -134,11 +167,8 @@ func SendEmail(emailAddress, code string) error {
 	e := email.NewEmail()
 	e.From = fmt.Sprintf("CloudDisk <%s>", emailUser)
 	e.To = []string{emailAddress}
-	//e.Bcc = []string{"test_bcc@example.com"}
-	//e.Cc = []string{"test_cc@example.com"}
-	e.Subject = "玺朽GO 邮箱Server"
-	//e.Text = []byte("Text Body is, of course, supported!")
-	e.HTML = []byte("你的验证码：<h1>" + code + "</h1>")
+	e.Subject = subject
+	e.HTML = []byte(html)
 	err := emailSender(e, emailHost, emailPort, emailUser, emailPassword)
 	if err != nil {
 		return err
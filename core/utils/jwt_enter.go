@@ -2,6 +2,8 @@ package utils
 
 import (
 	"errors"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -9,9 +11,10 @@ import (
 
 // JwtPayLoad JWT Payload 结构。
 type JwtPayLoad struct {
-	Id       int
-	Identity string
-	Name     string
+	Id        int
+	Identity  string
+	Name      string
+	SessionId string
 }
 
 // CustomClaims 自定义 JWT 声明。
@@ -22,20 +25,36 @@ type CustomClaims struct {
 
 // GenToken 生成 JWT Token。
 func GenToken(user JwtPayLoad, accessSecret string, expires int64) (string, error) {
-	claims := CustomClaims{
+	token, _, err := GenAccessToken(user, accessSecret, time.Hour*time.Duration(expires))
+	return token, err
+}
+
+// GenAccessToken 生成带唯一 ID（jti）的访问令牌，并返回其声明。
+func GenAccessToken(user JwtPayLoad, accessSecret string, ttl time.Duration) (string, *CustomClaims, error) {
+	now := time.Now()
+	claims := &CustomClaims{
 		JwtPayLoad: user,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expires))),
+			ID:        UUID(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(accessSecret))
+	signed, err := token.SignedString([]byte(accessSecret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 // ParseToken 解析 JWT Token。
 func ParseToken(tokenStr string, accessSecret string, expires int64) (*CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
 		return []byte(accessSecret), nil
 	})
 	if err != nil {
@@ -47,3 +66,36 @@ func ParseToken(tokenStr string, accessSecret string, expires int64) (*CustomCla
 	}
 	return nil, errors.New("invalid token")
 }
+
+// AccessTokenTTL 返回访问令牌有效期，expireSeconds 取自配置 Auth.AccessExpire（秒），未配置时默认 15 分钟。
+func AccessTokenTTL(expireSeconds int64) time.Duration {
+	if expireSeconds > 0 {
+		return time.Duration(expireSeconds) * time.Second
+	}
+	return 15 * time.Minute
+}
+
+// RefreshTokenTTL 返回刷新令牌有效期，默认 30 天。
+func RefreshTokenTTL() time.Duration {
+	if v := os.Getenv("REFRESH_TOKEN_TTL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 30 * 24 * time.Hour
+}
+
+// RevokedTokenKey 返回已吊销访问令牌在 Redis 中的键。
+func RevokedTokenKey(jti string) string {
+	return "revoked_token:" + jti
+}
+
+// SessionKey 返回登录会话在 Redis 中的键，值为会话所属用户标识。
+func SessionKey(sid string) string {
+	return "user_session:" + sid
+}
+
+// SessionSeenKey 返回会话最近活跃时间节流标记的键。
+func SessionSeenKey(sid string) string {
+	return "user_session_seen:" + sid
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"strings"
+	"sync"
+
+	"cloud_disk/core/common"
+)
+
+// mailTemplateFS 内置的邮件模板，每种语言一个文件，模板名为 "<邮件类型>.subject" 与 "<邮件类型>.body"。
+//
+//go:embed mail_templates/*.html
+var mailTemplateFS embed.FS
+
+// mailLangs 支持的邮件语言。
+var mailLangs = []string{common.LangZh, common.LangEn}
+
+var (
+	mailTemplatesOnce sync.Once
+	mailTemplates     map[string]*template.Template
+	mailTemplatesErr  error
+)
+
+// loadMailTemplates 解析全部语言的邮件模板，缺少数据字段时渲染报错而不是输出空值。
+func loadMailTemplates() (map[string]*template.Template, error) {
+	mailTemplatesOnce.Do(func() {
+		mailTemplates = make(map[string]*template.Template, len(mailLangs))
+		for _, lang := range mailLangs {
+			t, err := template.New(lang).
+				Funcs(template.FuncMap{"bytes": FormatBytes}).
+				Option("missingkey=error").
+				ParseFS(mailTemplateFS, "mail_templates/layout.html", "mail_templates/"+lang+".html")
+			if err != nil {
+				mailTemplatesErr = fmt.Errorf("parse %s mail templates: %w", lang, err)
+				return
+			}
+			mailTemplates[lang] = t
+		}
+	})
+	return mailTemplates, mailTemplatesErr
+}
+
+// DefaultMailLang 返回默认邮件语言，由 MAIL_DEFAULT_LANG 配置，默认简体中文。
+func DefaultMailLang() string {
+	if lang := supportedMailLang(os.Getenv("MAIL_DEFAULT_LANG")); lang != "" {
+		return lang
+	}
+	return common.LangZh
+}
+
+// MailLang 按顺序返回候选中第一个受支持的语言，候选可以是语言代码或 Accept-Language 头；均不支持时返回默认语言。
+func MailLang(candidates ...string) string {
+	for _, candidate := range candidates {
+		for _, part := range strings.Split(candidate, ",") {
+			if lang := supportedMailLang(part); lang != "" {
+				return lang
+			}
+		}
+	}
+	return DefaultMailLang()
+}
+
+// supportedMailLang 将 "en-US;q=0.9" 之类的语言标签归一为受支持的语言，不支持时返回空字符串。
+func supportedMailLang(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, ";-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, lang := range mailLangs {
+		if tag == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// RenderMail 按语言渲染邮件模板，返回纯文本主题与 HTML 正文；不支持的语言使用默认语言。
+func RenderMail(name, lang string, data any) (subject, body string, err error) {
+	templates, err := loadMailTemplates()
+	if err != nil {
+		return "", "", err
+	}
+	t := templates[MailLang(lang)]
+	if t.Lookup(name+".body") == nil {
+		return "", "", fmt.Errorf("unknown mail template %q", name)
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name+".subject", data); err != nil {
+		return "", "", err
+	}
+	// 主题按 HTML 转义渲染，发送前还原为纯文本
+	subject = strings.TrimSpace(html.UnescapeString(buf.String()))
+	buf.Reset()
+	if err := t.ExecuteTemplate(&buf, name+".body", data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
+
+// FormatBytes 将字节数格式化为易读的大小，如 1.5 GB。
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit && exp < 4; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTP"[exp])
+}
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/zeromicro/go-zero/core/metric"
+)
+
+// metricNamespace 业务指标的命名空间；路由耗时由 go-zero 内置的 http_server_requests_duration_ms 提供。
+const metricNamespace = "cloud_disk"
+
+// durationBuckets 耗时直方图的分桶（毫秒），覆盖从签名 URL 到大视频压缩的耗时范围。
+var durationBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 300000}
+
+// 上传结果标签。
+const (
+	// UploadResultStored 新文件已写入存储。
+	UploadResultStored = "stored"
+	// UploadResultInstant 秒传：文件已存在，仅创建用户文件记录。
+	UploadResultInstant = "instant"
+	// UploadResultRejected 处理时空间不足，任务被丢弃。
+	UploadResultRejected = "rejected"
+	// UploadResultFailed 重试耗尽后仍处理失败。
+	UploadResultFailed = "failed"
+)
+
+// 去重查询阶段标签。
+const (
+	// DedupStageBloom 布隆过滤器判断。
+	DedupStageBloom = "bloom"
+	// DedupStageDB 布隆过滤器命中后的数据库确认。
+	DedupStageDB = "db"
+)
+
+// 存储操作标签。
+const (
+	// StorageOpPut 普通上传。
+	StorageOpPut = "put"
+	// StorageOpMultipartPut 分片上传（整个过程计一次）。
+	StorageOpMultipartPut = "multipart_put"
+	// StorageOpDelete 删除对象。
+	StorageOpDelete = "delete"
+	// StorageOpPresign 生成下载签名 URL。
+	StorageOpPresign = "presign"
+)
+
+// 消息队列标签。
+const (
+	// MQQueueUpload 异步上传队列。
+	MQQueueUpload = "upload"
+	// MQQueueWebhook webhook 投递队列。
+	MQQueueWebhook = "webhook"
+	// MQQueueMail 邮件队列。
+	MQQueueMail = "mail"
+)
+
+var (
+	metricUploadTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "upload",
+		Name:      "total",
+		Help:      "async upload tasks by result.",
+		Labels:    []string{"result"},
+	})
+	metricUploadBytes = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "upload",
+		Name:      "bytes_total",
+		Help:      "bytes added to user storage by async uploads.",
+		Labels:    []string{"result"},
+	})
+	metricDedupLookups = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "dedup",
+		Name:      "lookups_total",
+		Help:      "upload dedup lookups by stage and result.",
+		Labels:    []string{"stage", "result"},
+	})
+	metricMQConsumeDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "mq",
+		Name:      "consume_duration_ms",
+		Help:      "mq message handling duration(ms).",
+		Labels:    []string{"queue", "result"},
+		Buckets:   durationBuckets,
+	})
+	metricMQRetries = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "mq",
+		Name:      "retries_total",
+		Help:      "mq message retries.",
+		Labels:    []string{"queue"},
+	})
+	metricMQNacks = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "mq",
+		Name:      "nacks_total",
+		Help:      "mq messages rejected without requeue.",
+		Labels:    []string{"queue"},
+	})
+	metricCompressDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "compress",
+		Name:      "duration_ms",
+		Help:      "image and video compression duration(ms).",
+		Labels:    []string{"kind", "result"},
+		Buckets:   durationBuckets,
+	})
+	metricStorageDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "storage",
+		Name:      "duration_ms",
+		Help:      "object storage call duration(ms).",
+		Labels:    []string{"op"},
+		Buckets:   durationBuckets,
+	})
+	metricStorageErrors = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "storage",
+		Name:      "errors_total",
+		Help:      "object storage call errors.",
+		Labels:    []string{"op"},
+	})
+	metricRecyclePurged = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: metricNamespace,
+		Subsystem: "recycle",
+		Name:      "purged_total",
+		Help:      "expired recycle bin objects purged by result.",
+		Labels:    []string{"result"},
+	})
+)
+
+// resultLabel 将错误转换为 ok 或 error 标签。
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// ObserveUpload 记录一次异步上传任务的结果与计入用户空间的字节数。
+func ObserveUpload(result string, size int64) {
+	metricUploadTotal.Inc(result)
+	if size > 0 {
+		metricUploadBytes.Add(float64(size), result)
+	}
+}
+
+// ObserveDedup 记录一次去重查询，hit 表示该阶段判定文件已存在。
+func ObserveDedup(stage string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	metricDedupLookups.Inc(stage, result)
+}
+
+// ObserveMQConsume 记录一次消息处理耗时。
+func ObserveMQConsume(queue string, start time.Time, err error) {
+	metricMQConsumeDur.Observe(time.Since(start).Milliseconds(), queue, resultLabel(err))
+}
+
+// ObserveMQRetry 记录一次消息重试。
+func ObserveMQRetry(queue string) {
+	metricMQRetries.Inc(queue)
+}
+
+// ObserveMQNack 记录一次不重新入队的拒绝。
+func ObserveMQNack(queue string) {
+	metricMQNacks.Inc(queue)
+}
+
+// ObserveCompress 记录一次压缩耗时，kind 为 image 或 video。
+func ObserveCompress(kind string, start time.Time, err error) {
+	metricCompressDur.Observe(time.Since(start).Milliseconds(), kind, resultLabel(err))
+}
+
+// ObserveStorage 记录一次对象存储调用的耗时，失败时累加错误数。
+func ObserveStorage(op string, start time.Time, err error) {
+	metricStorageDur.Observe(time.Since(start).Milliseconds(), op)
+	if err != nil {
+		metricStorageErrors.Inc(op)
+	}
+}
+
+// ObserveRecyclePurge 记录一次回收站过期对象清理的结果。
+func ObserveRecyclePurge(err error) {
+	result := "purged"
+	if err != nil {
+		result = "failed"
+	}
+	metricRecyclePurged.Inc(result)
+}
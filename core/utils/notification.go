@@ -0,0 +1,21 @@
+package utils
+
+import "time"
+
+// NotificationUnreadTTL 未读通知数缓存及其版本键的有效期，通知变更时会主动失效。
+const NotificationUnreadTTL = 10 * time.Minute
+
+// NotificationUnreadKey 返回用户未读通知数在 Redis 中的缓存键。
+func NotificationUnreadKey(userIdentity string) string {
+	return "notification_unread:" + userIdentity
+}
+
+// NotificationUnreadVersionKey 返回用户未读通知数缓存的版本键，通知变更时写入新版本使旧缓存失效。
+func NotificationUnreadVersionKey(userIdentity string) string {
+	return "notification_unread_ver:" + userIdentity
+}
+
+// TrashPurgeNoticeKey 返回回收站清理提醒的去重键，同一用户同一清理日期只提醒一次。
+func TrashPurgeNoticeKey(userIdentity, date string) string {
+	return "trash_purge_notice:" + userIdentity + ":" + date
+}
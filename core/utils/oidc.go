@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// oidcCacheTTL 身份提供方元数据与公钥的缓存时长。
+const oidcCacheTTL = time.Hour
+
+// oidcHTTPClient 访问身份提供方使用的 HTTP 客户端。
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// OIDCProvider 身份提供方元数据（/.well-known/openid-configuration）。
+type OIDCProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// OIDCClaims ID Token 中使用到的声明。
+type OIDCClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+// oidcCacheEntry 缓存项。
+type oidcCacheEntry struct {
+	provider *OIDCProvider
+	keys     map[string]*rsa.PublicKey
+	loadedAt time.Time
+}
+
+var (
+	oidcCacheMu sync.Mutex
+	oidcCache   = map[string]*oidcCacheEntry{}
+)
+
+// DiscoverOIDC 获取身份提供方元数据，结果按签发方缓存。
+func DiscoverOIDC(ctx context.Context, issuer string) (*OIDCProvider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	oidcCacheMu.Lock()
+	entry := oidcCache[issuer]
+	oidcCacheMu.Unlock()
+	if entry != nil && time.Since(entry.loadedAt) < oidcCacheTTL {
+		return entry.provider, nil
+	}
+
+	provider := new(OIDCProvider)
+	if err := oidcGetJSON(ctx, issuer+"/.well-known/openid-configuration", provider); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch %q", provider.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JwksURI == "" {
+		return nil, errors.New("oidc discovery: incomplete provider metadata")
+	}
+	oidcCacheMu.Lock()
+	oidcCache[issuer] = &oidcCacheEntry{provider: provider, loadedAt: time.Now()}
+	oidcCacheMu.Unlock()
+	return provider, nil
+}
+
+// PKCEChallenge 按 S256 方式计算 PKCE code_challenge。
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// OIDCAuthorizeURL 构造授权码模式（含 PKCE）的授权地址。
+func OIDCAuthorizeURL(provider *OIDCProvider, clientId, redirectURL string, scopes []string, state, nonce, verifier string) (string, error) {
+	u, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", clientId)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", PKCEChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// ExchangeOIDCCode 使用授权码与 PKCE code_verifier 换取 ID Token。
+func ExchangeOIDCCode(ctx context.Context, provider *OIDCProvider, clientId, clientSecret, redirectURL, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", clientId)
+	form.Set("code_verifier", verifier)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientId), url.QueryEscape(clientSecret))
+	}
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc token exchange: status %d", resp.StatusCode)
+	}
+	var token struct {
+		IdToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", err
+	}
+	if token.IdToken == "" {
+		return "", errors.New("oidc token exchange: id_token missing")
+	}
+	return token.IdToken, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发方、受众、有效期与 nonce。
+func VerifyIDToken(ctx context.Context, provider *OIDCProvider, clientId, rawToken, nonce string) (*OIDCClaims, error) {
+	claims := new(OIDCClaims)
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return oidcSigningKey(ctx, provider, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !claims.VerifyIssuer(provider.Issuer, true) {
+		return nil, errors.New("oidc id_token: issuer mismatch")
+	}
+	if !claims.VerifyAudience(clientId, true) {
+		return nil, errors.New("oidc id_token: audience mismatch")
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("oidc id_token: exp missing")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc id_token: sub missing")
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("oidc id_token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// oidcSigningKey 按 kid 查找签名公钥，未命中时重新拉取 JWKS（应对密钥轮换）。
+func oidcSigningKey(ctx context.Context, provider *OIDCProvider, kid string) (*rsa.PublicKey, error) {
+	issuer := strings.TrimSuffix(provider.Issuer, "/")
+	oidcCacheMu.Lock()
+	entry := oidcCache[issuer]
+	var keys map[string]*rsa.PublicKey
+	if entry != nil {
+		keys = entry.keys
+	}
+	oidcCacheMu.Unlock()
+	if key := pickSigningKey(keys, kid); key != nil {
+		return key, nil
+	}
+
+	keys, err := fetchJWKS(ctx, provider.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+	oidcCacheMu.Lock()
+	if entry = oidcCache[issuer]; entry != nil {
+		entry.keys = keys
+	}
+	oidcCacheMu.Unlock()
+	if key := pickSigningKey(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, errors.New("oidc id_token: signing key not found")
+}
+
+// pickSigningKey 选取签名公钥，未指定 kid 且仅有一把公钥时直接使用。
+func pickSigningKey(keys map[string]*rsa.PublicKey, kid string) *rsa.PublicKey {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// fetchJWKS 拉取并解析 JWKS 中的 RSA 公钥。
+func fetchJWKS(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := oidcGetJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// oidcGetJSON 发起 GET 请求并解析 JSON 响应。
+func oidcGetJSON(ctx context.Context, rawURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
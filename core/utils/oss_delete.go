@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"time"
 
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
 )
@@ -15,9 +16,11 @@ func DeleteOSSObject(ctx context.Context, objectKey string) error {
 	if err != nil {
 		return err
 	}
+	start := time.Now()
 	_, err = client.DeleteObject(ctx, &oss.DeleteObjectRequest{
 		Bucket: oss.Ptr(OSSBucketNameValue()),
 		Key:    oss.Ptr(objectKey),
 	})
+	ObserveStorage(StorageOpDelete, start, err)
 	return err
 }
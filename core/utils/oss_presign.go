@@ -16,10 +16,12 @@ func PresignGetObject(ctx context.Context, objectKey string, expires time.Durati
 	if err != nil {
 		return "", err
 	}
+	start := time.Now()
 	result, err := client.Presign(ctx, &oss.GetObjectRequest{
 		Bucket: oss.Ptr(OSSBucketNameValue()),
 		Key:    oss.Ptr(objectKey),
 	}, oss.PresignExpires(expires))
+	ObserveStorage(StorageOpPresign, start, err)
 	if err != nil {
 		return "", err
 	}
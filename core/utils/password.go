@@ -1,18 +1,36 @@
 package utils
 
 import (
-    "encoding/base64"
-    "strings"
+	"encoding/base64"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// DecodeMaybeBase64 兼容前端以 base64 提交的密码：仅当解码结果为可打印的 UTF-8 文本时才采用解码值，否则按原文处理。
 func DecodeMaybeBase64(s string) string {
-    t := strings.TrimSpace(s)
-    if t == "" {
-        return ""
-    }
-    b, err := base64.StdEncoding.DecodeString(t)
-    if err == nil {
-        return string(b)
-    }
-    return t
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return ""
+	}
+	b, err := base64.StdEncoding.DecodeString(t)
+	if err != nil || len(b) == 0 || !utf8.Valid(b) {
+		return t
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) {
+			return t
+		}
+	}
+	return string(b)
+}
+
+// legacyDecodeBase64 旧版的密码解码：只要能按 base64 解码就采用解码值，不论结果是否可打印。
+// 旧的 MD5 哈希按此规则计算，例如原文提交的 "pass1234" 会被当作 base64 解码后再哈希。
+func legacyDecodeBase64(s string) string {
+	t := strings.TrimSpace(s)
+	if b, err := base64.StdEncoding.DecodeString(t); err == nil {
+		return string(b)
+	}
+	return t
 }
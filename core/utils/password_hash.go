@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id 参数，参考 OWASP 推荐的最低配置。
+const (
+	argon2Memory  uint32 = 19 * 1024
+	argon2Time    uint32 = 2
+	argon2Threads uint8  = 1
+	argon2SaltLen        = 16
+	argon2KeyLen  uint32 = 32
+)
+
+// errInvalidPasswordHash 密码哈希格式无法识别。
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+// HashPassword 使用 argon2id 生成自描述格式的密码哈希：
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword 校验密码，兼容旧的无盐 MD5 哈希。
+// needsRehash 为 true 表示校验通过但哈希为旧格式或参数已过时，调用方应重新生成哈希。
+func VerifyPassword(encoded, password string) (ok bool, needsRehash bool) {
+	if isLegacyMd5(encoded) {
+		// 旧哈希可能是对原文误做 base64 解码后的结果，password 已按新规则解码，需同时比对旧规则的解码值
+		want := []byte(strings.ToLower(encoded))
+		ok = subtle.ConstantTimeCompare([]byte(Md5(password)), want) == 1 ||
+			subtle.ConstantTimeCompare([]byte(Md5(legacyDecodeBase64(password))), want) == 1
+		return ok, ok
+	}
+	memory, time, threads, salt, key, err := decodeArgon2Hash(encoded)
+	if err != nil {
+		return false, false
+	}
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false
+	}
+	outdated := memory != argon2Memory || time != argon2Time || threads != argon2Threads || uint32(len(key)) != argon2KeyLen
+	return true, outdated
+}
+
+// isLegacyMd5 判断是否为旧的 32 位十六进制 MD5 哈希。
+func isLegacyMd5(encoded string) bool {
+	if len(encoded) != 32 {
+		return false
+	}
+	for _, c := range encoded {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeArgon2Hash 解析 argon2id 哈希字符串。
+func decodeArgon2Hash(encoded string) (memory, time uint32, threads uint8, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	return memory, time, threads, salt, key, nil
+}
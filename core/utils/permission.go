@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"cloud_disk/core/common"
+	"cloud_disk/core/models"
+
+	"xorm.io/xorm"
+)
+
+// RoleHasPermission 判断角色是否拥有指定管理权限；admin 角色隐式拥有全部权限，普通用户（空角色）没有任何管理权限。
+func RoleHasPermission(engine *xorm.Engine, role, permission string) (bool, error) {
+	if role == common.RoleAdmin {
+		return true, nil
+	}
+	if role == "" {
+		return false, nil
+	}
+	cnt, err := engine.Where("role = ? AND permission = ?", role, permission).Count(new(models.RolePermission))
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+// ValidPermission 判断是否为系统支持的管理权限。
+func ValidPermission(permission string) bool {
+	for _, p := range common.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
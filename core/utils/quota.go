@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"cloud_disk/core/common"
+	"cloud_disk/core/models"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"xorm.io/xorm"
+)
+
+// ErrQuotaExceeded 存储空间不足。
+var ErrQuotaExceeded = errors.New("存储空间不足")
+
+// defaultQuotaBytes 默认存储配额：10GB。
+const defaultQuotaBytes = 10 * 1024 * 1024 * 1024
+
+// DefaultQuotaBytes 获取新用户的默认存储配额（字节），小于等于 0 表示不限制。
+func DefaultQuotaBytes() int64 {
+	if v := os.Getenv("DEFAULT_QUOTA_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return defaultQuotaBytes
+}
+
+// LoadQuota 读取用户配额；首次读取时按现有文件统计占用并以默认配额创建记录。
+func LoadQuota(engine *xorm.Engine, userIdentity string) (*models.UserQuota, error) {
+	quota := new(models.UserQuota)
+	has, err := engine.Where("user_identity = ?", userIdentity).Get(quota)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return quota, nil
+	}
+	used, trash, err := userUsage(engine, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	quota = &models.UserQuota{
+		UserIdentity: userIdentity,
+		QuotaBytes:   DefaultQuotaBytes(),
+		UsedBytes:    used,
+		TrashBytes:   trash,
+	}
+	if _, err := engine.Insert(quota); err != nil {
+		// 并发创建时唯一索引冲突，读取已创建的记录
+		existing := new(models.UserQuota)
+		if has, getErr := engine.Where("user_identity = ?", userIdentity).Get(existing); getErr == nil && has {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return quota, nil
+}
+
+// RemainingQuota 返回用户剩余可用空间；不限制配额时 limited 为 false。
+func RemainingQuota(engine *xorm.Engine, userIdentity string) (remaining int64, limited bool, err error) {
+	quota, err := LoadQuota(engine, userIdentity)
+	if err != nil {
+		return 0, false, err
+	}
+	if quota.QuotaBytes <= 0 {
+		return 0, false, nil
+	}
+	remaining = quota.QuotaBytes - quota.UsedBytes
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true, nil
+}
+
+// CheckQuota 校验用户剩余空间是否足够存放 size 字节，不足时返回 ErrQuotaExceeded。
+// 仅用于读取请求体前的提前拒绝，实际占用须通过 ReserveQuota 原子预占。
+func CheckQuota(engine *xorm.Engine, userIdentity string, size int64) error {
+	remaining, limited, err := RemainingQuota(engine, userIdentity)
+	if err != nil {
+		return err
+	}
+	if limited && size > remaining {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// ReserveQuota 以单条条件更新原子地预占 used 字节的已用空间（同时调整回收站占用 trash），
+// 超出配额时不做任何修改并返回 ErrQuotaExceeded，避免并发请求都通过校验后共同超额。
+// 需在文件记录变更之前调用；后续写入失败时调用 AddUsage(-used, -trash) 释放预占。
+func ReserveQuota(engine *xorm.Engine, userIdentity string, used, trash int64) error {
+	if used <= 0 {
+		return AddUsage(engine, userIdentity, used, trash)
+	}
+	if _, err := LoadQuota(engine, userIdentity); err != nil {
+		return err
+	}
+	res, err := engine.Exec("UPDATE "+new(models.UserQuota).TableName()+
+		" SET used_bytes = used_bytes + ?, trash_bytes = trash_bytes + ?"+
+		" WHERE user_identity = ? AND (quota_bytes <= 0 OR used_bytes + ? <= quota_bytes)",
+		used, trash, userIdentity, used)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// ReleaseQuota 释放 ReserveQuota 预占的空间，用于预占后文件记录写入失败的情况；失败时只记录日志。
+func ReleaseQuota(engine *xorm.Engine, userIdentity string, used, trash int64) {
+	if err := AddUsage(engine, userIdentity, -used, -trash); err != nil {
+		logx.Errorf("release quota failed user=%s used=%d trash=%d err=%v", userIdentity, used, trash, err)
+	}
+}
+
+// AddUsage 调整用户的已用与回收站占用，需在文件记录变更之后调用。
+// 配额记录尚不存在时按变更后的文件重新统计创建，不再重复累加。
+func AddUsage(engine *xorm.Engine, userIdentity string, used, trash int64) error {
+	if used == 0 && trash == 0 {
+		return nil
+	}
+	has, err := engine.Where("user_identity = ?", userIdentity).Exist(new(models.UserQuota))
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = LoadQuota(engine, userIdentity)
+		return err
+	}
+	_, err = engine.Where("user_identity = ?", userIdentity).
+		Incr("used_bytes", used).
+		Incr("trash_bytes", trash).
+		Update(new(models.UserQuota))
+	return err
+}
+
+// UserFileSizes 按用户汇总满足条件的文件记录占用的字节数，文件夹不计入。
+// cond 为基于别名 ur（user_repository）的过滤条件。
+func UserFileSizes(engine xorm.Interface, cond string, args ...interface{}) (map[string]int64, error) {
+	var rows []struct {
+		UserIdentity string
+		Total        int64
+	}
+	sql := "SELECT ur.user_identity AS user_identity, COALESCE(SUM(rp.size), 0) AS total FROM user_repository ur " +
+		"INNER JOIN repository_pool rp ON ur.repository_identity = rp.identity " +
+		"WHERE ur.repository_identity != '' AND (" + cond + ") GROUP BY ur.user_identity"
+	if err := engine.SQL(sql, args...).Find(&rows); err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		sizes[row.UserIdentity] = row.Total
+	}
+	return sizes, nil
+}
+
+// InPlaceholders 生成 IN 子句所需的占位符，如 "?,?,?"。
+func InPlaceholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// userUsage 统计用户正常文件与回收站文件的占用。
+func userUsage(engine *xorm.Engine, userIdentity string) (used, trash int64, err error) {
+	active, err := UserFileSizes(engine, "ur.user_identity = ? AND (ur.status IS NULL OR ur.status NOT IN (?, ?, ?))",
+		userIdentity, common.StatusDeleted, common.StatusPurging, common.StatusPurged)
+	if err != nil {
+		return 0, 0, err
+	}
+	deleted, err := UserFileSizes(engine, "ur.user_identity = ? AND ur.status = ?", userIdentity, common.StatusDeleted)
+	if err != nil {
+		return 0, 0, err
+	}
+	return active[userIdentity], deleted[userIdentity], nil
+}
+
+// QuotaWarningPercent 存储用量告警阈值（占配额的百分比）。
+const QuotaWarningPercent = 90
+
+// QuotaWarningCrossed 判断本次新增 added 字节后用量是否首次越过告警阈值，需在 AddUsage 之后调用；不限制配额时不告警。
+func QuotaWarningCrossed(engine *xorm.Engine, userIdentity string, added int64) (*models.UserQuota, bool, error) {
+	if added <= 0 {
+		return nil, false, nil
+	}
+	quota, err := LoadQuota(engine, userIdentity)
+	if err != nil || quota.QuotaBytes <= 0 {
+		return quota, false, err
+	}
+	threshold := quota.QuotaBytes * QuotaWarningPercent / 100
+	return quota, quota.UsedBytes >= threshold && quota.UsedBytes-added < threshold, nil
+}
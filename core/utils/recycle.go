@@ -33,3 +33,23 @@ func RecycleScanInterval() time.Duration {
 	}
 	return time.Hour
 }
+
+// ShareCleanupInterval 获取过期分享清理扫描间隔。
+func ShareCleanupInterval() time.Duration {
+	if v := os.Getenv("SHARE_CLEANUP_INTERVAL_SECONDS"); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+			return time.Duration(sec) * time.Second
+		}
+	}
+	return 10 * time.Minute
+}
+
+// SaveFolderSyncLimit 获取转存文件夹同步执行的最大节点数，超过则转为后台任务。
+func SaveFolderSyncLimit() int {
+	if v := os.Getenv("SAVE_FOLDER_SYNC_LIMIT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return 200
+}
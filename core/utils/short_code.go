@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// base62Alphabet 短码字符集。
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Base62Code 生成指定长度的随机 base62 短码。
+func Base62Code(n int) (string, error) {
+	max := big.NewInt(int64(len(base62Alphabet)))
+	var sb strings.Builder
+	sb.Grow(n)
+	for i := 0; i < n; i++ {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(base62Alphabet[idx.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// ShareBaseURL 返回公开分享页面的地址前缀，默认指向 web 前端开发服务器的 /s/ 分享页 http://localhost:5173/s/。
+func ShareBaseURL() string {
+	if v := strings.TrimSpace(os.Getenv("SHARE_BASE_URL")); v != "" {
+		if !strings.HasSuffix(v, "/") {
+			v += "/"
+		}
+		return v
+	}
+	return "http://localhost:5173/s/"
+}
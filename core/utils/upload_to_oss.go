@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"time"
 
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
 	"github.com/joho/godotenv"
@@ -74,7 +75,9 @@ func UploadToOSS(fileReader io.Reader, originalFilename string) (string, error)
 		bucketName = OSSBucketNameValue()
 		objectName = key
 	)
+	start := time.Now()
 	etag, err := ossUpload(region, bucketName, objectName, fileReader)
+	ObserveStorage(StorageOpPut, start, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to put object: %w", err)
 	}
@@ -15,11 +15,19 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-// UploadToOSSMultipart 分片上传文件到 OSS。
+// UploadToOSSMultipart 分片上传文件到 OSS，并记录整个上传过程的耗时。
 // filePath: 本地文件路径。
 // originalFilename: 原始文件名。
 // fileSize: 文件大小（字节）。
 func UploadToOSSMultipart(filePath string, originalFilename string, fileSize int64) (string, error) {
+	start := time.Now()
+	key, err := uploadToOSSMultipart(filePath, originalFilename, fileSize)
+	ObserveStorage(StorageOpMultipartPut, start, err)
+	return key, err
+}
+
+// uploadToOSSMultipart 执行分片上传：初始化、并发上传分片并完成合并。
+func uploadToOSSMultipart(filePath string, originalFilename string, fileSize int64) (string, error) {
 	key := UUID() + path.Ext(originalFilename)
 
 	if err := ossLoadEnv(); err != nil {
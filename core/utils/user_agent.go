@@ -0,0 +1,56 @@
+package utils
+
+import "strings"
+
+// userAgentRule 按关键字识别浏览器或操作系统。
+type userAgentRule struct {
+	keyword string
+	name    string
+}
+
+// browserRules 浏览器识别规则，按顺序匹配（Edge、Chrome 的 UA 同时包含 Safari 等关键字）。
+var browserRules = []userAgentRule{
+	{keyword: "edg/", name: "Edge"},
+	{keyword: "opr/", name: "Opera"},
+	{keyword: "firefox/", name: "Firefox"},
+	{keyword: "chrome/", name: "Chrome"},
+	{keyword: "safari/", name: "Safari"},
+	{keyword: "curl/", name: "curl"},
+	{keyword: "okhttp", name: "OkHttp"},
+}
+
+// osRules 操作系统识别规则。
+var osRules = []userAgentRule{
+	{keyword: "windows", name: "Windows"},
+	{keyword: "iphone", name: "iOS"},
+	{keyword: "ipad", name: "iPadOS"},
+	{keyword: "android", name: "Android"},
+	{keyword: "mac os", name: "macOS"},
+	{keyword: "linux", name: "Linux"},
+}
+
+// DeviceName 根据 User-Agent 粗略识别设备，如 "Chrome on Windows"。
+func DeviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	browser := matchUserAgent(ua, browserRules)
+	system := matchUserAgent(ua, osRules)
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "未知设备"
+}
+
+// matchUserAgent 返回第一个命中的规则名称。
+func matchUserAgent(ua string, rules []userAgentRule) string {
+	for _, rule := range rules {
+		if strings.Contains(ua, rule.keyword) {
+			return rule.name
+		}
+	}
+	return ""
+}
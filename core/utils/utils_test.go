@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"cloud_disk/core/common"
 )
 
 // TestMd5 验证 Md5 计算结果。
@@ -119,3 +124,154 @@ func BenchmarkMd5(b *testing.B) {
 		_ = Md5(input)
 	}
 }
+
+// TestPasswordHash 验证 argon2id 哈希与旧 MD5 哈希的校验。
+func TestPasswordHash(t *testing.T) {
+	hashed, err := HashPassword("pass1234")
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	if !strings.HasPrefix(hashed, "$argon2id$") {
+		t.Fatalf("unexpected hash format: %s", hashed)
+	}
+	if ok, rehash := VerifyPassword(hashed, "pass1234"); !ok || rehash {
+		t.Fatalf("verify failed: ok=%v rehash=%v", ok, rehash)
+	}
+	if ok, _ := VerifyPassword(hashed, "wrong"); ok {
+		t.Fatal("wrong password accepted")
+	}
+	if ok, rehash := VerifyPassword(Md5("pass1234"), "pass1234"); !ok || !rehash {
+		t.Fatalf("legacy verify failed: ok=%v rehash=%v", ok, rehash)
+	}
+	if ok, _ := VerifyPassword("garbage", "pass1234"); ok {
+		t.Fatal("invalid hash accepted")
+	}
+}
+
+// TestVerifyPasswordLegacyDecode 验证旧版按 base64 误解码原文密码生成的 MD5 哈希仍可校验通过。
+func TestVerifyPasswordLegacyDecode(t *testing.T) {
+	// "pass1234" 恰好是合法 base64，旧版会解码成不可打印字节后再哈希
+	legacy := Md5(legacyDecodeBase64("pass1234"))
+	if legacy == Md5("pass1234") {
+		t.Fatal("test input should decode to different bytes")
+	}
+	if DecodeMaybeBase64("pass1234") != "pass1234" {
+		t.Fatal("non-printable decode result should fall back to raw input")
+	}
+	if ok, rehash := VerifyPassword(legacy, DecodeMaybeBase64("pass1234")); !ok || !rehash {
+		t.Fatalf("legacy decoded hash rejected: ok=%v rehash=%v", ok, rehash)
+	}
+	if ok, _ := VerifyPassword(legacy, DecodeMaybeBase64("pass5678")); ok {
+		t.Fatal("wrong password accepted")
+	}
+}
+
+// TestTOTP 验证 RFC 6238 测试向量与时钟偏差校验。
+func TestTOTP(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 密钥 "12345678901234567890"
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	code, err := TOTPCode(secret, TOTPStep(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatalf("totp failed: %v", err)
+	}
+	if code != "287082" {
+		t.Fatalf("unexpected code: %s", code)
+	}
+	now := time.Unix(1111111109, 0)
+	prev, _ := TOTPCode(secret, TOTPStep(now)-1)
+	if _, ok := VerifyTOTP(secret, prev, now, 1); !ok {
+		t.Fatal("expected skewed code accepted")
+	}
+	if _, ok := VerifyTOTP(secret, prev, now, 0); ok {
+		t.Fatal("expected skewed code rejected without skew")
+	}
+}
+
+// TestDeviceName 验证根据 User-Agent 识别设备。
+func TestDeviceName(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36":                             "Chrome on Windows",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0":                   "Edge on Windows",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1": "Safari on iOS",
+		"curl/8.4.0": "curl",
+		"":           "未知设备",
+	}
+	for ua, want := range cases {
+		if got := DeviceName(ua); got != want {
+			t.Fatalf("device mismatch for %q: %s", ua, got)
+		}
+	}
+}
+
+// TestRenderMail 验证邮件模板按语言渲染、HTML 转义，以及 Accept-Language 的语言选择。
+func TestRenderMail(t *testing.T) {
+	data := map[string]any{"Code": "123456", "Purpose": common.CodePurposeRegister, "Minutes": 5}
+	subject, body, err := RenderMail(common.MailVerification, common.LangEn, data)
+	if err != nil {
+		t.Fatalf("render en failed: %v", err)
+	}
+	if subject != "Your CloudDisk sign-up code" || !strings.Contains(body, "123456") {
+		t.Fatalf("unexpected en mail: %q %q", subject, body)
+	}
+	zhSubject, _, err := RenderMail(common.MailVerification, common.LangZh, data)
+	if err != nil || zhSubject == subject {
+		t.Fatalf("zh mail not localized: %q %v", zhSubject, err)
+	}
+
+	invite := map[string]any{"Owner": "bob & co", "Name": "<script>", "Folder": false, "Permission": "read", "URL": "http://localhost/s/abc"}
+	subject, body, err = RenderMail(common.MailShareInvite, common.LangEn, invite)
+	if err != nil {
+		t.Fatalf("render invite failed: %v", err)
+	}
+	if !strings.Contains(subject, `bob & co shared "<script>"`) {
+		t.Fatalf("subject should be unescaped: %q", subject)
+	}
+	if strings.Contains(body, "<script>") || !strings.Contains(body, "&lt;script&gt;") {
+		t.Fatalf("body not escaped: %q", body)
+	}
+	if _, _, err := RenderMail("unknown", common.LangEn, data); err == nil {
+		t.Fatal("unknown template should fail")
+	}
+	if _, _, err := RenderMail(common.MailShareInvite, common.LangEn, map[string]any{}); err == nil {
+		t.Fatal("missing field should fail")
+	}
+
+	cases := []struct {
+		candidates []string
+		want       string
+	}{
+		{[]string{"en-US,en;q=0.9"}, common.LangEn},
+		{[]string{"fr-FR,zh-CN;q=0.8"}, common.LangZh},
+		{[]string{"", "en"}, common.LangEn},
+		{[]string{"zh", "en"}, common.LangZh},
+		{[]string{"fr"}, DefaultMailLang()},
+	}
+	for _, c := range cases {
+		if got := MailLang(c.candidates...); got != c.want {
+			t.Fatalf("MailLang(%q) = %s, want %s", c.candidates, got, c.want)
+		}
+	}
+}
+
+// TestClientIP 验证仅在直连地址为可信代理时采用 X-Forwarded-For。
+func TestClientIP(t *testing.T) {
+	t.Cleanup(func() { _ = SetTrustedProxies(nil) })
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.9:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got := ClientIP(req); got != "203.0.113.9" {
+		t.Fatalf("untrusted peer must not be overridden by XFF: %s", got)
+	}
+
+	if err := SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatalf("set trusted proxies failed: %v", err)
+	}
+	req.RemoteAddr = "10.0.0.2:5000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 198.51.100.1, 192.168.1.1")
+	if got := ClientIP(req); got != "198.51.100.1" {
+		t.Fatalf("expected rightmost untrusted hop, got %s", got)
+	}
+	if err := SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Fatal("invalid proxy should be rejected")
+	}
+}
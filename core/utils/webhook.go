@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// webhook 请求头，接收方按 WebhookSignatureHeader 校验请求来源。
+const (
+	WebhookEventHeader     = "X-CloudDisk-Event"
+	WebhookDeliveryHeader  = "X-CloudDisk-Delivery"
+	WebhookTimestampHeader = "X-CloudDisk-Timestamp"
+	WebhookSignatureHeader = "X-CloudDisk-Signature"
+)
+
+// webhookSecretPrefix webhook 签名密钥前缀。
+const webhookSecretPrefix = "whsec_"
+
+// webhookSecretLength webhook 签名密钥随机部分长度。
+const webhookSecretLength = 32
+
+// GenerateWebhookSecret 生成新的 webhook 签名密钥。
+func GenerateWebhookSecret() (string, error) {
+	code, err := Base62Code(webhookSecretLength)
+	if err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + code, nil
+}
+
+// WebhookSignature 计算 webhook 请求签名：以密钥对 "时间戳.请求体" 做 HMAC-SHA256，格式为 sha256=<hex>。
+// 时间戳参与签名，接收方可据此拒绝过旧的请求以防重放。
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrWebhookPrivateAddress 回调地址解析到回环、内网、链路本地等非公网地址。
+var ErrWebhookPrivateAddress = errors.New("webhook target is not a public address")
+
+// webhookAllowPrivate 是否允许投递到内网地址，仅用于回调接收方部署在内网的场景。
+var webhookAllowPrivate atomic.Bool
+
+// SetWebhookAllowPrivate 设置是否允许 webhook 投递到内网地址。
+func SetWebhookAllowPrivate(allow bool) {
+	webhookAllowPrivate.Store(allow)
+}
+
+// webhookIPAllowed 判断 webhook 是否可以访问该地址：默认拒绝回环、内网、链路本地、组播与未指定地址。
+func webhookIPAllowed(ip net.IP) bool {
+	if webhookAllowPrivate.Load() {
+		return true
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		// 0.0.0.0/8 与运营商级 NAT 100.64.0.0/10 同样不是公网地址
+		if ip[0] == 0 || (ip[0] == 100 && ip[1]&0xc0 == 64) {
+			return false
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// CheckWebhookHost 解析回调地址的主机名，任一解析结果不是公网地址时返回 ErrWebhookPrivateAddress。
+func CheckWebhookHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !webhookIPAllowed(ip) {
+			return ErrWebhookPrivateAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no address for %s", host)
+	}
+	for _, addr := range addrs {
+		if !webhookIPAllowed(addr.IP) {
+			return ErrWebhookPrivateAddress
+		}
+	}
+	return nil
+}
+
+// WebhookDialContext 返回投递使用的拨号函数：在实际建立连接前再次校验目标 IP，
+// 防止创建后修改 DNS 解析（DNS rebinding）绕过创建时的地址检查。
+func WebhookDialContext(timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !webhookIPAllowed(ip) {
+				return ErrWebhookPrivateAddress
+			}
+			return nil
+		},
+	}
+	return dialer.DialContext
+}
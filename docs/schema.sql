@@ -19,7 +19,7 @@ CREATE TABLE `user_basic` (
   `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增主键ID',
   `identity` varchar(36) DEFAULT NULL COMMENT '用户唯一标识（UUID，避免主键暴露）',
   `name` varchar(60) DEFAULT NULL COMMENT '用户名',
-  `password` varchar(32) DEFAULT NULL COMMENT '用户密码（建议加密存储，如MD5、BCrypt）',
+  `password` varchar(255) DEFAULT NULL COMMENT '用户密码哈希（argon2id，兼容旧 MD5 并在登录时自动升级）',
   `email` varchar(100) DEFAULT NULL COMMENT '用户邮箱（用于登录、找回密码）',
   `created_at` datetime DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime DEFAULT NULL COMMENT '更新时间',